@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"log"
 	"os"
@@ -35,6 +36,7 @@ type mccCommand struct {
 	Verbosity int    `short:"v" long:"verbosity" description:"Verbosity level" default:"1"`
 	Delta     int    `short:"d" long:"delta" description:"Display progress ~Delta nodes (Verbosity > 0)" default:"100000000"`
 	Compact   bool   `short:"c" long:"compact" description:"Output solutions in compact format, one per line"`
+	Count     bool   `long:"count" description:"Output only the number of solutions and the search statistics"`
 }
 
 func (command mccCommand) Execute(args []string) error {
@@ -120,6 +122,14 @@ func (command mccCommand) Execute(args []string) error {
 		log.Printf("Elapsed Time: %v", elapsed)
 	}()
 
+	if command.Count {
+		count, err := taocp.MCCCount(context.Background(), xcYaml.Items, multiplicities, options, xcYaml.SItems, stats)
+		if err != nil {
+			return err
+		}
+		return writeCount(output, count, stats, command.Compact)
+	}
+
 	if !command.Compact {
 		output.WriteString("solutions:\n")
 	}
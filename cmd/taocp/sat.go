@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wallberg/sandbox-go/taocp"
+)
+
+// Exit status codes used by SAT solvers to report the result
+const (
+	satExitSatisfiable   = 10
+	satExitUnsatisfiable = 20
+)
+
+// initialize this command by adding it to the parser
+func init() {
+	var command satCommand
+
+	_, err := parser.AddCommand("sat",
+		"Satisfiability (SAT)",
+		`Solve Satisfiability (SAT) problems using taocp.SatAlgorithm{A,B,C,D,L,P,W}
+Uses Knuth format for input. Prints SAT or UNSAT, followed by the satisfying
+assignment using the original variable names. Exits with status 10 if
+satisfiable and 20 if unsatisfiable. The local search algorithms P and W
+print UNKNOWN and exit with status 0 if they find no solution.`,
+		&command,
+	)
+	if err != nil {
+		log.Fatalf("Error adding sat command: %v", err)
+	}
+}
+
+type satCommand struct {
+	Input                  string        `short:"i" long:"input" description:"Input SAT file in Knuth format" default:"-"`
+	Algorithm              string        `short:"a" long:"algorithm" description:"SAT algorithm" choice:"A" choice:"B" choice:"C" choice:"D" choice:"L" choice:"P" choice:"W" default:"D"`
+	Verbosity              int           `short:"v" long:"verbosity" description:"Verbosity level" default:"0"`
+	Delta                  int           `short:"d" long:"delta" description:"Display progress ~Delta nodes (Verbosity > 0)" default:"100000000"`
+	CompensationResolvants bool          `long:"compensation-resolvants" description:"Algorithm L: use compensation resolvants (Exercise 139)"`
+	SuppressBigClauses     bool          `long:"suppress-big-clauses" description:"Algorithm L: convert to 3SAT instead of using big clauses (Exercise 143)"`
+	Theta                  float64       `long:"theta" description:"Algorithm L: threshold for swapping free literals in big clauses (Exercise 143)" default:"0.390625"`
+	Lookahead              bool          `long:"lookahead" description:"Algorithm L: look ahead at both values of each free variable before each decision"`
+	DoubleLookahead        bool          `long:"double-lookahead" description:"Algorithm L: double lookahead at each decision literal"`
+	Noise                  float64       `long:"noise" description:"Algorithm W: probability of a random flip" default:"0.567"`
+	MaxFlips               int           `long:"max-flips" description:"Algorithms P and W: maximum flips before a restart" default:"1000000"`
+	MaxTries               int           `long:"max-tries" description:"Algorithms P and W: maximum random assignments to try" default:"10"`
+	Seed                   int64         `long:"seed" description:"Algorithms P and W, and random branching: seed for the pseudorandom generator" default:"0"`
+	Branching              string        `long:"branching" description:"Algorithms B and D: branching heuristic" choice:"default" choice:"moms" choice:"jw" choice:"dlcs" choice:"random" default:"default"`
+	Proof                  string        `long:"proof" description:"Algorithms C, D and L: write a DRAT proof of unsatisfiability to this file"`
+	BreakSymmetry          bool          `long:"break-symmetry" description:"Add lex-leader clauses which break the symmetries of the clauses"`
+	Telemetry              string        `long:"telemetry" description:"Write solver metrics to this file as JSON lines"`
+	TelemetryInterval      time.Duration `long:"telemetry-interval" description:"Minimum time between solver metrics" default:"1s"`
+}
+
+func (command satCommand) Execute(args []string) error {
+	var err error
+
+	// Open input file for reading
+	var input *os.File
+	if command.Input == "-" {
+		input = os.Stdin
+	} else {
+		if input, err = os.Open(command.Input); err != nil {
+			return err
+		}
+	}
+	defer input.Close()
+
+	// Read the clauses
+	clauses, variables, err := taocp.SatParse(input)
+	if err != nil {
+		return err
+	}
+	n := len(variables)
+
+	// Solve
+	stats := &taocp.SatStats{
+		Debug:     command.Verbosity > 1,
+		Progress:  command.Verbosity > 0,
+		Verbosity: command.Verbosity - 2,
+		Delta:     command.Delta,
+	}
+	options := &taocp.SatOptions{}
+
+	if command.Telemetry != "" {
+		telemetry, err := os.Create(command.Telemetry)
+		if err != nil {
+			return err
+		}
+		defer telemetry.Close()
+		stats.Telemetry = taocp.SatTelemetryWriter(telemetry)
+		stats.TelemetryInterval = command.TelemetryInterval
+	}
+
+	if command.Branching != "default" {
+		if command.Algorithm != "B" && command.Algorithm != "D" {
+			return fmt.Errorf("--branching is only supported by algorithms B and D")
+		}
+		if options.Branching, err = taocp.NewSatBranching(command.Branching, command.Seed); err != nil {
+			return err
+		}
+	}
+
+	if command.Proof != "" {
+		if command.Algorithm != "C" && command.Algorithm != "D" && command.Algorithm != "L" {
+			return fmt.Errorf("--proof is only supported by algorithms C, D and L")
+		}
+		// Algorithm L writes no proof for a 3SAT conversion, see taocp.SatOptions.Proof
+		if command.Algorithm == "L" && command.SuppressBigClauses {
+			return fmt.Errorf("--proof is not supported with --suppress-big-clauses")
+		}
+		if command.BreakSymmetry {
+			return fmt.Errorf("--proof is not supported with --break-symmetry")
+		}
+		proof, err := os.Create(command.Proof)
+		if err != nil {
+			return err
+		}
+		defer proof.Close()
+		options.Proof = proof
+	}
+
+	start := time.Now()
+
+	// Solve the clauses with the symmetries broken, keeping the original
+	// clauses to verify the solution
+	solve := clauses
+	if command.BreakSymmetry {
+		var complete bool
+		n, solve, complete = taocp.SatBreakSymmetry(n, clauses)
+		if command.Verbosity > 0 {
+			log.Printf("Symmetry breaking: %d clauses and %d variables added", len(solve)-len(clauses), n-len(variables))
+			if !complete {
+				log.Printf("Search for symmetries reached its node limit; some may remain")
+			}
+		}
+	}
+
+	var (
+		sat      bool
+		solution []int
+	)
+
+	switch command.Algorithm {
+	case "A":
+		sat, solution = taocp.SatAlgorithmA(n, solve, stats, options)
+	case "B":
+		sat, solution = taocp.SatAlgorithmB(n, solve, stats, options)
+	case "C":
+		sat, solution = taocp.SatAlgorithmC(n, solve, stats, options)
+	case "D":
+		sat, solution = taocp.SatAlgorithmD(n, solve, stats, options)
+	case "L":
+		optionsL := &taocp.SatAlgorithmLOptions{
+			CompensationResolvants: command.CompensationResolvants,
+			SuppressBigClauses:     command.SuppressBigClauses,
+			Theta:                  command.Theta,
+			Lookahead:              command.Lookahead,
+			DoubleLookahead:        command.DoubleLookahead,
+		}
+		sat, solution = taocp.SatAlgorithmL(n, solve, stats, options, optionsL)
+	case "P", "W":
+		optionsW := &taocp.SatAlgorithmWOptions{
+			Noise:    command.Noise,
+			MaxFlips: command.MaxFlips,
+			MaxTries: command.MaxTries,
+			Seed:     command.Seed,
+		}
+		if command.Algorithm == "P" {
+			sat, solution = taocp.SatAlgorithmP(n, solve, stats, options, optionsW)
+		} else {
+			sat, solution = taocp.SatAlgorithmW(n, solve, stats, options, optionsW)
+		}
+	}
+
+	if command.Verbosity > 0 {
+		log.Printf("Elapsed Time: %v", time.Since(start))
+		if len(stats.Levels) > 0 {
+			log.Printf("Stats: %v", stats)
+		}
+		if stats.Flips > 0 {
+			log.Printf("Flips: %d, Restarts: %d", stats.Flips, stats.Restarts)
+		}
+		if stats.Probes > 0 {
+			log.Printf("Probes: %d, Double lookaheads: %d", stats.Probes, stats.DoubleLookaheads)
+		}
+	}
+
+	if !sat && (command.Algorithm == "P" || command.Algorithm == "W") {
+		// Local search is incomplete
+		fmt.Println("UNKNOWN")
+		return nil
+	}
+
+	if !sat {
+		fmt.Println("UNSAT")
+		return exitStatus(satExitUnsatisfiable)
+	}
+
+	// Verify the solution before reporting it, without any auxiliary
+	// variables of symmetry breaking
+	n = len(variables)
+	solution = solution[:n]
+	if !taocp.SatTest(n, clauses, solution) {
+		return fmt.Errorf("algorithm %s returned an invalid solution", command.Algorithm)
+	}
+
+	fmt.Println("SAT")
+	fmt.Println(satAssignment(solution, variables))
+
+	return exitStatus(satExitSatisfiable)
+}
+
+// satAssignment formats a solution as a list of literals using the variable
+// names, with ~ prefixed to variables which are false
+func satAssignment(solution []int, variables map[int]string) string {
+	var b strings.Builder
+
+	for i, value := range solution {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		if value == 0 {
+			b.WriteString("~")
+		}
+		if name, ok := variables[i+1]; ok {
+			b.WriteString(name)
+		} else {
+			b.WriteString(strconv.Itoa(i + 1))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wallberg/sandbox-go/taocp"
+)
+
+// initialize this command by adding it to the parser
+func init() {
+	var command satBenchCommand
+
+	_, err := parser.AddCommand("sat-bench",
+		"Satisfiability (SAT) benchmarks",
+		`Benchmark the SAT algorithms over a directory of SAT files in Knuth format
+Each algorithm is run on each instance by the sat command of this program, in
+a separate process which is killed when it exceeds the timeout. Every SAT
+answer is verified with taocp.SatTest, and instances where the algorithms
+disagree are flagged. Writes a table of result, time and nodes as Markdown or
+CSV, and exits with an error if any answer is wrong or disputed.`,
+		&command,
+	)
+	if err != nil {
+		log.Fatalf("Error adding sat-bench command: %v", err)
+	}
+}
+
+type satBenchCommand struct {
+	Directory  string        `short:"D" long:"directory" description:"Directory of SAT files in Knuth format" default:"taocp/testdata/SATExamples"`
+	Pattern    string        `short:"p" long:"pattern" description:"Glob pattern of the SAT files in the directory" default:"*.sat"`
+	Algorithms string        `short:"a" long:"algorithms" description:"Comma separated list of SAT algorithms" default:"C,D,L"`
+	Timeout    time.Duration `short:"t" long:"timeout" description:"Maximum time for each algorithm on each instance" default:"10s"`
+	Format     string        `short:"f" long:"format" description:"Output format" choice:"markdown" choice:"csv" default:"markdown"`
+	Output     string        `short:"o" long:"output" description:"Output file" default:"-"`
+	Verbosity  int           `short:"v" long:"verbosity" description:"Verbosity level" default:"1"`
+	Branching  string        `long:"branching" description:"Algorithms B and D: branching heuristic" choice:"default" choice:"moms" choice:"jw" choice:"dlcs" choice:"random" default:"default"`
+	Seed       int64         `long:"seed" description:"Algorithms P and W, and random branching: seed for the pseudorandom generator" default:"0"`
+}
+
+// satBenchRun is the outcome of one algorithm on one instance
+type satBenchRun struct {
+	Instance  string  // name of the SAT file
+	Algorithm string  // SAT algorithm
+	Result    string  // SAT, UNSAT, UNKNOWN, TIMEOUT or ERROR
+	Time      float64 // seconds, of the solver if reported, otherwise of the process
+	Nodes     int     // SatStats.Nodes
+	Flips     int     // SatStats.Flips
+	Verified  string  // yes or no for SAT results, otherwise empty
+	Note      string  // disagreement or error
+}
+
+func (command satBenchCommand) Execute(args []string) error {
+	var err error
+
+	algorithms := strings.Split(command.Algorithms, ",")
+	for i, algorithm := range algorithms {
+		algorithm = strings.ToUpper(strings.TrimSpace(algorithm))
+		if len(algorithm) != 1 || !strings.Contains("ABCDLPW", algorithm) {
+			return fmt.Errorf("unknown SAT algorithm %q", algorithm)
+		}
+		algorithms[i] = algorithm
+	}
+
+	files, err := filepath.Glob(filepath.Join(command.Directory, command.Pattern))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no SAT files matching %s in %s", command.Pattern, command.Directory)
+	}
+	sort.Strings(files)
+
+	// Runs are made by this program's sat command, so that they can be killed
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	// Open output file for writing
+	var output *os.File
+	if command.Output == "-" {
+		output = os.Stdout
+	} else {
+		if output, err = os.Create(command.Output); err != nil {
+			return err
+		}
+		defer output.Close()
+	}
+
+	var (
+		runs     []satBenchRun
+		problems int
+	)
+
+	for _, file := range files {
+		clauses, variables, err := taocp.SatRead(file)
+		if err != nil {
+			return err
+		}
+
+		instance := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		start := len(runs)
+
+		for _, algorithm := range algorithms {
+			run := command.run(executable, file, algorithm, clauses, variables)
+			run.Instance = instance
+			if run.Verified == "no" {
+				problems++
+			}
+			if command.Verbosity > 0 {
+				log.Printf("%s %s: %s in %.3fs", instance, algorithm, run.Result, run.Time)
+			}
+			runs = append(runs, run)
+		}
+
+		// Flag algorithms which disagree on satisfiability
+		var sat, unsat []string
+		for _, run := range runs[start:] {
+			switch run.Result {
+			case "SAT":
+				sat = append(sat, run.Algorithm)
+			case "UNSAT":
+				unsat = append(unsat, run.Algorithm)
+			}
+		}
+		if len(sat) > 0 && len(unsat) > 0 {
+			problems++
+			note := fmt.Sprintf("disagreement: SAT by %s, UNSAT by %s",
+				strings.Join(sat, " "), strings.Join(unsat, " "))
+			for i := start; i < len(runs); i++ {
+				runs[i].Note = note
+			}
+			log.Printf("%s: %s", instance, note)
+		}
+	}
+
+	if command.Format == "csv" {
+		err = satBenchCSV(output, runs)
+	} else {
+		err = satBenchMarkdown(output, runs)
+	}
+	if err != nil {
+		return err
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("found %d invalid solutions or disagreements", problems)
+	}
+	return nil
+}
+
+// run solves one instance with one algorithm in a separate process, and
+// verifies its solution against the clauses
+func (command satBenchCommand) run(executable string, file string, algorithm string,
+	clauses taocp.SatClauses, variables map[int]string) satBenchRun {
+
+	run := satBenchRun{Algorithm: algorithm}
+
+	// The final telemetry report of the solver provides its time and nodes
+	telemetry, err := os.CreateTemp("", "sat-bench-*.jsonl")
+	if err != nil {
+		run.Result, run.Note = "ERROR", err.Error()
+		return run
+	}
+	telemetry.Close()
+	defer os.Remove(telemetry.Name())
+
+	cmdArgs := []string{"sat",
+		"--algorithm", algorithm,
+		"--input", file,
+		"--telemetry", telemetry.Name(),
+		"--telemetry-interval", "1000h",
+		"--seed", strconv.FormatInt(command.Seed, 10),
+	}
+	if command.Branching != "default" && (algorithm == "B" || algorithm == "D") {
+		cmdArgs = append(cmdArgs, "--branching", command.Branching)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), command.Timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, executable, cmdArgs...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err = cmd.Run()
+	run.Time = time.Since(start).Seconds()
+
+	if report, ok := satBenchTelemetry(telemetry.Name()); ok {
+		run.Time = report.Elapsed
+		run.Nodes = report.Nodes
+		run.Flips = report.Flips
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		run.Result = "TIMEOUT"
+		return run
+	case err == nil:
+		run.Result = "UNKNOWN"
+		return run
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == satExitUnsatisfiable:
+		run.Result = "UNSAT"
+		return run
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == satExitSatisfiable:
+		run.Result = "SAT"
+	default:
+		run.Result = "ERROR"
+		run.Note = satBenchLastLine(stderr.String())
+		if run.Note == "" {
+			run.Note = err.Error()
+		}
+		return run
+	}
+
+	// Verify the satisfying assignment, which follows the SAT line
+	var solution []int
+	lines := strings.SplitN(stdout.String(), "\n", 3)
+	if len(lines) < 2 {
+		err = fmt.Errorf("missing assignment")
+	} else {
+		solution, err = satBenchSolution(lines[1], variables)
+	}
+	if err == nil && taocp.SatTest(len(variables), clauses, solution) {
+		run.Verified = "yes"
+	} else {
+		run.Verified = "no"
+		if err != nil {
+			run.Note = err.Error()
+		} else {
+			run.Note = "invalid solution"
+		}
+	}
+
+	return run
+}
+
+// satBenchTelemetry returns the final report of a telemetry file, if the
+// solver finished
+func satBenchTelemetry(filename string) (taocp.SatTelemetry, bool) {
+	var report taocp.SatTelemetry
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return report, false
+	}
+	defer f.Close()
+
+	done := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if err := json.Unmarshal(scanner.Bytes(), &report); err == nil {
+			done = report.Done
+		}
+	}
+
+	return report, done
+}
+
+// satBenchSolution parses an assignment printed by the sat command, as
+// formatted by satAssignment, into a solution
+func satBenchSolution(assignment string, variables map[int]string) ([]int, error) {
+
+	name2variable := make(map[string]int, len(variables))
+	for k, name := range variables {
+		name2variable[name] = k
+	}
+
+	solution := make([]int, len(variables))
+	seen := 0
+	for _, literal := range strings.Fields(assignment) {
+		value := 1
+		if strings.HasPrefix(literal, "~") {
+			value = 0
+			literal = literal[1:]
+		}
+		k, ok := name2variable[literal]
+		if !ok {
+			return nil, fmt.Errorf("unknown variable %q in the assignment", literal)
+		}
+		solution[k-1] = value
+		seen++
+	}
+	if seen != len(variables) {
+		return nil, fmt.Errorf("expected %d variables in the assignment; got %d", len(variables), seen)
+	}
+
+	return solution, nil
+}
+
+// satBenchLastLine returns the last non-empty line of s
+func satBenchLastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// satBenchRow returns the columns of a run, for output
+func satBenchRow(run satBenchRun) []string {
+	// Runs which didn't finish have no counts
+	var nodes, flips string
+	if run.Result != "TIMEOUT" && run.Result != "ERROR" {
+		nodes = strconv.Itoa(run.Nodes)
+		flips = strconv.Itoa(run.Flips)
+	}
+	return []string{
+		run.Instance,
+		run.Algorithm,
+		run.Result,
+		strconv.FormatFloat(run.Time, 'f', 3, 64),
+		nodes,
+		flips,
+		run.Verified,
+		run.Note,
+	}
+}
+
+// satBenchHeader is the header of the output table
+var satBenchHeader = []string{"instance", "algorithm", "result", "time", "nodes", "flips", "verified", "note"}
+
+// satBenchCSV writes the runs to w as CSV
+func satBenchCSV(w io.Writer, runs []satBenchRun) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(satBenchHeader); err != nil {
+		return err
+	}
+	for _, run := range runs {
+		if err := writer.Write(satBenchRow(run)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// satBenchMarkdown writes the runs to w as a Markdown table
+func satBenchMarkdown(w io.Writer, runs []satBenchRun) error {
+	var b strings.Builder
+
+	row := func(columns []string) {
+		b.WriteString("|")
+		for _, column := range columns {
+			b.WriteString(" ")
+			b.WriteString(strings.ReplaceAll(column, "|", `\|`))
+			b.WriteString(" |")
+		}
+		b.WriteString("\n")
+	}
+
+	row(satBenchHeader)
+	b.WriteString("|")
+	for i := range satBenchHeader {
+		if i >= 3 && i <= 5 {
+			b.WriteString("---:|") // numbers align right
+		} else {
+			b.WriteString("---|")
+		}
+	}
+	b.WriteString("\n")
+	for _, run := range runs {
+		row(satBenchRow(run))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
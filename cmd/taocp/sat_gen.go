@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/wallberg/sandbox-go/graph"
+	"github.com/wallberg/sandbox-go/taocp"
+	ybgraph "github.com/yourbasic/graph"
+)
+
+// initialize this command by adding it to the parser
+func init() {
+	var command satGenCommand
+
+	_, err := parser.AddCommand("sat-gen",
+		"Satisfiability (SAT) generators",
+		`Generate Satisfiability (SAT) benchmark problems from TAOCP 7.2.2.2
+Writes the clauses in Knuth format, for the sat and sat-bench commands.
+The parameters of each family are:
+  hole        -n holes
+  color       -d colors, and --graph with -n vertices, or an edge list from
+              --input with one pair of vertices "u v" per line
+  mchess      -n rows and columns of the board
+  fsnark      -n order of the flower snark
+  life        -r generations, and the pattern from --input
+  tomography  the image from --input
+  factor      -z number to factor, -m bits of x and -n bits of y
+  waerden     -j zeros, -k ones and -n length
+  langford    -n pairs
+  rand        -k literals per clause, -m clauses, -n variables and --seed
+Patterns and images are rows of '*' for live or black cells, and '.' for
+dead or white ones.`,
+		&command,
+	)
+	if err != nil {
+		log.Fatalf("Error adding sat-gen command: %v", err)
+	}
+}
+
+type satGenCommand struct {
+	Family string `short:"f" long:"family" description:"Family of problems" choice:"hole" choice:"color" choice:"mchess" choice:"fsnark" choice:"life" choice:"tomography" choice:"factor" choice:"waerden" choice:"langford" choice:"rand" required:"true"`
+	N      int    `short:"n" description:"Size of the problem" default:"0"`
+	M      int    `short:"m" description:"Second size of the problem (factor, rand)" default:"0"`
+	J      int    `short:"j" description:"Equally spaced zeros (waerden)" default:"0"`
+	K      int    `short:"k" description:"Equally spaced ones (waerden), or literals per clause (rand)" default:"0"`
+	D      int    `short:"d" description:"Number of colors (color)" default:"0"`
+	R      int    `short:"r" description:"Number of generations (life)" default:"1"`
+	Z      uint64 `short:"z" description:"Number to factor (factor)" default:"0"`
+	Graph  string `short:"g" long:"graph" description:"Graph of n vertices to color; grid and torus have n x n" choice:"path" choice:"cycle" choice:"complete" choice:"grid" choice:"torus" default:"cycle"`
+	Input  string `short:"i" long:"input" description:"Input pattern, image or edge list"`
+	Output string `short:"o" long:"output" description:"Output SAT file in Knuth format" default:"-"`
+	Seed   int64  `long:"seed" description:"Seed for the pseudorandom generator (rand)" default:"0"`
+}
+
+func (command satGenCommand) Execute(args []string) error {
+	var (
+		err       error
+		clauses   taocp.SatClauses
+		variables map[int]string
+	)
+
+	// positive checks that the named parameters are at least min
+	positive := func(min int, names string, values ...int) error {
+		for i, name := range strings.Split(names, ",") {
+			if values[i] < min {
+				return fmt.Errorf("%s requires -%s >= %d", command.Family, name, min)
+			}
+		}
+		return nil
+	}
+
+	switch command.Family {
+	case "hole":
+		if err = positive(1, "n", command.N); err == nil {
+			clauses, variables = taocp.SatPigeonhole(command.N)
+		}
+
+	case "color":
+		var g ybgraph.Iterator
+		if g, err = command.graph(); err == nil {
+			if err = positive(1, "d", command.D); err == nil {
+				clauses, variables = taocp.SatColoring(g, command.D)
+			}
+		}
+
+	case "mchess":
+		if err = positive(3, "n", command.N); err == nil {
+			clauses, variables = taocp.SatMutilatedChessboard(command.N)
+		}
+
+	case "fsnark":
+		if err = positive(3, "n", command.N); err == nil {
+			clauses, variables = taocp.SatFlowerSnark(command.N)
+		}
+
+	case "life":
+		var pattern []string
+		if pattern, err = command.pattern(); err == nil {
+			if err = positive(1, "r", command.R); err == nil {
+				clauses, variables = taocp.SatLife(pattern, command.R)
+			}
+		}
+
+	case "tomography":
+		var image []string
+		if image, err = command.pattern(); err == nil {
+			clauses, variables = taocp.SatTomography(image)
+		}
+
+	case "factor":
+		if err = positive(2, "m,n", command.M, command.N); err == nil {
+			clauses, variables = taocp.SatFactor(command.Z, command.M, command.N)
+		}
+
+	case "waerden":
+		if err = positive(1, "j,k,n", command.J, command.K, command.N); err == nil {
+			clauses = taocp.SatWaerdan(command.J, command.K, command.N)
+		}
+
+	case "langford":
+		if err = positive(1, "n", command.N); err == nil {
+			clauses, _ = taocp.SatLangford(command.N)
+		}
+
+	case "rand":
+		if err = positive(1, "k,m,n", command.K, command.M, command.N); err == nil {
+			clauses = taocp.SatRand(command.K, command.M, command.N, command.Seed)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// Open output file for writing
+	var output *os.File
+	if command.Output == "-" {
+		output = os.Stdout
+	} else {
+		if output, err = os.Create(command.Output); err != nil {
+			return err
+		}
+		defer output.Close()
+	}
+
+	// Describe the problem in a comment, then write the clauses
+	if _, err = fmt.Fprintf(output, "~ %s\n", strings.Join(os.Args[1:], " ")); err != nil {
+		return err
+	}
+	return taocp.SatWrite(output, clauses, variables)
+}
+
+// graph returns the graph to color, from the edge list of --input if given
+func (command satGenCommand) graph() (ybgraph.Iterator, error) {
+	if command.Input == "" {
+		if command.N < 1 {
+			return nil, fmt.Errorf("color requires -n >= 1, or an edge list from --input")
+		}
+		switch command.Graph {
+		case "path":
+			return graph.Path(command.N), nil
+		case "complete":
+			return graph.Complete(command.N), nil
+		case "grid":
+			return graph.CartesianProduct(graph.Path(command.N), graph.Path(command.N)), nil
+		case "torus":
+			return graph.CartesianProduct(graph.Cycle(command.N), graph.Cycle(command.N)), nil
+		default:
+			return graph.Cycle(command.N), nil
+		}
+	}
+
+	lines, err := command.lines()
+	if err != nil {
+		return nil, err
+	}
+
+	var edges [][2]int
+	order := 0
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d of %s: expected two vertices; got %q", i+1, command.Input, line)
+		}
+		var edge [2]int
+		for j, field := range fields {
+			if edge[j], err = strconv.Atoi(field); err != nil || edge[j] < 0 {
+				return nil, fmt.Errorf("line %d of %s: expected a vertex number; got %q", i+1, command.Input, field)
+			}
+			order = max(order, edge[j]+1)
+		}
+		edges = append(edges, edge)
+	}
+
+	g := ybgraph.New(order)
+	for _, edge := range edges {
+		g.AddBoth(edge[0], edge[1])
+	}
+	return g, nil
+}
+
+// pattern returns the rows of the pattern or image of --input
+func (command satGenCommand) pattern() ([]string, error) {
+	if command.Input == "" {
+		return nil, fmt.Errorf("%s requires a pattern from --input", command.Family)
+	}
+
+	rows, err := command.lines()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%s: expected a pattern", command.Input)
+	}
+	for i, row := range rows {
+		if len(row) != len(rows[0]) {
+			return nil, fmt.Errorf("line %d of %s: expected %d cells; got %d", i+1, command.Input, len(rows[0]), len(row))
+		}
+	}
+	return rows, nil
+}
+
+// lines returns the non-empty lines of --input, without surrounding space
+func (command satGenCommand) lines() ([]string, error) {
+	var (
+		input io.Reader
+		err   error
+	)
+	if command.Input == "-" {
+		input = os.Stdin
+	} else {
+		f, err := os.Open(command.Input)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		input = f
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"os"
 
 	flags "github.com/jessevdk/go-flags"
@@ -15,8 +17,29 @@ type Options struct {
 	// None
 }
 
+// exitStatus is returned by a command which completed, but exits with a
+// status other than 0, such as the result of a SAT solver. The status is
+// used only after the command has returned and its deferred calls have run.
+type exitStatus int
+
+func (status exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(status))
+}
+
 func main() {
 
+	var status exitStatus
+	parser.CommandHandler = func(command flags.Commander, args []string) error {
+		if command == nil {
+			return nil
+		}
+		err := command.Execute(args)
+		if errors.As(err, &status) {
+			return nil
+		}
+		return err
+	}
+
 	if _, err := parser.Parse(); err != nil {
 		if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
 			os.Exit(0)
@@ -24,4 +47,6 @@ func main() {
 			os.Exit(1)
 		}
 	}
+
+	os.Exit(int(status))
 }
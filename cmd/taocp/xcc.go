@@ -2,8 +2,10 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"iter"
 	"log"
 	"os"
 	"strings"
@@ -29,16 +31,26 @@ Uses YAML for input and output`,
 }
 
 type xccCommand struct {
-	Input         string `short:"i" long:"input" description:"Input YAML" default:"-"`
-	Output        string `short:"o" long:"output" description:"Output YAML" default:"-"`
-	Verbosity     int    `short:"v" long:"verbosity" description:"Verbosity level" default:"1"`
-	Delta         int    `short:"d" long:"delta" description:"Display progress ~Delta nodes (Verbosity > 0)" default:"100000000"`
-	Compact       bool   `short:"c" long:"compact" description:"Output solutions in compact format, one per line"`
-	Minimax       bool   `short:"m" long:"minimax" description:"Return minimax solutions (multiple)"`
-	MinimaxSingle bool   `short:"s" long:"minimax-single" description:"Return minimax solutions (single)"`
-	Exercise83    bool   `short:"e" long:"exercise83" description:"Use the curious extension of Exercise 7.2.2.1-83"`
-	DisableSharp  bool   `short:"p" long:"disable-sharp" description:"Disable use of the sharp preference heuristic"`
-	Limit         int    `short:"l" long:"limit" description:"Halt after this number of solutions found" default:"0"`
+	Input           string `short:"i" long:"input" description:"Input YAML" default:"-"`
+	Output          string `short:"o" long:"output" description:"Output YAML" default:"-"`
+	Verbosity       int    `short:"v" long:"verbosity" description:"Verbosity level" default:"1"`
+	Delta           int    `short:"d" long:"delta" description:"Display progress ~Delta nodes (Verbosity > 0)" default:"100000000"`
+	Compact         bool   `short:"c" long:"compact" description:"Output solutions in compact format, one per line"`
+	Minimax         bool   `short:"m" long:"minimax" description:"Return minimax solutions (multiple)"`
+	MinimaxSingle   bool   `short:"s" long:"minimax-single" description:"Return minimax solutions (single)"`
+	Exercise83      bool   `short:"e" long:"exercise83" description:"Use the curious extension of Exercise 7.2.2.1-83"`
+	DisableSharp    bool   `short:"p" long:"disable-sharp" description:"Disable use of the sharp preference heuristic"`
+	Limit           int    `short:"l" long:"limit" description:"Halt after this number of solutions found" default:"0"`
+	Checkpoint      string `long:"checkpoint" description:"Periodically write the search state to this YAML file"`
+	CheckpointDelta int    `long:"checkpoint-delta" description:"Write a checkpoint every ~CheckpointDelta nodes" default:"100000000"`
+	Resume          string `long:"resume" description:"Resume the search from this checkpoint YAML file"`
+	Engine          string `long:"engine" description:"Search engine, dancing links or dancing cells" choice:"links" choice:"cells" default:"links"`
+	Threads         int    `short:"t" long:"threads" description:"Number of threads searching in parallel" default:"1"`
+	Count           bool   `long:"count" description:"Output only the number of solutions and the search statistics"`
+	Estimate        int    `long:"estimate" description:"Estimate the size of the search tree using this number of random probes" default:"0"`
+	Seed            int64  `long:"seed" description:"Seed for the random probes of --estimate" default:"0"`
+	Cheapest        int    `short:"k" long:"cheapest" description:"Return this number of solutions of least total cost, using the option costs" default:"0"`
+	BreakSymmetry   bool   `long:"break-symmetry" description:"Remove options which are symmetric to others, keeping a solution if there is one but losing others; --count, --limit and --estimate then describe the reduced problem"`
 }
 
 func (command xccCommand) Execute(args []string) error {
@@ -49,6 +61,33 @@ func (command xccCommand) Execute(args []string) error {
 		return fmt.Errorf("please select only one of --minimax, --minimax-single")
 	}
 
+	// Validate parallel options
+	if command.Threads < 1 {
+		return fmt.Errorf("--threads must be >= 1")
+	}
+	if command.Threads > 1 && (command.Checkpoint != "" || command.Resume != "") {
+		return fmt.Errorf("--checkpoint and --resume are not supported with --threads > 1")
+	}
+
+	// Validate engine options
+	if command.Engine == "cells" && (command.Threads > 1 || command.Estimate > 0 ||
+		command.Cheapest > 0 || command.Checkpoint != "" || command.Resume != "") {
+		return fmt.Errorf("--engine cells does not support --threads, --estimate, --cheapest, --checkpoint or --resume")
+	}
+
+	// The estimate is of the search tree without costs
+	if command.Estimate > 0 && command.Cheapest > 0 {
+		return fmt.Errorf("--estimate is not supported with --cheapest")
+	}
+
+	// Symmetries ignore the costs and the option numbers, so the cheapest or
+	// minimax solutions could be removed; and Exercise 83 assumes the full
+	// set of options at level 0
+	if command.BreakSymmetry && (command.Cheapest > 0 || command.Minimax ||
+		command.MinimaxSingle || command.Exercise83) {
+		return fmt.Errorf("--cheapest, --minimax, --minimax-single and --exercise83 are not supported with --break-symmetry")
+	}
+
 	// Open input file for reading
 	var input *os.File
 	if command.Input == "-" {
@@ -77,6 +116,24 @@ func (command xccCommand) Execute(args []string) error {
 		options[i] = strings.Split(option, " ")
 	}
 
+	if command.BreakSymmetry {
+		remain, complete, err := taocp.XCCBreakSymmetry(xcYaml.Items, options, xcYaml.SItems)
+		if err != nil {
+			return err
+		}
+		if command.Verbosity > 0 {
+			log.Printf("Breaking symmetry removed %d of %d options", len(options)-len(remain), len(options))
+			if !complete {
+				log.Printf("Search for symmetries reached its node limit; some may remain")
+			}
+		}
+		broken := make([][]string, len(remain))
+		for k, r := range remain {
+			broken[k] = options[r]
+		}
+		options = broken
+	}
+
 	// Open output file for writing
 	var output *os.File
 	if command.Output == "-" {
@@ -104,6 +161,28 @@ func (command xccCommand) Execute(args []string) error {
 		EnableSharpPreference: !command.DisableSharp,
 	}
 
+	// Checkpoints
+	if command.Checkpoint != "" {
+		xccOptions.CheckpointDelta = command.CheckpointDelta
+		xccOptions.Checkpoint = func(c *taocp.XCCCheckpoint) {
+			if err := writeCheckpoint(command.Checkpoint, c); err != nil {
+				log.Printf("Error writing checkpoint: %v", err)
+			}
+		}
+	}
+
+	if command.Resume != "" {
+		data, err := os.ReadFile(command.Resume)
+		if err != nil {
+			return err
+		}
+		var c taocp.XCCCheckpoint
+		if err = yaml.Unmarshal(data, &c); err != nil {
+			return err
+		}
+		xccOptions.Resume = &c
+	}
+
 	start := time.Now()
 	defer func() {
 		stop := time.Now()
@@ -111,15 +190,59 @@ func (command xccCommand) Execute(args []string) error {
 		log.Printf("Elapsed Time: %v", elapsed)
 	}()
 
-	if !command.Compact {
+	if command.Estimate > 0 {
+		estimate, err := taocp.XCCEstimate(context.Background(), xcYaml.Items, options, xcYaml.SItems, xccOptions, command.Estimate, command.Seed)
+		if err != nil {
+			return err
+		}
+		return writeEstimate(output, estimate)
+	}
+
+	if command.Cheapest > 0 {
+		if xcYaml.Costs == nil {
+			return fmt.Errorf("--cheapest requires costs in the input")
+		}
+		xccOptions.Costs = xcYaml.Costs
+		xccOptions.CheapestK = command.Cheapest
+
+		solutions, err := taocp.XCCCheapest(context.Background(), xcYaml.Items, options, xcYaml.SItems, stats, xccOptions)
+		if err != nil {
+			return err
+		}
+		return writeCheapest(output, solutions, command.Compact)
+	}
+
+	if command.Count && command.Threads == 1 && command.Engine == "links" {
+		count, err := taocp.XCCCount(context.Background(), xcYaml.Items, options, xcYaml.SItems, stats, xccOptions)
+		if err != nil {
+			return err
+		}
+		return writeCount(output, count, stats, command.Compact)
+	}
+
+	var solutions iter.Seq2[[][]string, error]
+	if command.Engine == "cells" {
+		solutions = taocp.XCCCells(context.Background(), xcYaml.Items, options, xcYaml.SItems, stats, xccOptions)
+	} else if command.Threads > 1 {
+		solutions = taocp.XCCParallel(context.Background(), xcYaml.Items, options, xcYaml.SItems, stats, xccOptions, command.Threads)
+	} else {
+		solutions = taocp.XCC(xcYaml.Items, options, xcYaml.SItems, stats, xccOptions)
+	}
+
+	if !command.Compact && !command.Count {
 		output.WriteString("solutions:\n")
 	}
-	for solution, err := range taocp.XCC(xcYaml.Items, options, xcYaml.SItems, stats, xccOptions) {
+	for solution, err := range solutions {
 
 		if err != nil {
 			return err
 		}
 
+		if command.Count {
+			// Only the total is needed
+			continue
+		}
+
 		if !command.Compact {
 			output.WriteString("  -\n")
 			for _, option := range solution {
@@ -144,8 +267,114 @@ func (command xccCommand) Execute(args []string) error {
 		if command.Limit > 0 && stats.Solutions == command.Limit {
 			return nil
 		}
-		return nil
+	}
+
+	if command.Count {
+		return writeCount(output, stats.Solutions, stats, command.Compact)
 	}
 
 	return nil
 }
+
+// writeCount writes the number of solutions and the search statistics as
+// YAML, or only the number of solutions in compact format
+func writeCount(output io.Writer, count int, stats *taocp.ExactCoverStats, compact bool) error {
+	if compact {
+		_, err := fmt.Fprintln(output, count)
+		return err
+	}
+
+	// Drop the trailing levels which were never entered
+	levels := stats.Levels
+	for len(levels) > 0 && levels[len(levels)-1] == 0 {
+		levels = levels[:len(levels)-1]
+	}
+
+	data, err := yaml.Marshal(struct {
+		Count  int   `yaml:"count"`
+		Nodes  int   `yaml:"nodes"`
+		Levels []int `yaml:"levels,flow"`
+	}{count, stats.Nodes, levels})
+	if err != nil {
+		return err
+	}
+
+	_, err = output.Write(data)
+	return err
+}
+
+// writeCheapest writes the cheapest solutions with their costs as YAML, or
+// one per line in compact format
+func writeCheapest(output io.Writer, solutions []taocp.XCCCostSolution, compact bool) error {
+	var b strings.Builder
+
+	if !compact {
+		b.WriteString("solutions:\n")
+	}
+	for _, solution := range solutions {
+		if !compact {
+			fmt.Fprintf(&b, "  - cost: %d\n", solution.Cost)
+			b.WriteString("    options:\n")
+			for _, option := range solution.Options {
+				b.WriteString("      - \"")
+				b.WriteString(strings.Join(option, " "))
+				b.WriteString("\"\n")
+			}
+		} else {
+			fmt.Fprintf(&b, "%d: ", solution.Cost)
+			for i, option := range solution.Options {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				b.WriteString("\"")
+				b.WriteString(strings.Join(option, " "))
+				b.WriteString("\"")
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(output, b.String())
+	return err
+}
+
+// writeEstimate writes the search tree size estimate as YAML
+func writeEstimate(output io.Writer, estimate *taocp.ExactCoverEstimate) error {
+	data, err := yaml.Marshal(struct {
+		Probes            int       `yaml:"probes"`
+		Nodes             float64   `yaml:"nodes"`
+		NodesVariance     float64   `yaml:"nodes-variance"`
+		Solutions         float64   `yaml:"solutions"`
+		SolutionsVariance float64   `yaml:"solutions-variance"`
+		Levels            []float64 `yaml:"levels,flow"`
+	}{
+		estimate.Probes,
+		estimate.Nodes,
+		estimate.NodesVariance,
+		estimate.Solutions,
+		estimate.SolutionsVariance,
+		estimate.Levels,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = output.Write(data)
+	return err
+}
+
+// writeCheckpoint serializes the checkpoint to filename as YAML, replacing
+// the file atomically
+func writeCheckpoint(filename string, c *taocp.XCCCheckpoint) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	tmp := filename + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filename)
+}
@@ -0,0 +1,297 @@
+package csp
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Constraint satisfaction problems (CSP), from The Art of Computer
+// Programming, Volume 4, §7.2.2.3. A Model has finite-domain integer
+// variables and table, all-different and linear constraints, and may be
+// compiled to SAT clauses (Sat) or to an exact cover problem with colors
+// (XCC), whose solutions decode to a value for each variable.
+
+// Relation is the relation of the two sides of a linear constraint
+type Relation int
+
+const (
+	LessEqual    Relation = iota // sum <= rhs
+	Equal                        // sum = rhs
+	GreaterEqual                 // sum >= rhs
+)
+
+// String returns the relation as an operator
+func (r Relation) String() string {
+	switch r {
+	case LessEqual:
+		return "<="
+	case Equal:
+		return "="
+	case GreaterEqual:
+		return ">="
+	}
+	return fmt.Sprintf("Relation(%d)", int(r))
+}
+
+// constraintKind is the kind of a constraint
+type constraintKind int
+
+const (
+	table constraintKind = iota
+	allDifferent
+	linear
+)
+
+// constraint is a constraint on the variables vars
+type constraint struct {
+	kind   constraintKind
+	vars   []string // names of the variables constrained
+	tuples [][]int  // allowed values of vars (table)
+	coeffs []int    // coefficient of each variable (linear)
+	rel    Relation // relation of the sum to rhs (linear)
+	rhs    int      // right hand side (linear)
+}
+
+// Model is a constraint satisfaction problem
+type Model struct {
+	names       []string         // names of the variables, in order of declaration
+	domains     map[string][]int // sorted values of each variable
+	constraints []constraint     // constraints, in order of declaration
+}
+
+// NewModel returns a model with no variables or constraints
+func NewModel() *Model {
+	return &Model{domains: make(map[string][]int)}
+}
+
+// Var declares a variable with the given name and domain of possible
+// values. The name may not contain spaces or the characters ":", "=", "#"
+// or "~", which are used in the names of the items and SAT variables.
+func (m *Model) Var(name string, domain ...int) error {
+	if name == "" || strings.ContainsAny(name, " \t\n:=#~") {
+		return fmt.Errorf("invalid variable name '%s'", name)
+	}
+	if _, ok := m.domains[name]; ok {
+		return fmt.Errorf("variable '%s' is already declared", name)
+	}
+	if len(domain) == 0 {
+		return fmt.Errorf("variable '%s' has an empty domain", name)
+	}
+
+	values := slices.Clone(domain)
+	slices.Sort(values)
+	m.domains[name] = slices.Compact(values)
+	m.names = append(m.names, name)
+
+	return nil
+}
+
+// Names returns the names of the variables, in order of declaration
+func (m *Model) Names() []string {
+	return slices.Clone(m.names)
+}
+
+// Domain returns the sorted domain of the variable name, or nil if there is
+// no such variable
+func (m *Model) Domain(name string) []int {
+	return slices.Clone(m.domains[name])
+}
+
+// declared returns an error if any of the variables are not declared
+func (m *Model) declared(vars []string) error {
+	for _, v := range vars {
+		if _, ok := m.domains[v]; !ok {
+			return fmt.Errorf("variable '%s' is not declared", v)
+		}
+	}
+	return nil
+}
+
+// Table constrains the values of vars to be one of the tuples
+func (m *Model) Table(vars []string, tuples [][]int) error {
+	if err := m.declared(vars); err != nil {
+		return err
+	}
+	for _, tuple := range tuples {
+		if len(tuple) != len(vars) {
+			return fmt.Errorf("tuple %v does not have %d values", tuple, len(vars))
+		}
+	}
+
+	m.constraints = append(m.constraints, constraint{
+		kind:   table,
+		vars:   slices.Clone(vars),
+		tuples: slices.Clone(tuples),
+	})
+
+	return nil
+}
+
+// AllDifferent constrains the values of vars to be distinct
+func (m *Model) AllDifferent(vars ...string) error {
+	if err := m.declared(vars); err != nil {
+		return err
+	}
+
+	m.constraints = append(m.constraints, constraint{
+		kind: allDifferent,
+		vars: slices.Clone(vars),
+	})
+
+	return nil
+}
+
+// Linear constrains the values of vars by
+// coeffs[0]*vars[0] + ... + coeffs[k-1]*vars[k-1] rel rhs
+func (m *Model) Linear(coeffs []int, vars []string, rel Relation, rhs int) error {
+	if err := m.declared(vars); err != nil {
+		return err
+	}
+	if len(coeffs) != len(vars) {
+		return fmt.Errorf("%d coefficients for %d variables", len(coeffs), len(vars))
+	}
+	if rel < LessEqual || rel > GreaterEqual {
+		return fmt.Errorf("invalid relation %v", rel)
+	}
+
+	m.constraints = append(m.constraints, constraint{
+		kind:   linear,
+		vars:   slices.Clone(vars),
+		coeffs: slices.Clone(coeffs),
+		rel:    rel,
+		rhs:    rhs,
+	})
+
+	return nil
+}
+
+// satisfied determines if the constraint is satisfied by the values
+func (c *constraint) satisfied(values map[string]int) bool {
+	switch c.kind {
+	case table:
+	Tuples:
+		for _, tuple := range c.tuples {
+			for i, v := range c.vars {
+				if values[v] != tuple[i] {
+					continue Tuples
+				}
+			}
+			return true
+		}
+		return false
+
+	case allDifferent:
+		seen := make(map[int]bool)
+		for _, v := range c.vars {
+			if seen[values[v]] {
+				return false
+			}
+			seen[values[v]] = true
+		}
+		return true
+
+	default:
+		sum := 0
+		for i, v := range c.vars {
+			sum += c.coeffs[i] * values[v]
+		}
+		switch c.rel {
+		case LessEqual:
+			return sum <= c.rhs
+		case Equal:
+			return sum == c.rhs
+		}
+		return sum >= c.rhs
+	}
+}
+
+// Check determines if values assigns a value in its domain to every variable
+// and satisfies every constraint
+func (m *Model) Check(values map[string]int) bool {
+	for _, v := range m.names {
+		value, ok := values[v]
+		if !ok {
+			return false
+		}
+		if _, found := slices.BinarySearch(m.domains[v], value); !found {
+			return false
+		}
+	}
+	for i := range m.constraints {
+		if !m.constraints[i].satisfied(values) {
+			return false
+		}
+	}
+	return true
+}
+
+// tuples returns the tuples of values of the variables of a table or linear
+// constraint which satisfy it, with values in the domains
+func (m *Model) tuples(c *constraint) [][]int {
+	if c.kind == table {
+		var result [][]int
+	Tuples:
+		for _, tuple := range c.tuples {
+			for i, v := range c.vars {
+				if _, found := slices.BinarySearch(m.domains[v], tuple[i]); !found {
+					continue Tuples
+				}
+			}
+			result = append(result, tuple)
+		}
+		return result
+	}
+
+	// The least and the most of the terms from position i on, with any
+	// values of their variables
+	k := len(c.vars)
+	least, most := make([]int, k+1), make([]int, k+1)
+	for i := k - 1; i >= 0; i-- {
+		domain := m.domains[c.vars[i]]
+		a, b := c.coeffs[i]*domain[0], c.coeffs[i]*domain[len(domain)-1]
+		least[i] = least[i+1] + min(a, b)
+		most[i] = most[i+1] + max(a, b)
+	}
+
+	// The earlier position of a variable appearing twice, or -1
+	earlier := make([]int, k)
+	for i, v := range c.vars {
+		earlier[i] = slices.Index(c.vars, v)
+		if earlier[i] == i {
+			earlier[i] = -1
+		}
+	}
+
+	// Try the tuples of values in the domains, pruning a partial sum which
+	// can't satisfy the relation whatever the remaining values are, so that
+	// only the tuples which satisfy it are fully visited. A variable
+	// appearing again keeps the value it already has.
+	var result [][]int
+	tuple := make([]int, k)
+	var try func(i int, sum int)
+	try = func(i int, sum int) {
+		if c.rel != GreaterEqual && sum+least[i] > c.rhs {
+			return
+		}
+		if c.rel != LessEqual && sum+most[i] < c.rhs {
+			return
+		}
+		if i == k {
+			result = append(result, slices.Clone(tuple))
+			return
+		}
+		if j := earlier[i]; j >= 0 {
+			tuple[i] = tuple[j]
+			try(i+1, sum+c.coeffs[i]*tuple[i])
+			return
+		}
+		for _, value := range m.domains[c.vars[i]] {
+			tuple[i] = value
+			try(i+1, sum+c.coeffs[i]*value)
+		}
+	}
+	try(0, 0)
+
+	return result
+}
@@ -0,0 +1,274 @@
+package csp
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/wallberg/sandbox-go/taocp"
+)
+
+var encodings = []Encoding{Direct, Log, Order}
+
+// satSolutions returns the decoded solutions of the model compiled to SAT
+func satSolutions(m *Model, encoding Encoding) []map[string]int {
+	p := m.Sat(encoding)
+	var solutions []map[string]int
+	for solution := range taocp.SatAlgorithmDAll(p.N, p.Clauses, nil, nil, p.Projection) {
+		solutions = append(solutions, p.Decode(solution))
+	}
+	return solutions
+}
+
+// xccSolutions returns the decoded solutions of the model compiled to XCC
+func xccSolutions(t *testing.T, m *Model) []map[string]int {
+	p := m.XCC()
+	var solutions []map[string]int
+	for solution, err := range taocp.XCC(p.Items, p.Options, p.Secondary, nil, nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		solutions = append(solutions, p.Decode(solution))
+	}
+	return solutions
+}
+
+// bruteSolutions returns the solutions of the model by trying every
+// assignment of values to the variables
+func bruteSolutions(m *Model) []map[string]int {
+	var solutions []map[string]int
+	values := make(map[string]int)
+	names := m.Names()
+	var try func(i int)
+	try = func(i int) {
+		if i == len(names) {
+			if m.Check(values) {
+				solutions = append(solutions, maps.Clone(values))
+			}
+			return
+		}
+		for _, d := range m.Domain(names[i]) {
+			values[names[i]] = d
+			try(i + 1)
+		}
+	}
+	try(0)
+	return solutions
+}
+
+// solutionKeys returns the solutions as sorted strings, for comparison
+func solutionKeys(solutions []map[string]int) []string {
+	keys := make([]string, len(solutions))
+	for i, solution := range solutions {
+		keys[i] = fmt.Sprint(solution)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestModelErrors(t *testing.T) {
+	m := NewModel()
+
+	cases := []struct {
+		err error
+	}{
+		{m.Var("x", 1, 2, 3)},
+		{m.Var("x", 1)},
+		{m.Var("", 1)},
+		{m.Var("a:b", 1)},
+		{m.Var("y")},
+		{m.Table([]string{"x", "z"}, [][]int{{1, 2}})},
+		{m.Table([]string{"x"}, [][]int{{1, 2}})},
+		{m.AllDifferent("x", "z")},
+		{m.Linear([]int{1, 2}, []string{"x"}, Equal, 3)},
+		{m.Linear([]int{1}, []string{"x"}, Relation(7), 3)},
+	}
+
+	for i, c := range cases {
+		if (c.err == nil) != (i == 0) {
+			t.Errorf("For case #%d, expected error=%v; got %v", i, i != 0, c.err)
+		}
+	}
+
+	if got := m.Domain("x"); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("Expected domain [1 2 3]; got %v", got)
+	}
+}
+
+// TestModelKnuth is the example of (1) and (2) in 7.2.2.3: five letters of a
+// word with three relations, which has two solutions, BLUED and SCION
+func TestModelKnuth(t *testing.T) {
+	m := NewModel()
+	m.Var("x1", 'B', 'S')
+	m.Var("x2", 'C', 'L')
+	m.Var("x3", 'A', 'I', 'U')
+	m.Var("x4", 'E', 'O')
+	m.Var("x5", 'D', 'N')
+	m.Table([]string{"x1", "x3", "x5"}, [][]int{{'B', 'A', 'N'}, {'B', 'U', 'D'}, {'S', 'I', 'N'}})
+	m.Table([]string{"x1", "x4"}, [][]int{{'B', 'E'}, {'S', 'E'}, {'S', 'O'}})
+	m.Table([]string{"x2", "x4", "x5"}, [][]int{{'C', 'O', 'D'}, {'C', 'O', 'N'}, {'L', 'E', 'D'}})
+
+	word := func(values map[string]int) string {
+		b := make([]byte, 5)
+		for i, v := range []string{"x1", "x2", "x3", "x4", "x5"} {
+			b[i] = byte(values[v])
+		}
+		return string(b)
+	}
+
+	expected := []string{"BLUED", "SCION"}
+
+	for _, encoding := range encodings {
+		var got []string
+		for _, solution := range satSolutions(m, encoding) {
+			got = append(got, word(solution))
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, expected) {
+			t.Errorf("For %v encoding, expected %v; got %v", encoding, expected, got)
+		}
+	}
+
+	var got []string
+	for _, solution := range xccSolutions(t, m) {
+		got = append(got, word(solution))
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, expected) {
+		t.Errorf("For XCC, expected %v; got %v", expected, got)
+	}
+}
+
+// TestModelRandom compares the solutions of random models with those found
+// by brute force
+func TestModelRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(19))
+	names := []string{"a", "b", "c", "d"}
+
+	for i := 0; i < 60; i++ {
+		m := NewModel()
+		for _, v := range names {
+			var domain []int
+			for range 1 + rng.Intn(5) {
+				domain = append(domain, rng.Intn(9)-4)
+			}
+			m.Var(v, domain...)
+		}
+
+		// pick returns 1 to 3 random variables, possibly repeated
+		pick := func() []string {
+			vars := make([]string, 1+rng.Intn(3))
+			for j := range vars {
+				vars[j] = names[rng.Intn(len(names))]
+			}
+			return vars
+		}
+
+		for range 1 + rng.Intn(3) {
+			vars := pick()
+			switch rng.Intn(3) {
+			case 0:
+				var tuples [][]int
+				for range rng.Intn(8) {
+					tuple := make([]int, len(vars))
+					for j := range tuple {
+						tuple[j] = rng.Intn(9) - 4
+					}
+					tuples = append(tuples, tuple)
+				}
+				m.Table(vars, tuples)
+			case 1:
+				m.AllDifferent(vars...)
+			case 2:
+				coeffs := make([]int, len(vars))
+				for j := range coeffs {
+					coeffs[j] = rng.Intn(7) - 3
+				}
+				m.Linear(coeffs, vars, Relation(rng.Intn(3)), rng.Intn(9)-4)
+			}
+		}
+
+		expected := solutionKeys(bruteSolutions(m))
+
+		for _, encoding := range encodings {
+			got := solutionKeys(satSolutions(m, encoding))
+			if !slices.Equal(got, expected) {
+				t.Errorf("For case #%d with %v encoding, expected %v; got %v", i, encoding, expected, got)
+			}
+		}
+
+		got := solutionKeys(xccSolutions(t, m))
+		if !slices.Equal(got, expected) {
+			t.Errorf("For case #%d with XCC, expected %v; got %v", i, expected, got)
+		}
+	}
+}
+
+// TestModelSendMoreMoney solves the cryptarithm SEND + MORE = MONEY
+func TestModelSendMoreMoney(t *testing.T) {
+	m := NewModel()
+	letters := []string{"S", "E", "N", "D", "M", "O", "R", "Y"}
+	for _, v := range letters {
+		if v == "S" || v == "M" {
+			m.Var(v, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+		} else {
+			m.Var(v, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+		}
+	}
+	m.AllDifferent(letters...)
+	m.Linear(
+		[]int{1000, 100, 10, 1, 1000, 100, 10, 1, -10000, -1000, -100, -10, -1},
+		[]string{"S", "E", "N", "D", "M", "O", "R", "E", "M", "O", "N", "E", "Y"},
+		Equal, 0)
+
+	expected := map[string]int{"S": 9, "E": 5, "N": 6, "D": 7, "M": 1, "O": 0, "R": 8, "Y": 2}
+
+	for _, encoding := range encodings {
+		p := m.Sat(encoding)
+		sat, solution := taocp.SatAlgorithmC(p.N, p.Clauses, nil, nil)
+		if !sat {
+			t.Errorf("For %v encoding, expected satisfiable", encoding)
+			continue
+		}
+		if got := p.Decode(solution); !maps.Equal(got, expected) {
+			t.Errorf("For %v encoding, expected %v; got %v", encoding, expected, got)
+		}
+		if !m.Check(p.Decode(solution)) {
+			t.Errorf("For %v encoding, expected a solution of the model", encoding)
+		}
+	}
+	got := xccSolutions(t, m)
+	if len(got) != 1 || !maps.Equal(got[0], expected) {
+		t.Errorf("For XCC, expected %v; got %v", expected, got)
+	}
+}
+
+// TestModelLinearXCC compiles a linear constraint on many variables to XCC,
+// whose options are only the few tuples which satisfy it
+func TestModelLinearXCC(t *testing.T) {
+	const n = 40
+	m := NewModel()
+	var (
+		vars   []string
+		coeffs []int
+	)
+	for i := 0; i < n; i++ {
+		v := fmt.Sprintf("x%d", i)
+		m.Var(v, 0, 1)
+		vars = append(vars, v)
+		coeffs = append(coeffs, 1)
+	}
+	m.Linear(coeffs, vars, Equal, 2)
+
+	// One option for each pair of variables which are 1, and two for each
+	// variable
+	p := m.XCC()
+	if expected := n*(n-1)/2 + 2*n; len(p.Options) != expected {
+		t.Errorf("Expected %d options; got %d", expected, len(p.Options))
+	}
+	if got := xccSolutions(t, m); len(got) != n*(n-1)/2 {
+		t.Errorf("Expected %d solutions; got %d", n*(n-1)/2, len(got))
+	}
+}
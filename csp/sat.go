@@ -0,0 +1,393 @@
+package csp
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"slices"
+	"strconv"
+
+	"github.com/wallberg/sandbox-go/taocp"
+)
+
+// Encoding is the representation of the values of a variable by Boolean
+// variables, 7.2.2.2
+type Encoding int
+
+const (
+	// Direct encoding: a variable "v=d" for each value d, exactly one of
+	// which is true
+	Direct Encoding = iota
+	// Log encoding: the index of the value in the domain, in binary, with
+	// a variable "v#i" for bit i
+	Log
+	// Order encoding: a variable "v>=d" for each value d but the smallest,
+	// true if and only if the value is at least d
+	Order
+)
+
+// String returns the name of the encoding
+func (e Encoding) String() string {
+	switch e {
+	case Direct:
+		return "direct"
+	case Log:
+		return "log"
+	case Order:
+		return "order"
+	}
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+// unbounded is the bound of a side of a linear constraint without one
+const unbounded = math.MaxInt / 4
+
+// SatProblem is a Model compiled to SAT clauses
+type SatProblem struct {
+	N          int              // number of variables
+	Clauses    taocp.SatClauses // clauses to satisfy
+	Variables  map[int]string   // names of the variables which encode values
+	Projection []int            // variables which encode values
+
+	encoding Encoding
+	names    []string         // names of the model variables
+	domains  map[string][]int // domain of each model variable
+	lits     map[string][]int // encoding variables of each model variable
+}
+
+// Decode returns the value of each variable of the model in a solution of the
+// clauses, in the form returned by the SAT algorithms: solution[k-1] is 1 if
+// variable k is true, otherwise 0.
+func (p *SatProblem) Decode(solution []int) map[string]int {
+	values := make(map[string]int, len(p.names))
+	for _, v := range p.names {
+		domain := p.domains[v]
+		lits := p.lits[v]
+
+		j := 0
+		switch p.encoding {
+		case Direct:
+			for i, k := range lits {
+				if solution[k-1] == 1 {
+					j = i
+					break
+				}
+			}
+		case Log:
+			for i, k := range lits {
+				j |= solution[k-1] << i
+			}
+		case Order:
+			for _, k := range lits {
+				j += solution[k-1]
+			}
+		}
+
+		if j < len(domain) {
+			values[v] = domain[j]
+		}
+	}
+	return values
+}
+
+// satBuilder accumulates the clauses of a SatProblem, in a circuit whose
+// named variables encode the values and whose gates combine them
+type satBuilder struct {
+	*SatProblem
+	circuit *taocp.SatCircuit // variables, gates and clauses
+	eqs     map[string][]int  // literal of each value of a variable, 0 if not yet created
+}
+
+// variable returns a new variable with the given name, which encodes values
+func (b *satBuilder) variable(name string) int {
+	k := b.circuit.Var(name)
+	b.Projection = append(b.Projection, k)
+	return k
+}
+
+// add adds a clause, without duplicate literals; a tautology is not added
+func (b *satBuilder) add(lits ...int) {
+	clause := make([]int, 0, len(lits))
+	for _, l := range lits {
+		if slices.Contains(clause, -l) {
+			return
+		}
+		if !slices.Contains(clause, l) {
+			clause = append(clause, l)
+		}
+	}
+	b.circuit.Assert(clause...)
+}
+
+// contradiction adds a clause which can't be satisfied
+func (b *satBuilder) contradiction() {
+	b.circuit.Assert(b.circuit.False())
+}
+
+// encode adds the encoding variables of v and the clauses restricting them to
+// the values of its domain
+func (b *satBuilder) encode(v string) {
+	domain := b.domains[v]
+	k := len(domain)
+	var lits []int
+
+	switch b.encoding {
+	case Direct:
+		for _, d := range domain {
+			lits = append(lits, b.variable(v+"="+strconv.Itoa(d)))
+		}
+
+		// At least one value
+		b.add(lits...)
+
+		// At most one value
+		if k <= 6 {
+			for i := 0; i < k; i++ {
+				for j := i + 1; j < k; j++ {
+					b.add(-lits[i], -lits[j])
+				}
+			}
+		} else {
+			b.circuit.Encode(func(startV int) (taocp.SatClauses, int) {
+				return taocp.SatSequentialCounter(0, 1, lits, startV)
+			})
+		}
+
+	case Log:
+		m := bits.Len(uint(k - 1))
+		for i := 0; i < m; i++ {
+			lits = append(lits, b.variable(v+"#"+strconv.Itoa(i)))
+		}
+
+		// The index is at most k-1: if bit i of k-1 is 0, then bit i may
+		// only be 1 if a higher bit which is 1 in k-1 is 0
+		for i := 0; i < m; i++ {
+			if (k-1)>>i&1 == 1 {
+				continue
+			}
+			clause := []int{-lits[i]}
+			for j := i + 1; j < m; j++ {
+				if (k-1)>>j&1 == 1 {
+					clause = append(clause, -lits[j])
+				}
+			}
+			b.add(clause...)
+		}
+
+	case Order:
+		for _, d := range domain[1:] {
+			lits = append(lits, b.variable(v+">="+strconv.Itoa(d)))
+		}
+
+		// x >= d_{j+1} implies x >= d_j
+		for j := 1; j < len(lits); j++ {
+			b.add(-lits[j], lits[j-1])
+		}
+	}
+
+	b.lits[v] = lits
+	b.eqs[v] = make([]int, k)
+}
+
+// eq returns a literal which is true if and only if v has the j-th value of
+// its domain
+func (b *satBuilder) eq(v string, j int) int {
+	if l := b.eqs[v][j]; l != 0 {
+		return l
+	}
+
+	lits := b.lits[v]
+	var l int
+
+	switch b.encoding {
+	case Direct:
+		l = lits[j]
+
+	case Log:
+		inputs := make([]int, len(lits))
+		for i, k := range lits {
+			if j>>i&1 == 1 {
+				inputs[i] = k
+			} else {
+				inputs[i] = -k
+			}
+		}
+		l = b.circuit.And(inputs...)
+
+	case Order:
+		var inputs []int
+		if j > 0 {
+			inputs = append(inputs, lits[j-1])
+		}
+		if j < len(lits) {
+			inputs = append(inputs, -lits[j])
+		}
+		l = b.circuit.And(inputs...)
+	}
+
+	b.eqs[v][j] = l
+	return l
+}
+
+// eqValue returns a literal which is true if and only if v has the value d,
+// or 0 if d is not in its domain
+func (b *satBuilder) eqValue(v string, d int) int {
+	j, found := slices.BinarySearch(b.domains[v], d)
+	if !found {
+		return 0
+	}
+	return b.eq(v, j)
+}
+
+// terms returns literals and weights whose weighted sum, plus a constant, is
+// the value of v
+func (b *satBuilder) terms(v string) (lits []int, weights []int, constant int) {
+	domain := b.domains[v]
+	if len(domain) == 1 {
+		return nil, nil, domain[0]
+	}
+
+	switch b.encoding {
+	case Order:
+		// d_0 + (d_1 - d_0) [x >= d_1] + (d_2 - d_1) [x >= d_2] + ...
+		lits = b.lits[v]
+		for j := 1; j < len(domain); j++ {
+			weights = append(weights, domain[j]-domain[j-1])
+		}
+		return lits, weights, domain[0]
+
+	case Log:
+		// d_0 + s * index, if the domain is an arithmetic progression
+		arithmetic := true
+		for j := 2; j < len(domain); j++ {
+			if domain[j]-domain[j-1] != domain[1]-domain[0] {
+				arithmetic = false
+				break
+			}
+		}
+		if arithmetic {
+			lits = b.lits[v]
+			for i := range lits {
+				weights = append(weights, (domain[1]-domain[0])<<i)
+			}
+			return lits, weights, domain[0]
+		}
+	}
+
+	// d_0 [x = d_0] + d_1 [x = d_1] + ...
+	for j, d := range domain {
+		lits = append(lits, b.eq(v, j))
+		weights = append(weights, d)
+	}
+	return lits, weights, 0
+}
+
+// Sat compiles the model to SAT clauses, with the values of each variable
+// represented by the given encoding. The variables which encode the values
+// are named, and their assignments in a solution of the clauses determine the
+// values of the model variables, by Decode; the other variables are
+// auxiliary, and determined by them.
+func (m *Model) Sat(encoding Encoding) *SatProblem {
+	b := &satBuilder{
+		SatProblem: &SatProblem{
+			encoding: encoding,
+			names:    slices.Clone(m.names),
+			domains:  m.domains,
+			lits:     make(map[string][]int),
+		},
+		circuit: taocp.NewSatCircuit(),
+		eqs:     make(map[string][]int),
+	}
+
+	for _, v := range m.names {
+		b.encode(v)
+	}
+
+	for i := range m.constraints {
+		c := &m.constraints[i]
+
+		switch c.kind {
+		case table:
+			// A selector for each tuple, which implies its values
+			var selectors []int
+		Tuples:
+			for _, tuple := range c.tuples {
+				var values []int
+				for j, v := range c.vars {
+					l := b.eqValue(v, tuple[j])
+					if l == 0 {
+						continue Tuples
+					}
+					values = append(values, l)
+				}
+				selectors = append(selectors, b.circuit.And(values...))
+			}
+			if len(selectors) == 0 {
+				b.contradiction()
+			} else {
+				b.add(selectors...)
+			}
+
+		case allDifferent:
+			for j, u := range c.vars {
+				for _, v := range c.vars[j+1:] {
+					if u == v {
+						b.contradiction()
+						continue
+					}
+					for _, d := range b.domains[u] {
+						if l := b.eqValue(v, d); l != 0 {
+							b.add(-b.eqValue(u, d), -l)
+						}
+					}
+				}
+			}
+
+		case linear:
+			// Merge the coefficients of each variable
+			coeffs := make(map[string]int)
+			var vars []string
+			for j, v := range c.vars {
+				if _, ok := coeffs[v]; !ok {
+					vars = append(vars, v)
+				}
+				coeffs[v] += c.coeffs[j]
+			}
+
+			var (
+				lits     []int
+				weights  []int
+				constant int
+			)
+			for _, v := range vars {
+				if coeffs[v] == 0 {
+					continue
+				}
+				vlits, vweights, vconstant := b.terms(v)
+				lits = append(lits, vlits...)
+				for _, w := range vweights {
+					weights = append(weights, coeffs[v]*w)
+				}
+				constant += coeffs[v] * vconstant
+			}
+
+			lo, hi := -unbounded, unbounded
+			if c.rel != GreaterEqual {
+				hi = c.rhs - constant
+			}
+			if c.rel != LessEqual {
+				lo = c.rhs - constant
+			}
+
+			b.circuit.Encode(func(startV int) (taocp.SatClauses, int) {
+				return taocp.SatPseudoBoolean(lo, hi, weights, lits, startV)
+			})
+		}
+	}
+
+	b.N = b.circuit.N()
+	b.Clauses = b.circuit.Clauses()
+	b.Variables = b.circuit.Variables()
+
+	return b.SatProblem
+}
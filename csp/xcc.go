@@ -0,0 +1,128 @@
+package csp
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// XCCProblem is a Model compiled to an exact cover problem with colors, the
+// arguments of taocp.XCC
+type XCCProblem struct {
+	Items     []string   // primary items
+	Options   [][]string // options
+	Secondary []string   // secondary items
+}
+
+// Decode returns the value of each variable of the model in a solution of the
+// problem, from the colors of the secondary items "v="
+func (p *XCCProblem) Decode(solution [][]string) map[string]int {
+	values := make(map[string]int)
+	for _, option := range solution {
+		for _, item := range option {
+			i := strings.Index(item, "=:")
+			if i < 0 {
+				continue
+			}
+			if value, err := strconv.Atoi(item[i+2:]); err == nil {
+				values[item[:i]] = value
+			}
+		}
+	}
+	return values
+}
+
+// XCC compiles the model to an exact cover problem with colors, 7.2.2.3.
+//
+// Each variable v is a primary item, covered by an option for each value d of
+// its domain, which gives the secondary item "v=" the color d. A table or
+// linear constraint is a primary item "#i", covered by an option for each
+// tuple of values which satisfies it, giving the items of its variables those
+// colors. An all-different constraint "#i" has a secondary item "#i=d" for
+// each value d, included in the options which give a variable that value, so
+// at most one of them can be chosen.
+//
+// The tuples of a linear constraint are found by trying the values of its
+// variables in turn, abandoning a partial sum which can't satisfy it whatever
+// the remaining values are, rather than trying every tuple of the domains.
+// But the number of tuples which satisfy it may still grow exponentially
+// with the number of variables; Sat encodes a large linear constraint more
+// compactly.
+func (m *Model) XCC() *XCCProblem {
+	p := &XCCProblem{}
+
+	// different lists the all-different constraints of each variable
+	different := make(map[string][]string)
+
+	for i := range m.constraints {
+		c := &m.constraints[i]
+		name := "#" + strconv.Itoa(i)
+
+		switch c.kind {
+		case allDifferent:
+			values := make(map[int]bool)
+			for _, v := range c.vars {
+				different[v] = append(different[v], name)
+				for _, d := range m.domains[v] {
+					values[d] = true
+				}
+			}
+			var sorted []int
+			for d := range values {
+				sorted = append(sorted, d)
+			}
+			slices.Sort(sorted)
+			for _, d := range sorted {
+				p.Secondary = append(p.Secondary, name+"="+strconv.Itoa(d))
+			}
+
+		default:
+			p.Items = append(p.Items, name)
+			seen := make(map[string]bool)
+		Tuples:
+			for _, tuple := range m.tuples(c) {
+				option := []string{name}
+				for j, v := range c.vars {
+					item := v + "=:" + strconv.Itoa(tuple[j])
+					for _, other := range option[1:] {
+						if strings.HasPrefix(other, v+"=:") && other != item {
+							// A repeated variable with two values
+							continue Tuples
+						}
+					}
+					if !slices.Contains(option, item) {
+						option = append(option, item)
+					}
+				}
+				// Repeated tuples would give repeated solutions
+				if key := strings.Join(option, " "); !seen[key] {
+					seen[key] = true
+					p.Options = append(p.Options, option)
+				}
+			}
+		}
+	}
+
+	for _, v := range m.names {
+		p.Items = append(p.Items, v)
+		p.Secondary = append(p.Secondary, v+"=")
+		for _, d := range m.domains[v] {
+			value := strconv.Itoa(d)
+			option := []string{v, v + "=:" + value}
+			for _, name := range different[v] {
+				item := name + "=" + value
+				if slices.Contains(option, item) {
+					// A repeated variable can't differ from itself
+					option = nil
+					break
+				}
+				option = append(option, item)
+			}
+			if option != nil {
+				p.Options = append(p.Options, option)
+			}
+		}
+	}
+
+	return p
+}
@@ -0,0 +1,303 @@
+package taocp
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"log"
+	"slices"
+	"strings"
+)
+
+// XCCCells solves the same exact covering with colors problems as XCC, using
+// the sparse-set "dancing cells" representation of Knuth's 2022 pre-fascicle
+// instead of doubly linked lists. The list of each item is a segment of one
+// array, whose first size(i) entries are the active nodes; a node is removed
+// by swapping it with the last active entry, and restored simply by
+// increasing the size again, in the reverse order of removal.
+//
+// Items are chosen and options tried in the same order as XCC, so the
+// solutions are returned in the same order and stats has the same values.
+// Of xccOptions only EnableSharpPreference is supported.
+func XCCCells(ctx context.Context, items []string, options [][]string,
+	secondary []string, stats *ExactCoverStats,
+	xccOptions *XCCOptions) iter.Seq2[[][]string, error] {
+
+	return func(yield func([][]string, error) bool) {
+
+		if xccOptions == nil {
+			// Use all default values
+			xccOptions = &XCCOptions{}
+		}
+
+		if xccOptions.Minimax || xccOptions.Exercise83 || xccOptions.Costs != nil ||
+			xccOptions.Checkpoint != nil || xccOptions.Resume != nil {
+			yield(nil, fmt.Errorf("only EnableSharpPreference is supported by XCCCells"))
+			return
+		}
+
+		if err := xccValidate(items, options, secondary); err != nil {
+			yield(nil, err)
+			return
+		}
+
+		var (
+			n1      int   // number of primary items
+			n       int   // total number of items
+			m       int   // total number of options
+			itm     []int // item of each node
+			clr     []int // color of each node, 0 if none
+			opt     []int // option of each node
+			first   []int // first node of each option, plus a sentinel
+			set     []int // nodes in the list of each item
+			loc     []int // location of each node in set
+			start   []int // start of the list of each item in set
+			size    []int // number of active nodes in the list of each item
+			active  []int // sparse set of the active primary items
+			nActive int   // number of active primary items
+			trail   []int // items whose size was decreased, in order
+			state   []int // node chosen at each level
+			ticks   int   // nodes visited since the context was checked
+			err     error // context error which halted the search
+		)
+
+		// Fill out the item tables
+		n1 = len(items)
+		n = n1 + len(secondary)
+		m = len(options)
+
+		if stats != nil {
+			stats.Theta = stats.Delta
+			stats.MaxLevel = -1
+			// A solution may have one option per item, at level n
+			if stats.Levels == nil {
+				stats.Levels = make([]int, n+1)
+			} else {
+				for len(stats.Levels) < n+1 {
+					stats.Levels = append(stats.Levels, 0)
+				}
+			}
+		}
+
+		index := make(map[string]int)
+		for j, item := range append(slices.Clone(items), secondary...) {
+			index[item] = j + 1
+		}
+
+		colors := map[string]int{"": 0}
+
+		// Fill out the node tables
+		llen := make([]int, n+1)
+		first = make([]int, m+1)
+		for k, option := range options {
+			first[k] = len(itm)
+			for _, item := range option {
+				name, color, _ := strings.Cut(item, ":")
+				if _, ok := colors[color]; !ok {
+					colors[color] = len(colors)
+				}
+				i := index[name]
+				itm = append(itm, i)
+				clr = append(clr, colors[color])
+				opt = append(opt, k)
+				llen[i]++
+			}
+		}
+		first[m] = len(itm)
+
+		// Fill out the list of each item, in order of the options
+		start = make([]int, n+2)
+		size = make([]int, n+1)
+		for i := 1; i <= n; i++ {
+			start[i+1] = start[i] + llen[i]
+		}
+		set = make([]int, len(itm))
+		loc = make([]int, len(itm))
+		for x, i := range itm {
+			loc[x] = start[i] + size[i]
+			set[loc[x]] = x
+			size[i]++
+		}
+
+		active = make([]int, n1)
+		for j := range active {
+			active[j] = j + 1
+		}
+		nActive = n1
+		apos := make([]int, n1+1) // location of each primary item in active
+		for j, i := range active {
+			apos[i] = j
+		}
+
+		state = make([]int, n+1)
+		tries := make([][]int, n+1) // options of the chosen item at each level
+
+		// remove removes node x from the list of its item
+		remove := func(x int) {
+			i := itm[x]
+			last := start[i] + size[i] - 1
+			y := set[last]
+			set[loc[x]], set[last] = y, x
+			loc[x], loc[y] = last, loc[x]
+			size[i]--
+			trail = append(trail, i)
+		}
+
+		// hide removes option k from the lists of its items, other than i
+		hide := func(k, i int) {
+			for x := first[k]; x < first[k+1]; x++ {
+				if itm[x] != i {
+					remove(x)
+				}
+			}
+		}
+
+		// deactivate removes primary item i from the active items
+		deactivate := func(i int) {
+			last := active[nActive-1]
+			active[apos[i]], active[nActive-1] = last, i
+			apos[i], apos[last] = nActive-1, apos[i]
+			nActive--
+		}
+
+		// cover hides the options of item i other than option k
+		cover := func(i, k int) {
+			for p := start[i]; p < start[i]+size[i]; p++ {
+				if x := set[p]; opt[x] != k {
+					hide(opt[x], i)
+				}
+			}
+		}
+
+		// purify hides the options of secondary item i whose color is not c,
+		// also removing them from the list of i
+		purify := func(i, c int) {
+			for p := start[i] + size[i] - 1; p >= start[i]; p-- {
+				if x := set[p]; clr[x] != c {
+					hide(opt[x], i)
+					remove(x)
+				}
+			}
+		}
+
+		// choose selects the next item using the Minimum Remaining Values
+		// heuristic, with the same preferences and tie breaking as XCC
+		choose := func() int {
+			i := 0
+			theta := -1
+			for _, p := range active[:nActive] {
+				lambda := size[p]
+				if xccOptions.EnableSharpPreference && lambda > 1 && !strings.HasPrefix(items[p-1], "#") {
+					lambda += m
+				}
+				if lambda < theta || theta == -1 || (lambda == theta && p < i) {
+					theta = lambda
+					i = p
+				}
+			}
+			return i
+		}
+
+		// visit yields the options of the current solution
+		visit := func(level int) bool {
+			solution := make([][]string, level)
+			for l, x := range state[:level] {
+				solution[l] = slices.Clone(options[opt[x]])
+			}
+			if stats != nil && stats.Debug {
+				log.Printf("visit(%v)", solution)
+			}
+			return yield(solution, nil)
+		}
+
+		// search explores the subproblem at level, returning false if the
+		// search should halt
+		var search func(level int) bool
+		search = func(level int) bool {
+
+			ticks++
+			if ticks&(contextCheckInterval-1) == 0 {
+				if err = ctx.Err(); err != nil {
+					return false
+				}
+			}
+
+			if stats != nil {
+				stats.Levels[level]++
+				stats.Nodes++
+
+				if stats.Progress {
+					if level > stats.MaxLevel {
+						stats.MaxLevel = level
+					}
+					if stats.Nodes >= stats.Theta {
+						log.Printf("Current level %d of max %d, %v", level, stats.MaxLevel, *stats)
+						stats.Theta += stats.Delta
+					}
+				}
+			}
+
+			if nActive == 0 {
+				if stats != nil {
+					stats.Solutions++
+				}
+				return visit(level)
+			}
+
+			i := choose()
+
+			// Try the options of i in their original order
+			tries[level] = append(tries[level][:0], set[start[i]:start[i]+size[i]]...)
+			slices.Sort(tries[level])
+
+			for _, x := range tries[level] {
+				k := opt[x]
+				state[level] = x
+				mark, marked := len(trail), nActive
+
+				// Commit each of the items in option k
+				for y := first[k]; y < first[k+1]; y++ {
+					j := itm[y]
+					if j <= n1 {
+						deactivate(j)
+					}
+					if j <= n1 || clr[y] == 0 {
+						cover(j, k)
+					} else {
+						purify(j, clr[y])
+					}
+				}
+
+				resume := search(level + 1)
+
+				// Restore the lists and active items, in reverse order
+				for len(trail) > mark {
+					size[trail[len(trail)-1]]++
+					trail = trail[:len(trail)-1]
+				}
+				nActive = marked
+
+				if !resume {
+					return false
+				}
+
+				if stats != nil {
+					stats.Nodes++
+				}
+			}
+
+			return true
+		}
+
+		if err = ctx.Err(); err != nil {
+			yield(nil, err)
+			return
+		}
+
+		search(0)
+
+		if err != nil {
+			yield(nil, err)
+		}
+	}
+}
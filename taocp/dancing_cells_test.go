@@ -0,0 +1,146 @@
+package taocp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// testXCCCells verifies that XCCCells returns the same solutions, in the same
+// order, with the same statistics as XCC
+func testXCCCells(t *testing.T, items []string, options [][]string,
+	secondary []string, xccOptions *XCCOptions) {
+
+	var expected [][][]string
+	expectedStats := &ExactCoverStats{}
+	for solution, err := range XCC(items, options, secondary, expectedStats, xccOptions) {
+		if err != nil {
+			t.Fatalf("XCC returned error %v", err)
+		}
+		expected = append(expected, solution)
+	}
+
+	var got [][][]string
+	stats := &ExactCoverStats{}
+	for solution, err := range XCCCells(context.Background(), items, options, secondary, stats, xccOptions) {
+		if err != nil {
+			t.Fatalf("XCCCells returned error %v", err)
+		}
+		got = append(got, solution)
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected solutions %v; got %v", expected, got)
+	}
+	if !reflect.DeepEqual(stats, expectedStats) {
+		t.Errorf("Expected stats %v; got %v", expectedStats, stats)
+	}
+}
+
+// randomXCC returns a random XCC problem with colored secondary items
+func randomXCC(rng *rand.Rand, n1, n2, m int) ([]string, [][]string, []string) {
+	items := make([]string, n1)
+	for i := range items {
+		items[i] = fmt.Sprintf("p%d", i)
+	}
+	secondary := make([]string, n2)
+	for i := range secondary {
+		secondary[i] = fmt.Sprintf("s%d", i)
+	}
+
+	options := make([][]string, m)
+	for k := range options {
+		for _, i := range rng.Perm(n1)[:1+rng.Intn(2)] {
+			options[k] = append(options[k], items[i])
+		}
+		for _, i := range rng.Perm(n2)[:rng.Intn(3)] {
+			if c := rng.Intn(3); c > 0 {
+				options[k] = append(options[k], fmt.Sprintf("%s:%c", secondary[i], 'A'+c-1))
+			} else {
+				options[k] = append(options[k], secondary[i])
+			}
+		}
+	}
+
+	return items, options, secondary
+}
+
+func TestXCCCells(t *testing.T) {
+
+	items, options, secondary := nQueensXC(8)
+	testXCCCells(t, items, options, secondary, nil)
+	testXCCCells(t, items, options, secondary, &XCCOptions{EnableSharpPreference: true})
+	testXCCCells(t, xcItems, xcOptions, []string{}, nil)
+	testXCCCells(t, xccItems, xccOptions, xccSItems, nil)
+	testXCCCells(t, xccMinimaxItems, xccMinimaxOptions, xccMinimaxSItems, nil)
+	testXCCCells(t, []string{"a", "b", "c"},
+		[][]string{{"a"}, {"b"}, {"c"}, {"a", "b"}, {"b", "c"}, {"a", "b", "c"}}, []string{}, nil)
+
+	rng := rand.New(rand.NewSource(0))
+	for k := 0; k < 100; k++ {
+		items, options, secondary := randomXCC(rng, 6, 4, 20)
+		testXCCCells(t, items, options, secondary, nil)
+	}
+
+	// Halt early
+	count := 0
+	for _, err := range XCCCells(context.Background(), items, options, secondary, nil, nil) {
+		if err != nil {
+			t.Fatalf("XCCCells returned error %v", err)
+		}
+		count++
+		if count == 10 {
+			break
+		}
+	}
+
+	// Errors
+	for _, err := range XCCCells(context.Background(), xcItems, xcOptions, []string{}, nil, &XCCOptions{Minimax: true}) {
+		if err == nil || err.Error() != "only EnableSharpPreference is supported by XCCCells" {
+			t.Errorf("Expected error for minimax; got %v", err)
+		}
+	}
+	for _, err := range XCCCells(context.Background(), xcItems, [][]string{{"z"}}, []string{}, nil, nil) {
+		if err == nil || err.Error() != "option '[z]' contains 'z' which is not an item or secondary item" {
+			t.Errorf("Expected error for invalid item; got %v", err)
+		}
+	}
+}
+
+func TestXCCCellsContext(t *testing.T) {
+
+	items, options, secondary := nQueensXC(10)
+
+	// Already canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for solution, err := range XCCCells(ctx, items, options, secondary, nil, nil) {
+		if !errors.Is(err, context.Canceled) || solution != nil {
+			t.Errorf("Expected only error %v; got solution=%v, err=%v", context.Canceled, solution, err)
+		}
+	}
+
+	// Canceled during the search
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	testContextCancel(t, cancel, XCCCells(ctx, items, options, secondary, nil, nil), 724)
+}
+
+func BenchmarkXCCCells(b *testing.B) {
+	items, options, secondary := nQueensXC(10)
+	b.Run("links", func(b *testing.B) {
+		for repeat := 0; repeat < b.N; repeat++ {
+			for range XCC(items, options, secondary, nil, nil) {
+			}
+		}
+	})
+	b.Run("cells", func(b *testing.B) {
+		for repeat := 0; repeat < b.N; repeat++ {
+			for range XCCCells(context.Background(), items, options, secondary, nil, nil) {
+			}
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package taocp
 
 import (
+	"context"
 	"fmt"
 	"iter"
 	"log"
@@ -43,11 +44,16 @@ func (s ExactCoverStats) String() string {
 		s.Solutions, s.Levels[:i])
 }
 
+// contextCheckInterval is the number of nodes visited between checks of the
+// context for cancellation; must be a power of 2
+const contextCheckInterval = 1 << 10
+
 // ExactCoverYaml provides YAML (de-)serialization for Exact Cover input
 type ExactCoverYaml struct {
-	Items   []string `yaml:""` // Primary Items
-	SItems  []string `yaml:""` // Secondary Items
-	Options []string `yaml:""` // Options
+	Items   []string `yaml:""`           // Primary Items
+	SItems  []string `yaml:""`           // Secondary Items
+	Options []string `yaml:""`           // Options
+	Costs   []int    `yaml:",omitempty"` // Cost of each option, optional
 }
 
 // NewExactCoverYaml creates a new instance of ExactCoverYaml
@@ -95,6 +101,21 @@ func ExactCover(items []string, options [][]string, secondary []string,
 	stats *ExactCoverStats) iter.Seq[[][]string] {
 
 	return func(yield func([][]string) bool) {
+		for solution := range ExactCoverContext(context.Background(), items, options, secondary, stats) {
+			if !yield(solution) {
+				return
+			}
+		}
+	}
+}
+
+// ExactCoverContext is like ExactCover, but checks ctx periodically during
+// the search. If ctx is canceled or its deadline is exceeded, the error is
+// returned as the final value of the sequence.
+func ExactCoverContext(ctx context.Context, items []string, options [][]string,
+	secondary []string, stats *ExactCoverStats) iter.Seq2[[][]string, error] {
+
+	return func(yield func([][]string, error) bool) {
 		var (
 			n1    int      // number of primary items
 			n2    int      // number of secondary items
@@ -109,6 +130,7 @@ func ExactCover(items []string, options [][]string, secondary []string,
 			level int
 			state []int // search state
 			debug bool  // is debug enabled?
+			ticks int   // nodes visited since the context was checked
 		)
 
 		dump := func() {
@@ -318,7 +340,7 @@ func ExactCover(items []string, options [][]string, secondary []string,
 				}
 			}
 
-			return yield(options)
+			return yield(options, nil)
 		}
 
 		// mrv selects the next item to try using the Minimum Remaining
@@ -394,6 +416,10 @@ func ExactCover(items []string, options [][]string, secondary []string,
 		}
 
 		// X1 [Initialize.]
+		if err := ctx.Err(); err != nil {
+			yield(nil, err)
+			return
+		}
 		initialize()
 
 		var (
@@ -408,6 +434,14 @@ func ExactCover(items []string, options [][]string, secondary []string,
 			log.Printf("X2. level=%d, x=%v\n", level, state[0:level])
 		}
 
+		ticks++
+		if ticks&(contextCheckInterval-1) == 0 {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+
 		if stats != nil {
 			stats.Levels[level]++
 			stats.Nodes++
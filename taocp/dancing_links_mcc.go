@@ -1,6 +1,7 @@
 package taocp
 
 import (
+	"context"
 	"fmt"
 	"iter"
 	"log"
@@ -41,6 +42,43 @@ import (
 func MCC(items []string, multiplicities [][2]int, options [][]string,
 	secondary []string, stats *ExactCoverStats) iter.Seq2[[][]string, error] {
 
+	return MCCContext(context.Background(), items, multiplicities, options, secondary, stats)
+}
+
+// MCCContext is like MCC, but checks ctx periodically during the search. If
+// ctx is canceled or its deadline is exceeded, the error is returned as the
+// final value of the sequence.
+func MCCContext(ctx context.Context, items []string, multiplicities [][2]int,
+	options [][]string, secondary []string,
+	stats *ExactCoverStats) iter.Seq2[[][]string, error] {
+
+	return mcc(ctx, items, multiplicities, options, secondary, stats, false)
+}
+
+// MCCCount is like MCCContext, but only counts the solutions instead of
+// building and returning each one. It returns the number of solutions
+// visited; stats, if not nil, has the same values as for MCC.
+func MCCCount(ctx context.Context, items []string, multiplicities [][2]int,
+	options [][]string, secondary []string,
+	stats *ExactCoverStats) (int, error) {
+
+	count := 0
+	for _, err := range mcc(ctx, items, multiplicities, options, secondary, stats, true) {
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// mcc implements MCCContext and MCCCount; when countOnly is true each
+// solution is visited as nil instead of building its options
+func mcc(ctx context.Context, items []string, multiplicities [][2]int,
+	options [][]string, secondary []string, stats *ExactCoverStats,
+	countOnly bool) iter.Seq2[[][]string, error] {
+
 	return func(yield func([][]string, error) bool) {
 
 		var (
@@ -65,6 +103,7 @@ func MCC(items []string, multiplicities [][2]int, options [][]string,
 			bound    []int    // upper limit on number of options for a primary item
 			debug    bool     // is debug enabled?
 			progress bool     // is progress enabled?
+			ticks    int      // nodes visited since the context was checked
 		)
 
 		dump := func() {
@@ -385,6 +424,10 @@ func MCC(items []string, multiplicities [][2]int, options [][]string,
 
 		lvisit := func() bool {
 
+			if countOnly {
+				return yield(nil, nil)
+			}
+
 			// Only one of the secondary items will have it's color value, the
 			// others will have -1. Save the color and add it to all the matching
 			// secondary items at the end.
@@ -647,6 +690,10 @@ func MCC(items []string, multiplicities [][2]int, options [][]string,
 			yield(nil, err)
 			return
 		}
+		if err := ctx.Err(); err != nil {
+			yield(nil, err)
+			return
+		}
 		initialize()
 
 		var (
@@ -667,6 +714,14 @@ func MCC(items []string, multiplicities [][2]int, options [][]string,
 			log.Printf("M2. l=%d, x[0:l]=%v\n", level, state[0:level])
 		}
 
+		ticks++
+		if ticks&(contextCheckInterval-1) == 0 {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+
 		if stats != nil {
 			stats.Levels[level]++
 			stats.Nodes++
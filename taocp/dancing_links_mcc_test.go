@@ -1,6 +1,8 @@
 package taocp
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
@@ -245,6 +247,85 @@ func TestMCC(t *testing.T) {
 	}
 }
 
+func TestMCCContext(t *testing.T) {
+
+	items, options, secondary := nQueensXC(10)
+	multiplicities := make([][2]int, len(items))
+	for i := range multiplicities {
+		multiplicities[i] = [2]int{1, 1}
+	}
+
+	// Already canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for solution, err := range MCCContext(ctx, items, multiplicities, options, secondary, nil) {
+		if !errors.Is(err, context.Canceled) || solution != nil {
+			t.Errorf("Expected only error %v; got solution=%v, err=%v", context.Canceled, solution, err)
+		}
+	}
+
+	// Canceled during the search
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	testContextCancel(t, cancel, MCCContext(ctx, items, multiplicities, options, secondary, nil), 724)
+
+	// Deadline exceeded
+	ctx, cancel = context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	for _, err := range MCCContext(ctx, items, multiplicities, options, secondary, nil) {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected error %v; got %v", context.DeadlineExceeded, err)
+		}
+	}
+}
+
+func TestMCCCount(t *testing.T) {
+
+	items, options, secondary := nQueensXC(8)
+	multiplicities := make([][2]int, len(items))
+	for i := range multiplicities {
+		multiplicities[i] = [2]int{1, 1}
+	}
+
+	cases := []struct {
+		items          []string
+		multiplicities [][2]int
+		options        [][]string
+		secondary      []string
+		expected       int
+	}{
+		{items, multiplicities, options, secondary, 92},
+		{
+			[]string{"a", "b"},
+			[][2]int{{0, 1}, {1, 2}},
+			[][]string{{"a", "b"}, {"a"}, {"b"}},
+			[]string{},
+			4,
+		},
+	}
+
+	for i, c := range cases {
+		expectedStats := &ExactCoverStats{}
+		for _, err := range MCC(c.items, c.multiplicities, c.options, c.secondary, expectedStats) {
+			if err != nil {
+				t.Fatalf("MCC returned error %v", err)
+			}
+		}
+
+		stats := &ExactCoverStats{}
+		count, err := MCCCount(context.Background(), c.items, c.multiplicities, c.options, c.secondary, stats)
+		if err != nil {
+			t.Errorf("For case #%d, expected no error; got %v", i, err)
+		}
+		if count != c.expected {
+			t.Errorf("For case #%d, expected %d solutions; got %d", i, c.expected, count)
+		}
+		if !reflect.DeepEqual(stats, expectedStats) {
+			t.Errorf("For case #%d, expected stats %v; got %v", i, expectedStats, stats)
+		}
+	}
+}
+
 func TestExercise_7221_69(t *testing.T) {
 	// This verifies Exercise 7.2.2.1-69, Gerrymandering in Bitland
 
@@ -1,7 +1,10 @@
 package taocp
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"iter"
 	"reflect"
 	"testing"
 
@@ -176,6 +179,91 @@ func testNQueens(t *testing.T, n int, expected int) {
 	}
 }
 
+// nQueensXC returns the items, options, and secondary items of the n-queens
+// problem, as constructed by NQueens
+func nQueensXC(n int) (items []string, options [][]string, secondary []string) {
+	for i := 1; i <= n; i++ {
+		items = append(items, fmt.Sprintf("r%d", i), fmt.Sprintf("c%d", i))
+	}
+	for i := 2; i <= 2*n; i++ {
+		secondary = append(secondary, fmt.Sprintf("a%d", i))
+	}
+	for i := 1 - n; i < n; i++ {
+		secondary = append(secondary, fmt.Sprintf("b%d", i))
+	}
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= n; j++ {
+			options = append(options, []string{
+				fmt.Sprintf("r%d", i),
+				fmt.Sprintf("c%d", j),
+				fmt.Sprintf("a%d", i+j),
+				fmt.Sprintf("b%d", i-j),
+			})
+		}
+	}
+	return items, options, secondary
+}
+
+// testContextCancel runs the sequence, cancelling the context after the first
+// solution, and checks that the search halts early with context.Canceled
+func testContextCancel(t *testing.T, cancel context.CancelFunc,
+	seq iter.Seq2[[][]string, error], total int) {
+
+	count := 0
+	var lastErr error
+	for solution, err := range seq {
+		if err != nil {
+			lastErr = err
+			break
+		}
+		if solution == nil {
+			t.Errorf("Expected a solution; got nil")
+		}
+		count++
+		if count == 1 {
+			cancel()
+		}
+	}
+
+	if !errors.Is(lastErr, context.Canceled) {
+		t.Errorf("Expected error %v; got %v", context.Canceled, lastErr)
+	}
+	if count == 0 || count >= total {
+		t.Errorf("Expected search to halt after 1 and before %d solutions; got %d", total, count)
+	}
+}
+
+func TestExactCoverContext(t *testing.T) {
+
+	items, options, secondary := nQueensXC(10)
+
+	// Already canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for solution, err := range ExactCoverContext(ctx, items, options, secondary, nil) {
+		if !errors.Is(err, context.Canceled) || solution != nil {
+			t.Errorf("Expected only error %v; got solution=%v, err=%v", context.Canceled, solution, err)
+		}
+	}
+
+	// Canceled during the search
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	testContextCancel(t, cancel, ExactCoverContext(ctx, items, options, secondary, nil), 724)
+
+	// Not canceled
+	count := 0
+	for _, err := range ExactCoverContext(context.Background(), items, options, secondary, nil) {
+		if err != nil {
+			t.Errorf("Expected no error; got %v", err)
+		}
+		count++
+	}
+	if count != 724 {
+		t.Errorf("Expected 724 solutions; got %d", count)
+	}
+}
+
 func BenchmarkNQueens(b *testing.B) {
 	for _, n := range []int{8, 11, 13} {
 		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
@@ -1,9 +1,14 @@
 package taocp
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"iter"
 	"log"
+	"math"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -24,6 +29,124 @@ type XCCOptions struct {
 
 	// Enable sharp heuristic preference
 	EnableSharpPreference bool
+
+	// When not nil, the nonnegative cost of each option. Only the CheapestK
+	// solutions of least total cost are returned, in increasing order of cost,
+	// after the search completes; branches which cannot lead to a solution
+	// cheaper than the CheapestK-th found so far are pruned
+	Costs []int
+
+	// Number of cheapest solutions to return when Costs is set; 0 means 1
+	CheapestK int
+
+	// When not nil, called with a checkpoint of the search state every
+	// CheckpointDelta nodes
+	Checkpoint func(*XCCCheckpoint)
+
+	// Number of nodes between calls to Checkpoint
+	CheckpointDelta int
+
+	// When not nil, resume the search from this checkpoint, which must have
+	// been created for identical input
+	Resume *XCCCheckpoint
+
+	// When not nil, called with the number (1..M) of the largest option in
+	// each solution, just before the solution is visited; used by XCCParallel
+	// to merge minimax solutions
+	visitMax func(k int)
+
+	// When true, visit each solution as nil instead of building its options;
+	// used by XCCCount
+	countOnly bool
+
+	// When not nil, follow a single random path of the search tree instead
+	// of the full search. Called with the number d > 0 of options of the
+	// chosen item, returns the index 0 <= r < d of the option to try; used
+	// by XCCEstimate
+	probe func(d int) int
+
+	// When not nil, called with the total cost of each solution, just before
+	// the solution is visited; used by XCCCheapest
+	visitCost func(cost int)
+}
+
+// XCCCheckpoint is a serializable snapshot of the XCC search state, taken on
+// entry to a level (step C2), from which the search can be resumed. Solutions
+// visited after the checkpoint was taken will be visited again on resume.
+type XCCCheckpoint struct {
+	Hash      string // fingerprint of the input and processing options
+	Level     int    // current level l
+	State     []int  // chosen option x_k at each level 0 <= k < l
+	Cutoff    int    // spacer at one end of the best minimax solution
+	Nodes     int    // count of nodes processed, from ExactCoverStats
+	Solutions int    // count of solutions returned, from ExactCoverStats
+}
+
+// xccHash returns a fingerprint of the XCC input and the options which
+// affect the order of the search
+func xccHash(items []string, options [][]string, secondary []string,
+	xccOptions *XCCOptions) string {
+
+	h := sha256.New()
+	for _, item := range items {
+		fmt.Fprintf(h, "%s\n", item)
+	}
+	h.Write([]byte{0})
+	for _, sitem := range secondary {
+		fmt.Fprintf(h, "%s\n", sitem)
+	}
+	h.Write([]byte{0})
+	for _, option := range options {
+		fmt.Fprintf(h, "%s\n", strings.Join(option, " "))
+	}
+	h.Write([]byte{0})
+	fmt.Fprintf(h, "%t %t %t %t", xccOptions.Minimax, xccOptions.MinimaxSingle,
+		xccOptions.Exercise83, xccOptions.EnableSharpPreference)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// xccValidate checks the items, options and secondary items of an XCC
+// problem
+func xccValidate(items []string, options [][]string, secondary []string) error {
+	// Items
+	if len(items) == 0 {
+		return fmt.Errorf("items may not be empty")
+	}
+	mItems := make(map[string]bool)
+	for _, item := range items {
+		if mItems[item] {
+			return fmt.Errorf("item '%s' is not unique", item)
+		}
+		mItems[item] = true
+	}
+
+	// Secondary Items
+	mSItems := make(map[string]bool)
+	for _, sitem := range secondary {
+		if mItems[sitem] || mSItems[sitem] {
+			return fmt.Errorf("secondary item '%s' is not unique", sitem)
+		}
+		mSItems[sitem] = true
+	}
+
+	// Options
+	if len(options) == 0 {
+		return fmt.Errorf("options may not be empty")
+	}
+	for _, option := range options {
+		for _, item := range option {
+			i := strings.Index(item, ":")
+			if i > -1 {
+				item = item[:i]
+			}
+			if !mItems[item] && !mSItems[item] {
+				return fmt.Errorf("option '%v' contains '%s' which is not an item or secondary item", option, item)
+			}
+		}
+	}
+
+	return nil
 }
 
 // XCC implements Algorithm C (7.2.2.1), exact covering with colors via
@@ -58,6 +181,71 @@ type XCCOptions struct {
 func XCC(items []string, options [][]string, secondary []string,
 	stats *ExactCoverStats, xccOptions *XCCOptions) iter.Seq2[[][]string, error] {
 
+	return XCCContext(context.Background(), items, options, secondary, stats, xccOptions)
+}
+
+// XCCCount is like XCCContext, but only counts the solutions instead of
+// building and returning each one. It returns the number of solutions
+// visited; stats, if not nil, has the same values as for XCC.
+func XCCCount(ctx context.Context, items []string, options [][]string,
+	secondary []string, stats *ExactCoverStats,
+	xccOptions *XCCOptions) (int, error) {
+
+	countOptions := XCCOptions{}
+	if xccOptions != nil {
+		countOptions = *xccOptions
+	}
+	countOptions.countOnly = true
+
+	count := 0
+	for _, err := range XCCContext(ctx, items, options, secondary, stats, &countOptions) {
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// XCCCostSolution is a solution with its total cost, returned by XCCCheapest
+type XCCCostSolution struct {
+	Cost    int        // total cost of the options
+	Options [][]string // the options of the solution
+}
+
+// XCCCheapest is like XCCContext with xccOptions.Costs set, but returns the
+// cheapest solutions together with their total costs
+func XCCCheapest(ctx context.Context, items []string, options [][]string,
+	secondary []string, stats *ExactCoverStats,
+	xccOptions *XCCOptions) ([]XCCCostSolution, error) {
+
+	if xccOptions == nil || xccOptions.Costs == nil {
+		return nil, fmt.Errorf("costs are required")
+	}
+
+	costOptions := *xccOptions
+	var cost int
+	costOptions.visitCost = func(c int) { cost = c }
+
+	var solutions []XCCCostSolution
+	for solution, err := range XCCContext(ctx, items, options, secondary, stats, &costOptions) {
+		if err != nil {
+			return nil, err
+		}
+		solutions = append(solutions, XCCCostSolution{Cost: cost, Options: solution})
+	}
+
+	return solutions, nil
+}
+
+// XCCContext is like XCC, but checks ctx periodically during the search. If
+// ctx is canceled or its deadline is exceeded, the error is returned as the
+// final value of the sequence.
+func XCCContext(ctx context.Context, items []string, options [][]string,
+	secondary []string, stats *ExactCoverStats,
+	xccOptions *XCCOptions) iter.Seq2[[][]string, error] {
+
 	return func(yield func([][]string, error) bool) {
 
 		if xccOptions == nil {
@@ -85,6 +273,14 @@ func XCC(items []string, options [][]string, secondary []string,
 			cutoff   int   // pointer to the spacer at one end of the best minimax solution found so far
 			debug    bool  // is debug enabled?
 			progress bool  // is progress enabled?
+			ticks    int   // nodes visited since the context was checked
+			cticks   int   // nodes visited since the last checkpoint
+			hash     string
+			cost     []int             // cost of the option of each node, when xccOptions.Costs is set
+			share    []float64         // cost of the option of each node divided by its number of primary items
+			pathCost []int             // total cost of the options chosen before each level
+			cheapest []XCCCostSolution // cheapest solutions found so far, in increasing order of cost
+			kCheap   int               // number of cheapest solutions to keep
 		)
 
 		dump := func() {
@@ -262,44 +458,73 @@ func XCC(items []string, options [][]string, secondary []string,
 		}
 
 		validate := func() error {
-			// Items
-			if len(items) == 0 {
-				return fmt.Errorf("items may not be empty")
-			}
-			mItems := make(map[string]bool)
-			for _, item := range items {
-				if mItems[item] {
-					return fmt.Errorf("item '%s' is not unique", item)
-				}
-				mItems[item] = true
+			if err := xccValidate(items, options, secondary); err != nil {
+				return err
 			}
 
-			// Secondary Items
-			mSItems := make(map[string]bool)
-			for _, sitem := range secondary {
-				if mItems[sitem] || mSItems[sitem] {
-					return fmt.Errorf("secondary item '%s' is not unique", sitem)
+			// Costs
+			if xccOptions.Costs != nil {
+				if len(xccOptions.Costs) != len(options) {
+					return fmt.Errorf("costs has %d values for %d options", len(xccOptions.Costs), len(options))
+				}
+				for k, c := range xccOptions.Costs {
+					if c < 0 {
+						return fmt.Errorf("cost %d of option %d is negative", c, k+1)
+					}
+				}
+				if xccOptions.CheapestK < 0 {
+					return fmt.Errorf("cheapest solutions must be >= 0; got %d", xccOptions.CheapestK)
+				}
+				if xccOptions.Minimax || xccOptions.Exercise83 ||
+					xccOptions.Checkpoint != nil || xccOptions.Resume != nil {
+					return fmt.Errorf("costs are not supported with minimax, Exercise 83, checkpoint or resume")
 				}
-				mSItems[sitem] = true
 			}
 
-			// Options
-			if len(options) == 0 {
-				return fmt.Errorf("options may not be empty")
-			}
-			for _, option := range options {
-				for _, item := range option {
-					i := strings.Index(item, ":")
-					if i > -1 {
-						item = item[:i]
+			return nil
+		}
+
+		// initializeCosts fills out the cost tables and sorts the list of each
+		// item by increasing cost, so the options of an item can be abandoned
+		// as soon as one is too costly
+		initializeCosts := func() {
+			cost = make([]int, size)
+			share = make([]float64, size)
+			x := n + 2
+			for k, option := range options {
+				primary := 0
+				for q := x; top[q] > 0; q++ {
+					if top[q] <= n1 {
+						primary++
 					}
-					if !mItems[item] && !mSItems[item] {
-						return fmt.Errorf("option '%v' contains '%s' which is not an item or secondary item", option, item)
+				}
+				for q := x; top[q] > 0; q++ {
+					cost[q] = xccOptions.Costs[k]
+					if primary > 0 {
+						share[q] = float64(cost[q]) / float64(primary)
 					}
 				}
+				x += len(option) + 1
 			}
 
-			return nil
+			for i := 1; i <= n; i++ {
+				nodes := make([]int, 0, llen[i])
+				for q := dlink[i]; q != i; q = dlink[q] {
+					nodes = append(nodes, q)
+				}
+				sort.SliceStable(nodes, func(a, b int) bool {
+					return cost[nodes[a]] < cost[nodes[b]]
+				})
+				prev := i
+				for _, q := range nodes {
+					dlink[prev], ulink[q] = q, prev
+					prev = q
+				}
+				dlink[prev], ulink[i] = i, prev
+			}
+
+			pathCost = make([]int, m+1)
+			kCheap = max(xccOptions.CheapestK, 1)
 		}
 
 		initialize := func() {
@@ -311,10 +536,11 @@ func XCC(items []string, options [][]string, secondary []string,
 			if stats != nil {
 				stats.Theta = stats.Delta
 				stats.MaxLevel = -1
+				// A solution may have one option per item, at level n
 				if stats.Levels == nil {
-					stats.Levels = make([]int, n)
+					stats.Levels = make([]int, n+1)
 				} else {
-					for len(stats.Levels) < n {
+					for len(stats.Levels) < n+1 {
 						stats.Levels = append(stats.Levels, 0)
 					}
 				}
@@ -431,6 +657,10 @@ func XCC(items []string, options [][]string, secondary []string,
 				spacerX = x
 			}
 
+			if xccOptions.Costs != nil {
+				initializeCosts()
+			}
+
 			level = 0
 			state = make([]int, m)
 			cutoff = size
@@ -440,6 +670,27 @@ func XCC(items []string, options [][]string, secondary []string,
 			}
 		}
 
+		// lowerBound returns a lower bound on the cost of covering the active
+		// primary items. Each option contributes its share of the cost to each
+		// of its primary items, so the cheapest share in the list of each item
+		// bounds the cost of covering that item.
+		lowerBound := func() int {
+			bound := 0.0
+			for p := rlink[0]; p != 0; p = rlink[p] {
+				if dlink[p] == p {
+					return math.MaxInt
+				}
+				least := share[dlink[p]]
+				for q := dlink[dlink[p]]; q != p; q = dlink[q] {
+					least = min(least, share[q])
+				}
+				bound += least
+			}
+
+			// Total costs are integers; allow for rounding in the shares
+			return int(math.Ceil(bound - 1e-9))
+		}
+
 		// sitemColors returns a map of secondary items to their currently selected
 		// color
 		sitemColors := func() *map[string]string {
@@ -785,40 +1036,69 @@ func XCC(items []string, options [][]string, secondary []string,
 			pMax := 0 // Track max p for minimax
 			kMax := 0 // level for max p
 
-			// Only one of the secondary items will have it's color value, the
-			// others will have -1. Save the color and add it to all the matching
-			// secondary items at the end.
-			sitemColor := sitemColors()
-
-			// Iterate over the options
-			options := make([][]string, 0)
+			// Find the largest option, for minimax
 			for i, p := range state[0:level] {
 				if p > pMax {
 					pMax = p
 					kMax = i
 				}
-				options = append(options, make([]string, 0))
+			}
 
-				// Move back to first item in the option
-				for top[p-1] > 0 {
-					p--
+			var options [][]string
+			if !xccOptions.countOnly {
+				// Only one of the secondary items will have it's color value, the
+				// others will have -1. Save the color and add it to all the matching
+				// secondary items at the end.
+				sitemColor := sitemColors()
+
+				// Iterate over the options
+				options = make([][]string, 0)
+				for i, p := range state[0:level] {
+					options = append(options, make([]string, 0))
+
+					// Move back to first item in the option
+					for top[p-1] > 0 {
+						p--
+					}
+
+					// Iterate over items in the option
+					q := p
+					for top[q] > 0 {
+						name := name[top[q]]
+						if color, ok := (*sitemColor)[name]; ok {
+							options[i] = append(options[i], name+":"+color)
+						} else {
+							options[i] = append(options[i], name)
+						}
+						q++
+					}
 				}
 
-				// Iterate over items in the option
-				q := p
-				for top[q] > 0 {
-					name := name[top[q]]
-					if color, ok := (*sitemColor)[name]; ok {
-						options[i] = append(options[i], name+":"+color)
-					} else {
-						options[i] = append(options[i], name)
+				if debug {
+					log.Printf("visit(%v)", options)
+				}
+			}
+
+			if cost != nil {
+				// Keep the cheapest solutions, visited when the search completes
+				c := pathCost[level]
+				k := sort.Search(len(cheapest), func(j int) bool { return cheapest[j].Cost > c })
+				if k < kCheap {
+					cheapest = slices.Insert(cheapest, k, XCCCostSolution{Cost: c, Options: options})
+					if len(cheapest) > kCheap {
+						cheapest = cheapest[:kCheap]
 					}
-					q++
 				}
+				return true
 			}
 
-			if debug {
-				log.Printf("visit(%v)", options)
+			if xccOptions.visitMax != nil {
+				// The spacer at the end of option k has top = -k
+				pp := pMax
+				for top[pp] > 0 {
+					pp++
+				}
+				xccOptions.visitMax(-top[pp])
 			}
 
 			if !yield(options, nil) {
@@ -896,6 +1176,120 @@ func XCC(items []string, options [][]string, secondary []string,
 			return true
 		}
 
+		// checkpoint reports the current search state
+		checkpoint := func() {
+			c := &XCCCheckpoint{
+				Hash:   hash,
+				Level:  level,
+				State:  make([]int, level),
+				Cutoff: cutoff,
+			}
+			copy(c.State, state[0:level])
+			if stats != nil {
+				c.Nodes = stats.Nodes
+				c.Solutions = stats.Solutions
+			}
+			xccOptions.Checkpoint(c)
+		}
+
+		// resume restores the search state from a checkpoint, by replaying
+		// the choices made at each level
+		resume := func(c *XCCCheckpoint) error {
+			if c.Hash != hash {
+				return fmt.Errorf("checkpoint does not match the input")
+			}
+			if c.Level < 0 || c.Level > len(state) || len(c.State) != c.Level {
+				return fmt.Errorf("checkpoint has invalid level %d", c.Level)
+			}
+			if c.Cutoff != size && (c.Cutoff <= n || c.Cutoff >= size || top[c.Cutoff] > 0) {
+				return fmt.Errorf("checkpoint has invalid cutoff %d", c.Cutoff)
+			}
+
+			// Remove all nodes > cutoff from further consideration. Only the
+			// lists with such nodes change; those of the secondary items are
+			// trimmed too, before the options are replayed, so that purify
+			// doesn't mark, nor cover hide, options beyond the cutoff
+			cutoff = c.Cutoff
+			if xccOptions.Minimax {
+				for x := 1; x <= n; x++ {
+					q := ulink[x]
+					for q > cutoff {
+						u := ulink[q]
+						dlink[u], ulink[x] = x, u
+						llen[x]--
+						q = u
+					}
+				}
+			}
+
+			for level = 0; level < c.Level; level++ {
+				p := c.State[level]
+				if p <= n+1 || p >= size || top[p] <= 0 {
+					return fmt.Errorf("checkpoint has invalid option %d at level %d", p, level)
+				}
+
+				// The option must be in the list of an active item
+				i := top[p]
+				if i > n1 {
+					return fmt.Errorf("checkpoint has invalid option %d at level %d", p, level)
+				}
+				q := rlink[0]
+				for q != 0 && q != i {
+					q = rlink[q]
+				}
+				if q != i {
+					return fmt.Errorf("checkpoint has inactive item %s at level %d", name[i], level)
+				}
+				q = dlink[i]
+				for q != i && q != p {
+					q = dlink[q]
+				}
+				if q != p {
+					return fmt.Errorf("checkpoint has invalid option %d at level %d", p, level)
+				}
+
+				// C4. [Cover i.]
+				cover(i)
+
+				// Exercise 7.2.2.1-83, replay the permanent covers made when
+				// leaving each of the earlier options at level 0
+				if xccOptions.Exercise83 && level == 0 {
+					for x := dlink[i]; x != p; x = dlink[x] {
+						// Find the spacer at the right of this option
+						y := x
+						for ; top[y] > 0; y++ {
+						}
+
+						// j is the last item in the option
+						j := top[y-1]
+						if j > n1 && color[y-1] == 0 {
+							cover(j)
+						}
+					}
+				}
+
+				// C5. [Try x_l.]
+				state[level] = p
+				q = p + 1
+				for q != p {
+					j := top[q]
+					if j <= 0 {
+						q = ulink[q]
+					} else {
+						commit(q, j)
+						q++
+					}
+				}
+			}
+
+			if stats != nil {
+				stats.Nodes = c.Nodes
+				stats.Solutions = c.Solutions
+			}
+
+			return nil
+		}
+
 		// C1 [Initialize.]
 		if stats != nil && stats.Debug {
 			log.Printf("C1. Initialize")
@@ -903,9 +1297,25 @@ func XCC(items []string, options [][]string, secondary []string,
 
 		if err := validate(); err != nil {
 			yield(nil, err)
+			return
+		}
+		if err := ctx.Err(); err != nil {
+			yield(nil, err)
+			return
 		}
 		initialize()
 
+		if xccOptions.Checkpoint != nil || xccOptions.Resume != nil {
+			hash = xccHash(items, options, secondary, xccOptions)
+		}
+
+		if xccOptions.Resume != nil {
+			if err := resume(xccOptions.Resume); err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+
 		var (
 			i int
 			j int
@@ -922,6 +1332,22 @@ func XCC(items []string, options [][]string, secondary []string,
 			log.Printf("C2. Enter level %d, x[0:l]=%v\n", level, state[0:level])
 		}
 
+		ticks++
+		if ticks&(contextCheckInterval-1) == 0 {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+
+		if xccOptions.Checkpoint != nil {
+			cticks++
+			if cticks >= xccOptions.CheckpointDelta {
+				checkpoint()
+				cticks = 0
+			}
+		}
+
 		if stats != nil {
 			stats.Levels[level]++
 			stats.Nodes++
@@ -958,6 +1384,15 @@ func XCC(items []string, options [][]string, secondary []string,
 			goto C8
 		}
 
+		if cost != nil && len(cheapest) == kCheap &&
+			lowerBound() >= cheapest[kCheap-1].Cost-pathCost[level] {
+			// Branch and bound, no cheaper solution is possible
+			if debug {
+				log.Printf("C2. Prune, path cost %d", pathCost[level])
+			}
+			goto C8
+		}
+
 		// C3. [Choose i.]
 		if xccOptions.Exercise83 && level == 0 {
 			if debug && stats.Verbosity > 1 {
@@ -979,6 +1414,13 @@ func XCC(items []string, options [][]string, secondary []string,
 		cover(i)
 		state[level] = dlink[i]
 
+		if xccOptions.probe != nil && llen[i] > 0 {
+			// Monte Carlo probe, try only one random option of i
+			for r := xccOptions.probe(llen[i]); r > 0; r-- {
+				state[level] = dlink[state[level]]
+			}
+		}
+
 	C5:
 		// C5. [Try x_l.]
 		if debug {
@@ -987,6 +1429,14 @@ func XCC(items []string, options [][]string, secondary []string,
 		if state[level] == i {
 			goto C7
 		}
+		if cost != nil {
+			if len(cheapest) == kCheap &&
+				pathCost[level]+cost[state[level]] >= cheapest[kCheap-1].Cost {
+				// The remaining options of i are at least as costly
+				goto C7
+			}
+			pathCost[level+1] = pathCost[level] + cost[state[level]]
+		}
 		// Commit each of the items in this option
 		p = state[level] + 1
 		for p != state[level] {
@@ -1008,6 +1458,11 @@ func XCC(items []string, options [][]string, secondary []string,
 			log.Printf("C6. Try again, l=%d\n", level)
 		}
 
+		if xccOptions.probe != nil {
+			// The probe has reached a leaf
+			return
+		}
+
 		if stats != nil {
 			stats.Nodes++
 		}
@@ -1075,6 +1530,16 @@ func XCC(items []string, options [][]string, secondary []string,
 			if progress {
 				showProgress()
 			}
+
+			// Visit the cheapest solutions
+			for _, solution := range cheapest {
+				if xccOptions.visitCost != nil {
+					xccOptions.visitCost(solution.Cost)
+				}
+				if !yield(solution.Options, nil) {
+					return
+				}
+			}
 			return
 		}
 		level--
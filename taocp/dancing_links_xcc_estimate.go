@@ -0,0 +1,117 @@
+package taocp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// ExactCoverEstimate holds Monte Carlo estimates of the size of an exact
+// cover search tree. Each probe follows a random path from the root, choosing
+// uniformly among the d options of the item chosen at each level; the product
+// of the d's along the path is an unbiased estimate of the number of nodes at
+// each level (Knuth, Estimating the efficiency of backtrack programs, 1975).
+type ExactCoverEstimate struct {
+	Probes            int       // number of random probes
+	Nodes             float64   // mean estimate of the number of nodes
+	NodesVariance     float64   // sample variance of the node estimates
+	Solutions         float64   // mean estimate of the number of solutions
+	SolutionsVariance float64   // sample variance of the solution estimates
+	Levels            []float64 // mean estimate of the number of nodes at each level
+}
+
+// XCCEstimate estimates the size of the XCC search tree, counted as entries
+// to step C2 as in ExactCoverStats.Levels, using probes random paths seeded
+// by seed. Minimax, Exercise83, Checkpoint and Resume are not supported,
+// since the shape of the tree then depends on the order of the search; nor
+// are Costs, which sort the options and cut off the search by cost.
+func XCCEstimate(ctx context.Context, items []string, options [][]string,
+	secondary []string, xccOptions *XCCOptions, probes int,
+	seed int64) (*ExactCoverEstimate, error) {
+
+	probeOptions := XCCOptions{}
+	if xccOptions != nil {
+		probeOptions = *xccOptions
+	}
+
+	if probeOptions.Minimax || probeOptions.Exercise83 {
+		return nil, fmt.Errorf("estimate is not supported with minimax or Exercise 83")
+	}
+	if probeOptions.Checkpoint != nil || probeOptions.Resume != nil {
+		return nil, fmt.Errorf("estimate is not supported with checkpoint or resume")
+	}
+	if probeOptions.Costs != nil {
+		return nil, fmt.Errorf("estimate is not supported with costs")
+	}
+	if probes < 1 {
+		return nil, fmt.Errorf("probes must be >= 1; got %d", probes)
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+
+	var (
+		weight    float64   // product of the branching degrees on the path
+		levels    []float64 // node estimate at each level of this probe
+		sumN      float64   // sum of the node estimates
+		sumN2     float64   // sum of the squares of the node estimates
+		sumS      float64   // sum of the solution estimates
+		sumS2     float64   // sum of the squares of the solution estimates
+		sumLevels []float64 // sum of the node estimates at each level
+	)
+
+	probeOptions.countOnly = true
+	probeOptions.probe = func(d int) int {
+		weight *= float64(d)
+		levels = append(levels, weight)
+		return rng.Intn(d)
+	}
+
+	for k := 0; k < probes; k++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		weight = 1
+		levels = append(levels[:0], 1)
+		solutions := 0.0
+
+		for _, err := range XCCContext(ctx, items, options, secondary, nil, &probeOptions) {
+			if err != nil {
+				return nil, err
+			}
+			solutions = weight
+		}
+
+		nodes := 0.0
+		for l, w := range levels {
+			nodes += w
+			if l == len(sumLevels) {
+				sumLevels = append(sumLevels, 0)
+			}
+			sumLevels[l] += w
+		}
+
+		sumN += nodes
+		sumN2 += nodes * nodes
+		sumS += solutions
+		sumS2 += solutions * solutions
+	}
+
+	n := float64(probes)
+	estimate := &ExactCoverEstimate{
+		Probes:    probes,
+		Nodes:     sumN / n,
+		Solutions: sumS / n,
+		Levels:    make([]float64, len(sumLevels)),
+	}
+	if probes > 1 {
+		estimate.NodesVariance = math.Max(0, (sumN2-sumN*sumN/n)/(n-1))
+		estimate.SolutionsVariance = math.Max(0, (sumS2-sumS*sumS/n)/(n-1))
+	}
+	for l, sum := range sumLevels {
+		estimate.Levels[l] = sum / n
+	}
+
+	return estimate, nil
+}
@@ -0,0 +1,71 @@
+package taocp
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestXCCEstimate(t *testing.T) {
+
+	// Every path has the same shape, so every probe is exact
+	estimate, err := XCCEstimate(context.Background(), []string{"a", "b"},
+		[][]string{{"a"}, {"a"}, {"b"}, {"b"}, {"b"}}, []string{}, nil, 10, 0)
+	if err != nil {
+		t.Fatalf("expected no error; got %v", err)
+	}
+	expected := &ExactCoverEstimate{
+		Probes:    10,
+		Nodes:     9,
+		Solutions: 6,
+		Levels:    []float64{1, 2, 6},
+	}
+	if !reflect.DeepEqual(estimate, expected) {
+		t.Errorf("expected estimate %v; got %v", expected, estimate)
+	}
+
+	// The mean of many probes is close to the actual size of the tree
+	items, options, secondary := nQueensXC(8)
+	stats := &ExactCoverStats{}
+	count, err := XCCCount(context.Background(), items, options, secondary, stats, nil)
+	if err != nil {
+		t.Fatalf("expected no error; got %v", err)
+	}
+	nodes := 0
+	for _, n := range stats.Levels {
+		nodes += n
+	}
+
+	probes := 20000
+	estimate, err = XCCEstimate(context.Background(), items, options, secondary, nil, probes, 1)
+	if err != nil {
+		t.Fatalf("expected no error; got %v", err)
+	}
+
+	// Allow 4 standard errors
+	if math.Abs(estimate.Nodes-float64(nodes)) > 4*math.Sqrt(estimate.NodesVariance/float64(probes)) {
+		t.Errorf("expected nodes estimate near %d; got %f (variance %f)", nodes, estimate.Nodes, estimate.NodesVariance)
+	}
+	if math.Abs(estimate.Solutions-float64(count)) > 4*math.Sqrt(estimate.SolutionsVariance/float64(probes)) {
+		t.Errorf("expected solutions estimate near %d; got %f (variance %f)", count, estimate.Solutions, estimate.SolutionsVariance)
+	}
+
+	// Unsupported options
+	cases := []struct {
+		xccOptions *XCCOptions
+		probes     int
+		err        string
+	}{
+		{&XCCOptions{Minimax: true}, 1, "estimate is not supported with minimax or Exercise 83"},
+		{&XCCOptions{Resume: &XCCCheckpoint{}}, 1, "estimate is not supported with checkpoint or resume"},
+		{&XCCOptions{Costs: make([]int, len(options))}, 1, "estimate is not supported with costs"},
+		{nil, 0, "probes must be >= 1; got 0"},
+	}
+	for i, c := range cases {
+		_, err := XCCEstimate(context.Background(), items, options, secondary, c.xccOptions, c.probes, 0)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("For case #%d, expected error %q; got %v", i, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package taocp
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// xccParallelResult is a message from an XCCParallel worker to the driver
+type xccParallelResult struct {
+	solution [][]string       // solution found by the worker
+	kMax     int              // number of the largest option in solution
+	err      error            // error returned by the worker
+	stats    *ExactCoverStats // statistics of a finished subproblem
+}
+
+// XCCParallel runs XCC using threads worker goroutines. The search is
+// partitioned on the options of the first item chosen at level 0: each
+// option becomes an independent subproblem in which the item has only that
+// option. Solutions are returned as they are found, so their order is not
+// deterministic.
+//
+// When xccOptions.Minimax is set, each subproblem is solved with minimax and
+// only the solutions which would have been visited by a sequential minimax
+// search, given the solutions already returned, are passed through; the last
+// solution(s) returned are the minimax solution(s). Checkpoint, Resume and
+// Costs are not supported.
+//
+// At completion stats holds the sum of the Nodes and Levels of the
+// subproblems, counting the node at level 0 which they share only once, the
+// maximum MaxLevel, and the count of Solutions returned. These match the
+// statistics of XCC, apart from minimax, which prunes each subproblem
+// separately.
+func XCCParallel(ctx context.Context, items []string, options [][]string,
+	secondary []string, stats *ExactCoverStats, xccOptions *XCCOptions,
+	threads int) iter.Seq2[[][]string, error] {
+
+	return func(yield func([][]string, error) bool) {
+
+		if xccOptions == nil {
+			// Use all default values
+			xccOptions = &XCCOptions{}
+		}
+
+		if threads < 1 {
+			yield(nil, fmt.Errorf("threads must be >= 1; got %d", threads))
+			return
+		}
+
+		if xccOptions.Checkpoint != nil || xccOptions.Resume != nil {
+			yield(nil, fmt.Errorf("checkpoint and resume are not supported by XCCParallel"))
+			return
+		}
+
+		if xccOptions.Costs != nil {
+			yield(nil, fmt.Errorf("costs are not supported by XCCParallel"))
+			return
+		}
+
+		// Choose the first item to branch on, using the same heuristics as
+		// XCC at level 0
+		llen := make(map[string]int)
+		for _, item := range items {
+			llen[item] = 0
+		}
+		for _, option := range options {
+			for _, item := range option {
+				if _, ok := llen[item]; ok {
+					llen[item]++
+				}
+			}
+		}
+
+		first := -1
+		if xccOptions.Exercise83 {
+			first = 0
+		} else {
+			theta := -1
+			for i, item := range items {
+				lambda := llen[item]
+				if xccOptions.EnableSharpPreference && lambda > 1 && !strings.HasPrefix(item, "#") {
+					lambda += len(options)
+				}
+				if lambda < theta || theta == -1 {
+					theta = lambda
+					first = i
+					if theta == 0 {
+						break
+					}
+				}
+			}
+		}
+
+		// Each option of the first item is one subproblem
+		var tasks []int
+		if first >= 0 {
+			for k, option := range options {
+				for _, item := range option {
+					if item == items[first] {
+						tasks = append(tasks, k)
+						break
+					}
+				}
+			}
+		}
+
+		if len(tasks) == 0 {
+			// Nothing to partition, so let XCC validate and solve
+			for solution, err := range XCCContext(ctx, items, options, secondary, stats, xccOptions) {
+				if !yield(solution, err) {
+					return
+				}
+			}
+			return
+		}
+
+		// subproblem returns the options and the original option numbers for
+		// the subproblem of task t
+		subproblem := func(t int) ([][]string, []int) {
+			// Exercise 7.2.2.1-83: uncolored secondary items permanently
+			// covered when leaving the earlier options at level 0
+			removed := make(map[string]bool)
+			excluded := func(option []string) bool {
+				for _, item := range option {
+					if removed[strings.SplitN(item, ":", 2)[0]] {
+						return true
+					}
+				}
+				return false
+			}
+			if xccOptions.Exercise83 {
+				for _, k := range tasks[:t] {
+					if excluded(options[k]) {
+						// Hidden before it could be tried
+						continue
+					}
+					last := options[k][len(options[k])-1]
+					if _, primary := llen[last]; !primary && !strings.Contains(last, ":") {
+						removed[last] = true
+					}
+				}
+				if excluded(options[tasks[t]]) {
+					return nil, nil
+				}
+			}
+
+			var (
+				subOptions [][]string
+				subNumbers []int
+			)
+			for k, option := range options {
+				if k != tasks[t] && (slices.Contains(option, items[first]) || excluded(option)) {
+					continue
+				}
+				subOptions = append(subOptions, option)
+				subNumbers = append(subNumbers, k+1)
+			}
+
+			return subOptions, subNumbers
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		results := make(chan xccParallelResult)
+		queue := make(chan int)
+
+		// Workers solve subproblems from the queue
+		worker := func() {
+			defer wg.Done()
+			for t := range queue {
+				subOptions, subNumbers := subproblem(t)
+				if subOptions == nil {
+					continue
+				}
+
+				var subStats *ExactCoverStats
+				if stats != nil {
+					subStats = &ExactCoverStats{
+						Progress:     stats.Progress,
+						Debug:        stats.Debug,
+						Verbosity:    stats.Verbosity,
+						Delta:        stats.Delta,
+						SuppressDump: stats.SuppressDump,
+					}
+				}
+
+				kMax := 0
+				subXccOptions := &XCCOptions{
+					Minimax:               xccOptions.Minimax,
+					MinimaxSingle:         xccOptions.MinimaxSingle,
+					EnableSharpPreference: xccOptions.EnableSharpPreference,
+					visitMax:              func(k int) { kMax = subNumbers[k-1] },
+				}
+
+				for solution, err := range XCCContext(ctx, items, subOptions, secondary, subStats, subXccOptions) {
+					if err != nil && ctx.Err() != nil {
+						// Canceled by the driver
+						return
+					}
+					select {
+					case results <- xccParallelResult{solution: solution, kMax: kMax, err: err}:
+					case <-ctx.Done():
+						return
+					}
+					if err != nil {
+						return
+					}
+				}
+
+				if subStats != nil {
+					select {
+					case results <- xccParallelResult{stats: subStats}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+
+		wg.Add(threads)
+		for w := 0; w < threads; w++ {
+			go worker()
+		}
+
+		// Feed the queue, then close the results when all workers are done
+		go func() {
+			defer close(queue)
+			for t := range tasks {
+				select {
+				case queue <- t:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		// halt stops the workers and waits for them to finish
+		halt := func() {
+			cancel()
+			for range results {
+			}
+		}
+
+		if stats != nil {
+			stats.MaxLevel = -1
+			for len(stats.Levels) < len(items)+len(secondary) {
+				stats.Levels = append(stats.Levels, 0)
+			}
+		}
+
+		best := len(options) + 1 // largest option number for minimax
+		merged := 0              // number of subproblem statistics merged
+		for result := range results {
+			if result.stats != nil {
+				// Merge the statistics of a finished subproblem. Its node
+				// at level 0 is the root of the search, already counted if
+				// another subproblem has been merged.
+				if merged > 0 && len(result.stats.Levels) > 0 && result.stats.Levels[0] > 0 {
+					result.stats.Nodes--
+					result.stats.Levels[0]--
+				}
+				merged++
+				stats.Nodes += result.stats.Nodes
+				if result.stats.MaxLevel > stats.MaxLevel {
+					stats.MaxLevel = result.stats.MaxLevel
+				}
+				for l, count := range result.stats.Levels {
+					if l == len(stats.Levels) {
+						stats.Levels = append(stats.Levels, 0)
+					}
+					stats.Levels[l] += count
+				}
+				continue
+			}
+
+			if result.err != nil {
+				halt()
+				yield(nil, result.err)
+				return
+			}
+
+			if xccOptions.Minimax {
+				if result.kMax > best || (xccOptions.MinimaxSingle && result.kMax == best) {
+					continue
+				}
+				best = result.kMax
+			}
+
+			if stats != nil {
+				stats.Solutions++
+			}
+			if !yield(result.solution, nil) {
+				halt()
+				return
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
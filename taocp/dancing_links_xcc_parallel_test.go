@@ -0,0 +1,159 @@
+package taocp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// xccMaxOption returns the number (1..M) of the largest option in solution
+func xccMaxOption(options [][]string, solution [][]string) int {
+	kMax := 0
+	for _, option := range solution {
+		for k := range options {
+			if strings.Join(options[k], " ") == strings.Join(option, " ") && k+1 > kMax {
+				kMax = k + 1
+			}
+		}
+	}
+	return kMax
+}
+
+// testXCCParallel verifies that XCCParallel returns the same solutions as XCC.
+// For minimax, only the final minimax solutions are compared and, for
+// MinimaxSingle, only their maximum option since the solution returned
+// depends on the order of the search.
+func testXCCParallel(t *testing.T, items []string, options [][]string,
+	secondary []string, xccOptions XCCOptions) {
+
+	var expected [][][]string
+	expectedStats := &ExactCoverStats{}
+	for solution, err := range XCC(items, options, secondary, expectedStats, &xccOptions) {
+		if err != nil {
+			t.Fatalf("XCC returned error %v", err)
+		}
+		expected = append(expected, solution)
+	}
+
+	for _, threads := range []int{1, 2, 4} {
+		var got [][][]string
+		stats := &ExactCoverStats{}
+		for solution, err := range XCCParallel(context.Background(), items, options, secondary, stats, &xccOptions, threads) {
+			if err != nil {
+				t.Fatalf("XCCParallel returned error %v", err)
+			}
+			got = append(got, solution)
+		}
+
+		if stats.Solutions != len(got) {
+			t.Errorf("Expected stats.Solutions=%d; got %d", len(got), stats.Solutions)
+		}
+
+		// Minimax prunes each subproblem separately, so its statistics differ
+		if !xccOptions.Minimax {
+			if stats.Nodes != expectedStats.Nodes {
+				t.Errorf("For threads=%d, expected stats.Nodes=%d; got %d", threads, expectedStats.Nodes, stats.Nodes)
+			}
+			if !reflect.DeepEqual(stats.Levels, expectedStats.Levels) {
+				t.Errorf("For threads=%d, expected stats.Levels=%v; got %v", threads, expectedStats.Levels, stats.Levels)
+			}
+		}
+
+		if xccOptions.MinimaxSingle && len(got) > 0 {
+			kMax := xccMaxOption(options, expected[len(expected)-1])
+			if k := xccMaxOption(options, got[len(got)-1]); k != kMax {
+				t.Errorf("For threads=%d, expected minimax option %d; got %d", threads, kMax, k)
+			}
+			continue
+		}
+
+		want := expected
+		if xccOptions.Minimax && len(got) > 0 {
+			// Keep only the solutions with the final maximum option
+			final := func(solutions [][][]string) [][][]string {
+				kMax := xccMaxOption(options, solutions[len(solutions)-1])
+				var result [][][]string
+				for _, solution := range solutions {
+					if xccMaxOption(options, solution) == kMax {
+						result = append(result, solution)
+					}
+				}
+				return result
+			}
+			want, got = final(expected), final(got)
+		}
+
+		sortSolutions(want)
+		sortSolutions(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("For threads=%d, expected solutions %v; got %v", threads, want, got)
+		}
+	}
+}
+
+func TestXCCParallel(t *testing.T) {
+
+	items, options, secondary := nQueensXC(8)
+	testXCCParallel(t, items, options, secondary, XCCOptions{})
+	testXCCParallel(t, items, options, secondary, XCCOptions{EnableSharpPreference: true})
+	testXCCParallel(t, xcItems, xcOptions, []string{}, XCCOptions{})
+	testXCCParallel(t, xccItems, xccOptions, xccSItems, XCCOptions{})
+	testXCCParallel(t, []string{"a", "b", "c"},
+		[][]string{{"a", "s"}, {"a", "b", "t"}, {"b", "s"}, {"b", "c"}, {"c", "t"}, {"c"}, {"a", "c"}, {"b"}},
+		[]string{"s", "t"}, XCCOptions{Exercise83: true})
+
+	// Minimax
+	testXCCParallel(t, items, options, secondary, XCCOptions{Minimax: true})
+	testXCCParallel(t, items, options, secondary, XCCOptions{Minimax: true, MinimaxSingle: true})
+	testXCCParallel(t, xccMinimaxItems, xccMinimaxOptions, xccMinimaxSItems, XCCOptions{Minimax: true})
+	testXCCParallel(t, xccMinimaxItems, xccMinimaxOptions, xccMinimaxSItems, XCCOptions{Minimax: true, MinimaxSingle: true})
+
+	// No solutions
+	testXCCParallel(t, []string{"a", "b"}, [][]string{{"a"}, {"a", "b"}}, []string{}, XCCOptions{})
+
+	// Invalid arguments
+	for _, err := range XCCParallel(context.Background(), xcItems, xcOptions, []string{}, nil, nil, 0) {
+		if err == nil || err.Error() != "threads must be >= 1; got 0" {
+			t.Errorf("Expected error for threads=0; got %v", err)
+		}
+	}
+	for _, err := range XCCParallel(context.Background(), xcItems, xcOptions, []string{}, nil,
+		&XCCOptions{Resume: &XCCCheckpoint{}}, 2) {
+		if err == nil || err.Error() != "checkpoint and resume are not supported by XCCParallel" {
+			t.Errorf("Expected error for resume; got %v", err)
+		}
+	}
+}
+
+func TestXCCParallelContext(t *testing.T) {
+
+	items, options, secondary := nQueensXC(10)
+
+	// Already canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for solution, err := range XCCParallel(ctx, items, options, secondary, nil, nil, 4) {
+		if !errors.Is(err, context.Canceled) || solution != nil {
+			t.Errorf("Expected only error %v; got solution=%v, err=%v", context.Canceled, solution, err)
+		}
+	}
+
+	// Canceled during the search
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	testContextCancel(t, cancel, XCCParallel(ctx, items, options, secondary, nil, nil, 4), 724)
+
+	// Stopped by the consumer
+	count := 0
+	for _, err := range XCCParallel(context.Background(), items, options, secondary, nil, nil, 4) {
+		if err != nil {
+			t.Fatalf("XCCParallel returned error %v", err)
+		}
+		count++
+		if count == 10 {
+			break
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package taocp
 
 import (
+	"context"
+	"errors"
 	"log"
+	"math/rand"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -24,6 +29,26 @@ var (
 		{"q", "x:A"},
 		{"p", "r", "x:A", "y"},
 	}
+
+	// Minimax example with colors
+	xccMinimaxItems = []string{"a", "b", "c", "d"}
+
+	xccMinimaxSItems = []string{"x", "y", "z"}
+
+	xccMinimaxOptions = [][]string{
+		{"a", "b", "x"},
+		{"a", "b", "y:1"},
+		{"b", "c", "y"},
+		{"b", "c", "x"},
+		{"a"},
+		{"b"},
+		{"c", "y:2"},
+		{"c", "y:3"},
+		{"c", "d", "z"},
+		{"d", "y:3"},
+		{"c", "d", "y"},
+		{"c", "d", "x"},
+	}
 )
 
 func TestXCC(t *testing.T) {
@@ -79,6 +104,264 @@ func TestXCC(t *testing.T) {
 	}
 }
 
+func TestXCCContext(t *testing.T) {
+
+	items, options, secondary := nQueensXC(10)
+
+	// Already canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for solution, err := range XCCContext(ctx, items, options, secondary, nil, nil) {
+		if !errors.Is(err, context.Canceled) || solution != nil {
+			t.Errorf("Expected only error %v; got solution=%v, err=%v", context.Canceled, solution, err)
+		}
+	}
+
+	// Canceled during the search
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	testContextCancel(t, cancel, XCCContext(ctx, items, options, secondary, nil, nil), 724)
+}
+
+func TestXCCCount(t *testing.T) {
+
+	items, options, secondary := nQueensXC(8)
+
+	cases := []struct {
+		items      []string
+		options    [][]string
+		secondary  []string
+		xccOptions *XCCOptions
+	}{
+		{items, options, secondary, nil},
+		{items, options, secondary, &XCCOptions{EnableSharpPreference: true}},
+		{xccItems, xccOptions, xccSItems, nil},
+		{xccMinimaxItems, xccMinimaxOptions, xccMinimaxSItems, &XCCOptions{Minimax: true}},
+		{xccMinimaxItems, xccMinimaxOptions, xccMinimaxSItems, &XCCOptions{Minimax: true, MinimaxSingle: true}},
+	}
+
+	for i, c := range cases {
+		expected := 0
+		expectedStats := &ExactCoverStats{}
+		for _, err := range XCC(c.items, c.options, c.secondary, expectedStats, c.xccOptions) {
+			if err != nil {
+				t.Fatalf("XCC returned error %v", err)
+			}
+			expected++
+		}
+
+		stats := &ExactCoverStats{}
+		count, err := XCCCount(context.Background(), c.items, c.options, c.secondary, stats, c.xccOptions)
+		if err != nil {
+			t.Errorf("For case #%d, expected no error; got %v", i, err)
+		}
+		if count != expected {
+			t.Errorf("For case #%d, expected %d solutions; got %d", i, expected, count)
+		}
+		if !reflect.DeepEqual(stats, expectedStats) {
+			t.Errorf("For case #%d, expected stats %v; got %v", i, expectedStats, stats)
+		}
+	}
+
+	// Canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := XCCCount(ctx, items, options, secondary, nil, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected error %v; got %v", context.Canceled, err)
+	}
+}
+
+// testXCCCheapest compares the cheapest solutions from XCCCheapest with the
+// costs of all the solutions found by XCC. Option names must be unique and
+// uncolored.
+func testXCCCheapest(t *testing.T, items []string, options [][]string,
+	secondary []string, costs []int, k int) {
+
+	optionCost := make(map[string]int)
+	for i, option := range options {
+		optionCost[strings.Join(option, " ")] = costs[i]
+	}
+	solutionCost := func(solution [][]string) int {
+		total := 0
+		for _, option := range solution {
+			total += optionCost[strings.Join(option, " ")]
+		}
+		return total
+	}
+
+	var expected []int
+	for solution, err := range XCC(items, options, secondary, nil, nil) {
+		if err != nil {
+			t.Fatalf("XCC returned error %v", err)
+		}
+		expected = append(expected, solutionCost(solution))
+	}
+	sort.Ints(expected)
+	if len(expected) > max(k, 1) {
+		expected = expected[:max(k, 1)]
+	}
+
+	stats := &ExactCoverStats{}
+	solutions, err := XCCCheapest(context.Background(), items, options, secondary, stats,
+		&XCCOptions{Costs: costs, CheapestK: k})
+	if err != nil {
+		t.Fatalf("XCCCheapest returned error %v", err)
+	}
+
+	got := make([]int, len(solutions))
+	for i, solution := range solutions {
+		got[i] = solution.Cost
+		if c := solutionCost(solution.Options); c != solution.Cost {
+			t.Errorf("Expected cost %d for solution %v; got %d", c, solution.Options, solution.Cost)
+		}
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected costs %v; got %v", expected, got)
+	}
+}
+
+func TestXCCCheapest(t *testing.T) {
+
+	// Costs chosen to make the cheapest solution unique
+	testXCCCheapest(t, xcItems, xcOptions, []string{}, []int{1, 2, 3, 4, 5, 6}, 1)
+	testXCCCheapest(t, []string{"a", "b", "c"},
+		[][]string{{"a"}, {"b"}, {"c"}, {"a", "b"}, {"b", "c"}, {"a", "b", "c"}},
+		[]string{}, []int{1, 2, 3, 2, 6, 7}, 3)
+
+	items, options, secondary := nQueensXC(8)
+	rng := rand.New(rand.NewSource(0))
+	costs := make([]int, len(options))
+	for i := range costs {
+		costs[i] = rng.Intn(100)
+	}
+	for _, k := range []int{0, 1, 5, 92, 100} {
+		testXCCCheapest(t, items, options, secondary, costs, k)
+	}
+
+	// Zero costs
+	testXCCCheapest(t, items, options, secondary, make([]int, len(options)), 10)
+
+	// Solutions are pruned
+	stats := &ExactCoverStats{}
+	if _, err := XCCCheapest(context.Background(), items, options, secondary, stats,
+		&XCCOptions{Costs: costs}); err != nil {
+		t.Fatalf("XCCCheapest returned error %v", err)
+	}
+	if stats.Solutions >= 92 {
+		t.Errorf("Expected fewer than 92 solutions visited; got %d", stats.Solutions)
+	}
+
+	// Invalid costs
+	cases := []struct {
+		xccOptions *XCCOptions
+		err        string
+	}{
+		{nil, "costs are required"},
+		{&XCCOptions{Costs: []int{1}}, "costs has 1 values for 6 options"},
+		{&XCCOptions{Costs: []int{1, 2, -3, 4, 5, 6}}, "cost -3 of option 3 is negative"},
+		{&XCCOptions{Costs: []int{1, 2, 3, 4, 5, 6}, CheapestK: -1}, "cheapest solutions must be >= 0; got -1"},
+		{&XCCOptions{Costs: []int{1, 2, 3, 4, 5, 6}, Minimax: true},
+			"costs are not supported with minimax, Exercise 83, checkpoint or resume"},
+	}
+	for i, c := range cases {
+		_, err := XCCCheapest(context.Background(), xcItems, xcOptions, []string{}, nil, c.xccOptions)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("For case #%d, expected error %q; got %v", i, c.err, err)
+		}
+	}
+}
+
+// testXCCResume runs XCC, taking a checkpoint at every node, then resumes
+// from each checkpoint and verifies that the remaining solutions match
+func testXCCResume(t *testing.T, items []string, options [][]string,
+	secondary []string, xccOptions XCCOptions) {
+
+	var checkpoints []*XCCCheckpoint
+	xccOptions.Checkpoint = func(c *XCCCheckpoint) {
+		checkpoints = append(checkpoints, c)
+	}
+	xccOptions.CheckpointDelta = 1
+
+	var expected [][][]string
+	stats := &ExactCoverStats{}
+	for solution, err := range XCC(items, options, secondary, stats, &xccOptions) {
+		if err != nil {
+			t.Fatalf("XCC returned error %v", err)
+		}
+		expected = append(expected, solution)
+	}
+
+	if len(checkpoints) == 0 {
+		t.Fatalf("Expected checkpoints; got none")
+	}
+
+	xccOptions.Checkpoint = nil
+	for k, c := range checkpoints {
+		xccOptions.Resume = c
+		got := expected[:c.Solutions:c.Solutions]
+		stats := &ExactCoverStats{}
+		for solution, err := range XCC(items, options, secondary, stats, &xccOptions) {
+			if err != nil {
+				t.Fatalf("For checkpoint #%d, XCC returned error %v", k, err)
+			}
+			got = append(got, solution)
+		}
+
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("For checkpoint #%d (level=%d, state=%v), expected solutions %v; got %v",
+				k, c.Level, c.State, expected, got)
+		}
+		if stats.Solutions != len(expected) {
+			t.Errorf("For checkpoint #%d, expected stats.Solutions=%d; got %d",
+				k, len(expected), stats.Solutions)
+		}
+	}
+}
+
+func TestXCCCheckpoint(t *testing.T) {
+
+	items, options, secondary := nQueensXC(6)
+	testXCCResume(t, items, options, secondary, XCCOptions{})
+	testXCCResume(t, items, options, secondary, XCCOptions{EnableSharpPreference: true})
+	testXCCResume(t, xccItems, xccOptions, xccSItems, XCCOptions{})
+	testXCCResume(t, []string{"a", "b", "c"},
+		[][]string{{"a", "s"}, {"a", "b", "t"}, {"b", "s"}, {"b", "c"}, {"c", "t"}, {"c"}, {"a", "c"}, {"b"}},
+		[]string{"s", "t"}, XCCOptions{Exercise83: true})
+
+	// Minimax
+	testXCCResume(t, xccMinimaxItems, xccMinimaxOptions, xccMinimaxSItems, XCCOptions{Minimax: true})
+	testXCCResume(t, xccMinimaxItems, xccMinimaxOptions, xccMinimaxSItems, XCCOptions{Minimax: true, MinimaxSingle: true})
+
+	// Minimax, with colored secondary items covered and purified by the
+	// options replayed
+	for _, c := range []struct {
+		items     []string
+		options   [][]string
+		secondary []string
+	}{
+		{[]string{"a", "b", "c", "d"}, [][]string{
+			{"b", "d"}, {"b", "d", "x"}, {"c", "x:2"}, {"c", "x:0"}, {"b", "d", "x:2"}, {"a"},
+			{"b", "x"}, {"c"}, {"b", "d"}, {"c"}, {"a", "b", "c", "x:2"}, {"c", "x:1"}},
+			[]string{"x"}},
+		{[]string{"a", "b", "c", "d", "e"}, [][]string{
+			{"a", "d", "e", "x:1", "z:2"}, {"a", "b", "c", "x:0", "y", "z:2"}, {"e", "x", "y:1"},
+			{"a", "b", "x:2", "z:2"}, {"a", "c", "x", "y:1"}, {"b", "y:2"}, {"c", "d", "e", "y:0", "z:0"},
+			{"c", "d", "e", "z"}, {"a"}, {"e", "x", "y:2"}, {"b", "c", "x:0", "y", "z:0"}, {"b", "z:0"}},
+			[]string{"x", "y", "z"}},
+	} {
+		testXCCResume(t, c.items, c.options, c.secondary, XCCOptions{Minimax: true})
+		testXCCResume(t, c.items, c.options, c.secondary, XCCOptions{Minimax: true, MinimaxSingle: true})
+	}
+
+	// Mismatched input
+	for _, err := range XCC(xcItems, xcOptions, []string{}, nil,
+		&XCCOptions{Resume: &XCCCheckpoint{Hash: "0"}}) {
+		if err == nil || err.Error() != "checkpoint does not match the input" {
+			t.Errorf("Expected error for mismatched checkpoint; got %v", err)
+		}
+	}
+}
+
 var (
 	cards1 = [9][3][3]int{
 		{{1, 0, 0}, {0, 2, 0}, {8, 0, 3}},
@@ -133,6 +416,55 @@ var (
 	}
 )
 
+func TestXCCInvalid(t *testing.T) {
+
+	cases := []struct {
+		items     []string   // primary items
+		options   [][]string // options
+		secondary []string   // secondary items
+	}{
+		{[]string{}, xccOptions, xccSItems},
+		{[]string{"p", "q", "p"}, xccOptions, xccSItems},
+		{xccItems, xccOptions, []string{"x", "p"}},
+		{xccItems, [][]string{}, xccSItems},
+		{xccItems, append(xccOptions, []string{"p", "z"}), xccSItems},
+	}
+
+	// The error is the only value, without continuing into the search
+	for i, c := range cases {
+		count := 0
+		for solution, err := range XCC(c.items, c.options, c.secondary, &ExactCoverStats{}, nil) {
+			count++
+			if err == nil {
+				t.Errorf("For case #%d, expected an error; got solution %v", i, solution)
+			}
+		}
+		if count != 1 {
+			t.Errorf("For case #%d, expected only the error; got %d values", i, count)
+		}
+	}
+}
+
+func TestXCCLevels(t *testing.T) {
+
+	// Each option has one item, so the solution is found at level n
+	stats := &ExactCoverStats{}
+	count := 0
+	for _, err := range XCC([]string{"a", "b"}, [][]string{{"a"}, {"b"}}, nil, stats, nil) {
+		if err != nil {
+			t.Fatalf("Expected no error; got %v", err)
+		}
+		count++
+	}
+
+	if count != 1 {
+		t.Errorf("Expected 1 solution; got %d", count)
+	}
+	if expected := []int{1, 1, 1}; !reflect.DeepEqual(stats.Levels, expected) {
+		t.Errorf("Expected stats.Levels %v; got %v", expected, stats.Levels)
+	}
+}
+
 func TestSudokuCards(t *testing.T) {
 
 	cases := []struct {
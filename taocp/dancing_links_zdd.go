@@ -0,0 +1,439 @@
+package taocp
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"iter"
+	"log"
+	"math/big"
+	"math/rand"
+	"slices"
+)
+
+// ZDDNode is a branch node of a ZDD. The family of sets represented by the
+// node is the family of Lo, plus the family of Hi with Option added to every
+// set.
+type ZDDNode struct {
+	Option int // index of the option in ZDD.Options
+	Lo     int // node reached when Option is not chosen
+	Hi     int // node reached when Option is chosen
+}
+
+// ZDD is a zero-suppressed binary decision diagram representing the family
+// of all solutions to an exact cover problem. Nodes 0 and 1 are the sinks,
+// ⊥ (no solutions) and ⊤ (the empty solution). Every other node has Lo and Hi
+// less than its own index.
+type ZDD struct {
+	Nodes   []ZDDNode  // nodes of the diagram, including the sinks
+	Root    int        // the node representing all solutions
+	Options [][]string // the options of the exact cover problem
+
+	counts []*big.Int // number of solutions of each node; computed lazily
+}
+
+// Count returns the number of solutions represented by the ZDD
+func (z *ZDD) Count() *big.Int {
+	z.count()
+	return new(big.Int).Set(z.counts[z.Root])
+}
+
+// count fills in the number of solutions of each node, if necessary
+func (z *ZDD) count() {
+	if len(z.counts) == len(z.Nodes) {
+		return
+	}
+
+	z.counts = make([]*big.Int, len(z.Nodes))
+	z.counts[0] = big.NewInt(0)
+	z.counts[1] = big.NewInt(1)
+	for k := 2; k < len(z.Nodes); k++ {
+		node := z.Nodes[k]
+		z.counts[k] = new(big.Int).Add(z.counts[node.Lo], z.counts[node.Hi])
+	}
+}
+
+// Sample returns a solution chosen uniformly at random using rng, or nil if
+// there are no solutions
+func (z *ZDD) Sample(rng *rand.Rand) [][]string {
+	z.count()
+	if z.counts[z.Root].Sign() == 0 {
+		return nil
+	}
+
+	solution := make([][]string, 0)
+	r := new(big.Int)
+	for k := z.Root; k > 1; {
+		node := z.Nodes[k]
+
+		// Choose Hi with probability count(Hi) / count(k)
+		r.Rand(rng, z.counts[k])
+		if r.Cmp(z.counts[node.Hi]) < 0 {
+			solution = append(solution, slices.Clone(z.Options[node.Option]))
+			k = node.Hi
+		} else {
+			k = node.Lo
+		}
+	}
+
+	return solution
+}
+
+// Solutions iterates over all of the solutions represented by the ZDD
+func (z *ZDD) Solutions() iter.Seq[[][]string] {
+
+	return func(yield func([][]string) bool) {
+
+		path := make([]int, 0) // options chosen on the path from the root
+
+		// visit iterates over the solutions of node k, returning false if
+		// the iteration should halt
+		var visit func(k int) bool
+		visit = func(k int) bool {
+			for k > 1 {
+				node := z.Nodes[k]
+				path = append(path, node.Option)
+				if !visit(node.Hi) {
+					return false
+				}
+				path = path[:len(path)-1]
+				k = node.Lo
+			}
+
+			if k == 0 {
+				return true
+			}
+
+			solution := make([][]string, len(path))
+			for i, option := range path {
+				solution[i] = slices.Clone(z.Options[option])
+			}
+			return yield(solution)
+		}
+
+		visit(z.Root)
+	}
+}
+
+// ExactCoverZDD implements Algorithm Z (7.2.2.1), dancing links with
+// memoization, building a ZDD of all the solutions to the exact cover problem
+// accepted by ExactCover. Subproblems with the same set of active items are
+// solved only once. Each option of the item chosen at level l is a node whose
+// Lo is the next option of the item and whose Hi is the ZDD of the subproblem
+// remaining after the option is chosen.
+//
+// stats, if not nil, counts the subproblems solved (Nodes) at each level
+func ExactCoverZDD(ctx context.Context, items []string, options [][]string,
+	secondary []string, stats *ExactCoverStats) (*ZDD, error) {
+
+	var (
+		n1     int             // number of primary items
+		n2     int             // number of secondary items
+		n      int             // total number of items
+		llink  []int           // left link of the item
+		rlink  []int           // right link of the item
+		top    []int           // item of the node, or -k for the spacer after option k
+		llen   []int           // length of the item list
+		ulink  []int           // up link of the node
+		dlink  []int           // down link of the node
+		active []uint64        // bitset of the active items
+		memo   map[string]int  // ZDD node of each solved subproblem
+		unique map[ZDDNode]int // ZDD node for each distinct (option, lo, hi)
+		ticks  int             // subproblems since the context was checked
+		err    error           // context error which halted the search
+	)
+
+	zdd := &ZDD{
+		Nodes:   []ZDDNode{{}, {}},
+		Options: options,
+	}
+
+	// Fill out the item tables
+	n1 = len(items)
+	n2 = len(secondary)
+	n = n1 + n2
+
+	if stats != nil {
+		stats.Theta = stats.Delta
+		stats.MaxLevel = -1
+		if stats.Levels == nil {
+			stats.Levels = make([]int, n)
+		} else {
+			for len(stats.Levels) < n {
+				stats.Levels = append(stats.Levels, 0)
+			}
+		}
+	}
+
+	index := make(map[string]int)
+	llink = make([]int, n+2)
+	rlink = make([]int, n+2)
+	for j, item := range append(slices.Clone(items), secondary...) {
+		i := j + 1
+		if _, ok := index[item]; ok {
+			return nil, fmt.Errorf("item '%s' is not unique", item)
+		}
+		index[item] = i
+		llink[i] = i - 1
+		rlink[i-1] = i
+	}
+
+	// two doubly linked lists, primary and secondary
+	// head of the primary list is at i=0
+	// head of the secondary list is at i=n+1
+	llink[n+1] = n
+	rlink[n] = n + 1
+	llink[n1+1] = n + 1
+	rlink[n+1] = n1 + 1
+	llink[0] = n1
+	rlink[n1] = 0
+
+	// Fill out the option tables
+	size := n + 2
+	for _, option := range options {
+		size += len(option) + 1
+	}
+	top = make([]int, size)
+	llen = top[0 : n+1]
+	ulink = make([]int, size)
+	dlink = make([]int, size)
+	for i := 1; i <= n; i++ {
+		ulink[i] = i
+		dlink[i] = i
+	}
+
+	x := n + 1
+	spacerX := x
+	for k, option := range options {
+		if len(option) == 0 {
+			return nil, fmt.Errorf("option %d is empty", k+1)
+		}
+		primary := false
+		for _, item := range option {
+			i, ok := index[item]
+			if !ok {
+				return nil, fmt.Errorf("item '%s' in option %d is not a declared item", item, k+1)
+			}
+			if i <= n1 {
+				primary = true
+			}
+
+			x++
+			top[x] = i
+			llen[i]++
+			ulink[x] = ulink[i]
+			dlink[ulink[i]] = x
+			ulink[i] = x
+			dlink[x] = i
+		}
+		if !primary {
+			return nil, fmt.Errorf("option %d has no primary items", k+1)
+		}
+
+		// Insert spacer at end of each option
+		dlink[spacerX] = x
+		x++
+		ulink[x] = spacerX + 1
+		top[x] = -(k + 1)
+		spacerX = x
+	}
+
+	active = make([]uint64, (n+64)/64)
+	for i := 1; i <= n; i++ {
+		active[i/64] |= 1 << (i % 64)
+	}
+	memo = make(map[string]int)
+	unique = make(map[ZDDNode]int)
+	buf := make([]byte, 8*len(active))
+
+	hide := func(p int) {
+		q := p + 1
+		for q != p {
+			x := top[q]
+			u, d := ulink[q], dlink[q]
+			if x <= 0 {
+				q = u // q was a spacer
+			} else {
+				dlink[u], ulink[d] = d, u
+				llen[x]--
+				q++
+			}
+		}
+	}
+
+	cover := func(i int) {
+		p := dlink[i]
+		for p != i {
+			hide(p)
+			p = dlink[p]
+		}
+		l, r := llink[i], rlink[i]
+		rlink[l], llink[r] = r, l
+		active[i/64] &^= 1 << (i % 64)
+	}
+
+	unhide := func(p int) {
+		q := p - 1
+		for q != p {
+			x := top[q]
+			u, d := ulink[q], dlink[q]
+			if x <= 0 {
+				q = d // q was a spacer
+			} else {
+				dlink[u], ulink[d] = q, q
+				llen[x]++
+				q--
+			}
+		}
+	}
+
+	uncover := func(i int) {
+		l, r := llink[i], rlink[i]
+		rlink[l], llink[r] = i, i
+		p := ulink[i]
+		for p != i {
+			unhide(p)
+			p = ulink[p]
+		}
+		active[i/64] |= 1 << (i % 64)
+	}
+
+	// mrv selects the next item to try using the Minimum Remaining
+	// Values heuristic.
+	mrv := func() int {
+		i := 0
+		theta := -1
+		for p := rlink[0]; p != 0; p = rlink[p] {
+			if llen[p] < theta || theta == -1 {
+				theta = llen[p]
+				i = p
+				if theta == 0 {
+					break
+				}
+			}
+		}
+		return i
+	}
+
+	// node returns the ZDD node for (option, lo, hi), creating it if
+	// necessary
+	node := func(option, lo, hi int) int {
+		if hi == 0 {
+			// zero-suppressed
+			return lo
+		}
+		key := ZDDNode{Option: option, Lo: lo, Hi: hi}
+		if k, ok := unique[key]; ok {
+			return k
+		}
+		zdd.Nodes = append(zdd.Nodes, key)
+		unique[key] = len(zdd.Nodes) - 1
+		return len(zdd.Nodes) - 1
+	}
+
+	// solve returns the ZDD node for the subproblem of the active items
+	var solve func(level int) int
+	solve = func(level int) int {
+
+		if rlink[0] == 0 {
+			return 1
+		}
+
+		// Has this subproblem already been solved?
+		for w, bits := range active {
+			binary.LittleEndian.PutUint64(buf[8*w:], bits)
+		}
+		key := string(buf)
+		if k, ok := memo[key]; ok {
+			return k
+		}
+
+		ticks++
+		if ticks&(contextCheckInterval-1) == 0 {
+			if err = ctx.Err(); err != nil {
+				return 0
+			}
+		}
+
+		if stats != nil {
+			stats.Levels[level]++
+			stats.Nodes++
+			if level > stats.MaxLevel {
+				stats.MaxLevel = level
+			}
+			if stats.Progress && stats.Nodes >= stats.Theta {
+				log.Printf("Current level %d, memo size %d, ZDD size %d, %v",
+					level, len(memo), len(zdd.Nodes), *stats)
+				stats.Theta += stats.Delta
+			}
+		}
+
+		// Choose i
+		i := mrv()
+
+		// Cover i
+		cover(i)
+
+		// Try each option x of i
+		var chain []ZDDNode
+		for x := dlink[i]; x != i; x = dlink[x] {
+
+			// Cover the other items of the option
+			for p := x + 1; p != x; {
+				if j := top[p]; j <= 0 {
+					p = ulink[p]
+				} else {
+					cover(j)
+					p++
+				}
+			}
+
+			hi := solve(level + 1)
+
+			// Uncover the other items of the option
+			for p := x - 1; p != x; {
+				if j := top[p]; j <= 0 {
+					p = dlink[p]
+				} else {
+					uncover(j)
+					p--
+				}
+			}
+
+			if err != nil {
+				uncover(i)
+				return 0
+			}
+
+			// Find the spacer at the end of the option
+			p := x
+			for top[p] > 0 {
+				p++
+			}
+			chain = append(chain, ZDDNode{Option: -top[p] - 1, Hi: hi})
+		}
+
+		// Uncover i
+		uncover(i)
+
+		// Link the options of i, from the last to the first
+		k := 0
+		for c := len(chain) - 1; c >= 0; c-- {
+			k = node(chain[c].Option, k, chain[c].Hi)
+		}
+
+		memo[key] = k
+		return k
+	}
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	zdd.Root = solve(0)
+	if err != nil {
+		return nil, err
+	}
+
+	return zdd, nil
+}
@@ -0,0 +1,144 @@
+package taocp
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExactCoverZDD(t *testing.T) {
+
+	cases := []struct {
+		items     []string
+		options   [][]string
+		secondary []string
+	}{
+		{xcItems, xcOptions, []string{}},
+		{[]string{"a", "b"}, [][]string{{"a"}, {"a", "b"}}, []string{}},
+		{[]string{"a", "b", "c"}, [][]string{{"a"}, {"b"}, {"c"}, {"a", "b"}, {"b", "c"}, {"a", "b", "c"}}, []string{}},
+	}
+	for n := 4; n <= 8; n++ {
+		items, options, secondary := nQueensXC(n)
+		cases = append(cases, struct {
+			items     []string
+			options   [][]string
+			secondary []string
+		}{items, options, secondary})
+	}
+
+	for i, c := range cases {
+		var expected [][][]string
+		for solution := range ExactCover(c.items, c.options, c.secondary, nil) {
+			expected = append(expected, solution)
+		}
+
+		zdd, err := ExactCoverZDD(context.Background(), c.items, c.options, c.secondary, nil)
+		if err != nil {
+			t.Errorf("For case #%d, expected no error; got %v", i, err)
+			continue
+		}
+
+		if count := zdd.Count(); count.Int64() != int64(len(expected)) {
+			t.Errorf("For case #%d, expected count %d; got %v", i, len(expected), count)
+		}
+
+		var got [][][]string
+		for solution := range zdd.Solutions() {
+			got = append(got, solution)
+		}
+
+		sortSolutions(expected)
+		sortSolutions(got)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("For case #%d, expected solutions %v; got %v", i, expected, got)
+		}
+	}
+}
+
+func TestExactCoverZDDSample(t *testing.T) {
+
+	items, options, secondary := nQueensXC(6)
+	zdd, err := ExactCoverZDD(context.Background(), items, options, secondary, nil)
+	if err != nil {
+		t.Fatalf("expected no error; got %v", err)
+	}
+
+	valid := make(map[string]bool)
+	for solution := range zdd.Solutions() {
+		sortSolutions([][][]string{solution})
+		valid[zddSolutionKey(solution)] = true
+	}
+
+	// Each of the 4 solutions should be sampled about 1000 times
+	rng := rand.New(rand.NewSource(0))
+	counts := make(map[string]int)
+	for k := 0; k < 4000; k++ {
+		solution := zdd.Sample(rng)
+		sortSolutions([][][]string{solution})
+		key := zddSolutionKey(solution)
+		if !valid[key] {
+			t.Fatalf("expected a valid solution; got %v", solution)
+		}
+		counts[key]++
+	}
+
+	if len(counts) != 4 {
+		t.Errorf("expected 4 distinct solutions; got %d", len(counts))
+	}
+	for key, count := range counts {
+		if count < 850 || count > 1150 {
+			t.Errorf("expected about 1000 samples of %s; got %d", key, count)
+		}
+	}
+
+	// No solutions
+	zdd, err = ExactCoverZDD(context.Background(), []string{"a", "b"}, [][]string{{"a"}}, []string{}, nil)
+	if err != nil {
+		t.Fatalf("expected no error; got %v", err)
+	}
+	if solution := zdd.Sample(rng); solution != nil {
+		t.Errorf("expected no solution; got %v", solution)
+	}
+}
+
+// zddSolutionKey joins the options of a sorted solution into a string
+func zddSolutionKey(solution [][]string) string {
+	var result []string
+	for _, option := range solution {
+		result = append(result, strings.Join(option, " "))
+	}
+	return strings.Join(result, ", ")
+}
+
+func TestExactCoverZDDErrors(t *testing.T) {
+
+	cases := []struct {
+		items     []string
+		options   [][]string
+		secondary []string
+		err       string
+	}{
+		{[]string{"a", "a"}, [][]string{{"a"}}, []string{}, "item 'a' is not unique"},
+		{[]string{"a"}, [][]string{{"a", "b"}}, []string{}, "item 'b' in option 1 is not a declared item"},
+		{[]string{"a"}, [][]string{{"a"}, {}}, []string{}, "option 2 is empty"},
+		{[]string{"a"}, [][]string{{"a"}, {"x"}}, []string{"x"}, "option 2 has no primary items"},
+	}
+
+	for i, c := range cases {
+		_, err := ExactCoverZDD(context.Background(), c.items, c.options, c.secondary, nil)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("For case #%d, expected error %q; got %v", i, c.err, err)
+		}
+	}
+
+	// Canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	items, options, secondary := nQueensXC(8)
+	if _, err := ExactCoverZDD(ctx, items, options, secondary, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error %v; got %v", context.Canceled, err)
+	}
+}
@@ -3,12 +3,15 @@ package taocp
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"log"
 	"math"
 	"math/rand"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Explore Satisfiability from The Art of Computer Programming, Volume 4,
@@ -26,16 +29,41 @@ type SatStats struct {
 	Delta        int  // Display progress every Delta number of Nodes
 	SuppressDump bool // Don't display the dump()
 
+	// Optional callback for structured metrics, see SatTelemetry, reported
+	// at most every TelemetryInterval (every node if 0) and when done
+	Telemetry         func(SatTelemetry)
+	TelemetryInterval time.Duration
+
 	// Statistics collectors
-	MaxLevel  int   // Maximum level reached
-	Theta     int   // Display progress at next Theta number of Nodes
-	Levels    []int // Count of times each level is entered
-	Nodes     int   // Count of nodes processed
-	Solutions int   // Count of solutions returned
+	MaxLevel         int   // Maximum level reached
+	Theta            int   // Display progress at next Theta number of Nodes
+	Levels           []int // Count of times each level is entered
+	Nodes            int   // Count of nodes processed
+	Solutions        int   // Count of solutions returned
+	Decisions        int   // Count of two-way branches (Algorithms A, B, C, D and L)
+	Propagations     int   // Count of literals forced by unit clauses (Algorithms C, D and L)
+	Conflicts        int   // Count of conflicts found (Algorithms C, D and L)
+	Learned          int   // Count of learned clauses (Algorithm C)
+	Restarts         int   // Count of restarts (Algorithms C, W and P)
+	Flips            int   // Count of flips (Algorithms W and P)
+	Probes           int   // Count of literals probed by lookahead (Algorithm L)
+	DoubleLookaheads int   // Count of double lookaheads (Algorithm L)
+
+	telemetry satTelemetryState // state of the Telemetry reports
 }
 
 // SatOptions provides SAT runtime options
 type SatOptions struct {
+	// Optional writer for a DRAT proof of unsatisfiability, checked by
+	// SatCheckProof (Algorithms C, D and L) - default nil. Algorithm L
+	// writes none if SuppressBigClauses converts the clauses to 3SAT, since
+	// the new variables are defined by RAT clauses which SatCheckProof,
+	// checking only RUP, can't accept.
+	Proof io.Writer
+
+	// Optional heuristic for choosing the literal of a two-way branch
+	// (Algorithms B and D) - default nil, their own choice
+	Branching SatBranching
 }
 
 // String returns a String representation of type SATStats struct
@@ -90,13 +118,28 @@ func SatRead(filename string) (SatClauses, map[int]string, error) {
 	if err != nil {
 		return nil, nil, fmt.Errorf("error opening %s for reading: %v", filename, err)
 	}
+	defer f.Close()
+
+	clauses, variable2name, err := SatParse(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error scanning %s: %v", filename, err)
+	}
+
+	return clauses, variable2name, nil
+}
+
+// SatParse parses SAT clauses in Knuth format from r and returns
+// a list of clauses along with the mapping of variables
+// (numeric to string name). The variables are numbered 1, 2, ... in the
+// order their names first appear.
+func SatParse(r io.Reader) (SatClauses, map[int]string, error) {
 
 	var clauses SatClauses
 	variable2name := make(map[int]string)
 	name2variable := make(map[string]int)
 	nextVariable := 1 // next literal to use
 
-	scanner := bufio.NewScanner(f)
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := scanner.Text()
 
@@ -134,12 +177,46 @@ func SatRead(filename string) (SatClauses, map[int]string, error) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, nil, fmt.Errorf("error scanning %s: %v", filename, err)
+		return nil, nil, err
 	}
 
 	return clauses, variable2name, nil
 }
 
+// SatWrite writes clauses to w in Knuth format, one clause per line, using
+// the variable names from variables. Variables without a name are written
+// using their number, as a name: SatParse renumbers the variables in the
+// order they first appear, so reading the clauses back gives the same
+// numbers only if the variables first appear in order 1, 2, ...; otherwise
+// each variable keeps its name, and an unnamed one the name of its number.
+func SatWrite(w io.Writer, clauses SatClauses, variables map[int]string) error {
+
+	bw := bufio.NewWriter(w)
+
+	for _, clause := range clauses {
+		for i, literal := range clause {
+			if i > 0 {
+				bw.WriteString(" ")
+			}
+
+			variable := literal
+			if literal < 0 {
+				bw.WriteString("~")
+				variable = -literal
+			}
+
+			if name, ok := variables[variable]; ok {
+				bw.WriteString(name)
+			} else {
+				bw.WriteString(strconv.Itoa(variable))
+			}
+		}
+		bw.WriteString("\n")
+	}
+
+	return bw.Flush()
+}
+
 // SatWaerdan returns the SAT clauses for waerden(j,k;n) which are satisfiable
 // if there exists a binary sequence with length n containing no j equally
 // spaced 0s and no k equally spaced 1s.
@@ -386,6 +463,15 @@ func SatTest(n int, clauses SatClauses, solution []int) bool {
 	return true
 }
 
+// satCheckProjection panics unless every variable of projection is in 1..n
+func satCheckProjection(n int, projection []int) {
+	for _, k := range projection {
+		if k < 1 || k > n {
+			panic(fmt.Sprintf("Expected projection variables in 1..%d; got %d", n, k))
+		}
+	}
+}
+
 // binomial efficiently computes the binomial coefficient (n pick k)
 func binomial(n, k int64) int64 {
 	if k == 0 {
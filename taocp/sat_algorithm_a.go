@@ -238,6 +238,8 @@ func SatAlgorithmA(n int, clauses SatClauses,
 	//
 
 	initialize()
+	stats.telemetryStart("A")
+	defer stats.telemetryDone()
 
 	if debug {
 		log.Printf("A1. Initialize")
@@ -277,6 +279,10 @@ A2:
 	if stats != nil {
 		stats.Levels[d-1]++
 		stats.Nodes++
+		if moves[d] < 2 {
+			stats.Decisions++
+		}
+		stats.telemetryTick()
 
 		if progress {
 			if d > stats.MaxLevel {
@@ -19,25 +19,47 @@ import (
 func SatAlgorithmB(n int, clauses SatClauses,
 	stats *SatStats, options *SatOptions) (bool, []int) {
 
+	var solution []int
+
+	sat := satAlgorithmB(n, clauses, stats, options, n, func(s []int) bool {
+		solution = s
+		return false
+	})
+
+	return sat, solution
+}
+
+// satAlgorithmB implements Algorithm B, passing each satisfying assignment to
+// visit until it returns false. Once a solution has been visited, the search
+// backtracks to depth np, so the solutions visited are distinct in the
+// variables 1..np. Returns true if any solution was visited.
+func satAlgorithmB(n int, clauses SatClauses,
+	stats *SatStats, options *SatOptions, np int, visit func([]int) bool) bool {
+
 	// State represents a single cell in the state table
 	type State struct {
 		L int // literal
 	}
 
 	var (
-		m         int     // total number of clauses
-		stateSize int     // total size of the state table
-		state     []State // search state
-		start     []int   // start of each clause in the table
-		watch     []int   // list of all clauses that currently watch l
-		link      []int   // the number of another clause with the same watch literal
-		d         int     // depth-plus-one of the implicit search tree
-		l         int     // literal
-		p         int     // index into the state table
-		i, j, k   int     // indices
-		moves     []int   // store current progress
-		debug     bool    // debugging is enabled
-		progress  bool    // progress tracking is enabled
+		m         int          // total number of clauses
+		stateSize int          // total size of the state table
+		state     []State      // search state
+		start     []int        // start of each clause in the table
+		watch     []int        // list of all clauses that currently watch l
+		link      []int        // the number of another clause with the same watch literal
+		d         int          // depth-plus-one of the implicit search tree
+		l         int          // literal
+		p         int          // index into the state table
+		i, j, k   int          // indices
+		moves     []int        // store current progress
+		order     []int        // variable set at each depth
+		depth     []int        // depth of each variable, or n+1 if unset
+		value     []int        // value of each variable, for branching
+		branching SatBranching // heuristic of options.Branching, or nil
+		found     bool         // a solution has been visited
+		debug     bool         // debugging is enabled
+		progress  bool         // progress tracking is enabled
 	)
 
 	// dump
@@ -133,6 +155,15 @@ func SatAlgorithmB(n int, clauses SatClauses,
 		link = make([]int, m+1)
 		watch = make([]int, 2*n+2)
 		moves = make([]int, n+1)
+		order = make([]int, n+1)
+		depth = make([]int, n+1)
+		for k := 1; k <= n; k++ {
+			depth[k] = n + 1
+		}
+		if options != nil && options.Branching != nil {
+			branching = options.Branching
+			value = make([]int, n+1)
+		}
 
 		for _, clause := range clauses {
 			stateSize += len(clause)
@@ -195,7 +226,7 @@ func SatAlgorithmB(n int, clauses SatClauses,
 	lvisit := func() []int {
 		solution := make([]int, n)
 		for i := 1; i < n+1; i++ {
-			solution[i-1] = (moves[i] % 2) ^ 1
+			solution[order[i]-1] = (moves[i] % 2) ^ 1
 		}
 		if debug {
 			log.Printf("visit solution=%v", solution)
@@ -209,6 +240,8 @@ func SatAlgorithmB(n int, clauses SatClauses,
 	//
 
 	initialize()
+	stats.telemetryStart("B")
+	defer stats.telemetryDone()
 
 	if debug {
 		log.Printf("B1. Initialize")
@@ -237,16 +270,55 @@ B2:
 			stats.Solutions++
 		}
 
-		return true, lvisit()
+		found = true
+		if !visit(lvisit()) {
+			return true
+		}
+
+		// Try the next values of x_1..x_np
+		for k := np + 1; k <= n; k++ {
+			depth[order[k]] = n + 1
+		}
+		d = np + 1
+		goto B6
 	}
 
-	if watch[2*d] == 0 || watch[2*d+1] != 0 {
-		moves[d] = 1
+	if branching == nil {
+		order[d] = d
+		if watch[2*d] == 0 || watch[2*d+1] != 0 {
+			moves[d] = 1
+		} else {
+			moves[d] = 0
+		}
 	} else {
-		moves[d] = 0
+		// Choose an unset variable, from x_1..x_np while any of them are
+		// unset
+		lo, hi := 1, np
+		if d > np {
+			lo, hi = np+1, n
+		}
+		var candidates []int
+		for k := 1; k <= n; k++ {
+			if depth[k] <= n {
+				value[k] = (moves[depth[k]] % 2) ^ 1
+			} else {
+				value[k] = -1
+				if lo <= k && k <= hi {
+					candidates = append(candidates, k)
+				}
+			}
+		}
+
+		lit := branching.Branch(clauses, value, candidates)
+		if lit > 0 {
+			order[d], moves[d] = lit, 0
+		} else {
+			order[d], moves[d] = -lit, 1
+		}
 	}
+	depth[order[d]] = d
 
-	l = 2*d + moves[d]
+	l = 2*order[d] + moves[d]
 
 	if debug {
 		log.Printf("B2. [Choose.] d=%d, l=%d, moves=%v", d, l, moves[1:d+1])
@@ -255,6 +327,8 @@ B2:
 	if stats != nil {
 		stats.Levels[d-1]++
 		stats.Nodes++
+		stats.Decisions++
+		stats.telemetryTick()
 
 		if progress {
 			if d > stats.MaxLevel {
@@ -302,7 +376,7 @@ B3:
 			}
 
 			// check if lp isn't false
-			if (lp>>1) > d || (lp+moves[lp>>1])%2 == 0 {
+			if depth[lp>>1] > d || (lp+moves[depth[lp>>1]])%2 == 0 {
 				state[i].L = lp
 				state[k].L = l ^ 1
 				link[j] = watch[lp]
@@ -346,7 +420,7 @@ B5:
 
 	if moves[d] < 2 {
 		moves[d] = 3 - moves[d]
-		l = 2*d + (moves[d] & 1)
+		l = 2*order[d] + (moves[d] & 1)
 
 		if debug {
 			log.Printf("B5.   d=%d, l=%d, moves=%v", d, l, moves[1:d+1])
@@ -362,16 +436,20 @@ B5:
 	//
 	// B6. [Backtrack.]
 	//
+B6:
 	if debug {
 		log.Printf("B6. [Backtrack.]")
 	}
 
 	if d == 1 {
-		// unsatisfiable
-		return false, nil
+		// no more solutions
+		return found
 	}
 
-	// Decrement the depth
+	// Decrement the depth, unsetting the variable at this depth
+	if d <= n {
+		depth[order[d]] = n + 1
+	}
 	d -= 1
 
 	if debug {
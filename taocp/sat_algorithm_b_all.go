@@ -0,0 +1,74 @@
+package taocp
+
+import (
+	"iter"
+)
+
+// SatAlgorithmBAll implements Algorithm B (7.2.2.2), satisfiability by
+// watching, returning all satisfying assignments by chronological
+// backtracking.
+//
+// If projection is not nil, the solutions are distinct only in the variables
+// of projection: for each assignment of those variables which can be extended
+// to a solution, a single solution is returned. This avoids the duplicates
+// which differ only in auxiliary variables, such as those created by SatMaxR
+// or Sat3. The variables of projection are branched on first, in the order
+// given. They must be in 1..n, or it panics.
+//
+// Arguments:
+// n          -- number of strictly distinct literals
+// clauses    -- list of clauses to satisfy
+// stats      -- SAT processing statistics
+// options    -- runtime options
+// projection -- variables in which the solutions are distinct, or nil for all
+func SatAlgorithmBAll(n int, clauses SatClauses,
+	stats *SatStats, options *SatOptions, projection []int) iter.Seq[[]int] {
+
+	return func(yield func([]int) bool) {
+
+		satCheckProjection(n, projection)
+
+		if projection == nil {
+			satAlgorithmB(n, clauses, stats, options, n, yield)
+			return
+		}
+
+		// Renumber the variables so those of projection come first; order[i]
+		// is the original variable which becomes variable i+1
+		order := make([]int, 0, n)
+		renumber := make([]int, n+1)
+		for _, k := range projection {
+			if renumber[k] == 0 {
+				order = append(order, k)
+				renumber[k] = len(order)
+			}
+		}
+		np := len(order)
+		for k := 1; k <= n; k++ {
+			if renumber[k] == 0 {
+				order = append(order, k)
+				renumber[k] = len(order)
+			}
+		}
+
+		renumbered := make(SatClauses, len(clauses))
+		for i, clause := range clauses {
+			renumbered[i] = make(SatClause, len(clause))
+			for j, k := range clause {
+				if k >= 0 {
+					renumbered[i][j] = renumber[k]
+				} else {
+					renumbered[i][j] = -renumber[-k]
+				}
+			}
+		}
+
+		satAlgorithmB(n, renumbered, stats, options, np, func(s []int) bool {
+			solution := make([]int, n)
+			for i, v := range s {
+				solution[order[i]-1] = v
+			}
+			return yield(solution)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package taocp
+
+import (
+	"fmt"
+	"iter"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// satProjections returns the distinct restrictions to projection of the
+// solutions of clauses, found by trying every assignment of the n variables
+func satProjections(n int, clauses SatClauses, projection []int) map[string]bool {
+	projections := make(map[string]bool)
+	solution := make([]int, n)
+	for bits := 0; bits < 1<<n; bits++ {
+		for k := range solution {
+			solution[k] = bits >> k & 1
+		}
+		if SatTest(n, clauses, solution) {
+			projections[satProjectionKey(solution, projection)] = true
+		}
+	}
+	return projections
+}
+
+// satProjectionKey returns the values of the variables of projection in
+// solution, or of every variable if projection is nil
+func satProjectionKey(solution []int, projection []int) string {
+	if projection == nil {
+		return fmt.Sprint(solution)
+	}
+	values := make([]int, len(projection))
+	for i, k := range projection {
+		values[i] = solution[k-1]
+	}
+	return fmt.Sprint(values)
+}
+
+// testSatAll checks that the solutions are valid, distinct in projection, and
+// cover every projected solution of the clauses
+func testSatAll(t *testing.T, name string, n int, clauses SatClauses,
+	projection []int, solutions iter.Seq[[]int]) {
+
+	expected := satProjections(n, clauses, projection)
+	got := make(map[string]bool)
+
+	for solution := range solutions {
+		if !SatTest(n, clauses, solution) {
+			t.Errorf("For %s, expected a valid solution; got %v", name, solution)
+			return
+		}
+		key := satProjectionKey(solution, projection)
+		if got[key] {
+			t.Errorf("For %s, expected distinct solutions; got %s twice", name, key)
+			return
+		}
+		got[key] = true
+	}
+
+	if len(got) != len(expected) {
+		t.Errorf("For %s, expected %d solutions; got %d", name, len(expected), len(got))
+	}
+}
+
+func TestSatAlgorithmBAll(t *testing.T) {
+
+	maxR, numV := SatMaxR(2, SatClause{1, 2, 3, 4, 5}, 6)
+
+	cases := []struct {
+		n          int        // number of strictly distinct literals
+		clauses    SatClauses // clauses to satisfy
+		projection []int      // variables in which the solutions are distinct
+	}{
+		{0, SatClauses{}, nil},
+		{3, SatClauses{}, nil},
+		{3, SatClauses{}, []int{2}},
+		{3, SatClauses{{1, -2}, {2, 3}, {-1, -3}, {-1, -2, 3}}, nil},
+		{3, SatClauses{{1, -2}, {2, 3}, {-1, -3}, {-1, -2, 3}, {1, 2, -3}}, nil},
+		{4, ClausesRPrime, nil},
+		{4, ClausesRPrime, []int{4, 1}},
+		{4, ClausesR, nil},
+		{8, SatWaerdan(3, 3, 8), nil},
+		{5 + numV, maxR, nil},
+		{5 + numV, maxR, []int{1, 2, 3, 4, 5}},
+	}
+
+	for i, c := range cases {
+		solutions := SatAlgorithmBAll(c.n, c.clauses, &SatStats{}, &SatOptions{}, c.projection)
+		testSatAll(t, fmt.Sprintf("case #%d", i), c.n, c.clauses, c.projection, solutions)
+	}
+
+	// Random clauses with random projections
+	rng := rand.New(rand.NewSource(0))
+	for seed := int64(0); seed < 30; seed++ {
+		n := 10
+		clauses := SatRand(3, 20+int(seed), n, seed)
+		projection := rng.Perm(n)[:rng.Intn(n)]
+		for i := range projection {
+			projection[i]++
+		}
+
+		solutions := SatAlgorithmBAll(n, clauses, nil, &SatOptions{}, projection)
+		testSatAll(t, fmt.Sprintf("seed %d", seed), n, clauses, projection, solutions)
+	}
+}
+
+// testSatProjectionRange checks that solve panics with a message for a
+// projection outside the variables 1..3
+func testSatProjectionRange(t *testing.T, name string, solve func(projection []int)) {
+	for _, projection := range [][]int{{0}, {1, -2}, {4}} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("For %s, projection %v, expected a panic", name, projection)
+				} else if msg, ok := r.(string); !ok || !strings.HasPrefix(msg, "Expected projection variables in 1..3") {
+					t.Errorf("For %s, projection %v, expected a projection panic; got %v", name, projection, r)
+				}
+			}()
+			solve(projection)
+		}()
+	}
+}
+
+func TestSatAllProjectionRange(t *testing.T) {
+
+	clauses := SatClauses{{1, 2}, {-1, 3}}
+
+	testSatProjectionRange(t, "SatAlgorithmBAll", func(projection []int) {
+		for range SatAlgorithmBAll(3, clauses, nil, nil, projection) {
+		}
+	})
+	testSatProjectionRange(t, "SatAlgorithmDAll", func(projection []int) {
+		for range SatAlgorithmDAll(3, clauses, nil, nil, projection) {
+		}
+	})
+}
+
+func TestSatAlgorithmBAllLangford(t *testing.T) {
+
+	cases := []struct {
+		n     int // Langford pairs
+		count int // number of solutions, up to reversal
+	}{
+		{3, 1},
+		{4, 1},
+		{5, 0},
+		{7, 26},
+		{8, 150},
+	}
+
+	for _, c := range cases {
+		clauses, options := SatLangford(c.n)
+
+		count := 0
+		for range SatAlgorithmBAll(len(options), clauses, nil, &SatOptions{}, nil) {
+			count++
+		}
+
+		if count != c.count {
+			t.Errorf("For langford(%d), expected %d solutions; got %d", c.n, c.count, count)
+		}
+	}
+}
@@ -0,0 +1,873 @@
+package taocp
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+)
+
+// Parameters of Algorithm C
+const (
+	satCRho         = 0.95  // decay factor for variable activity
+	satCRhoClause   = 0.999 // decay factor for clause activity
+	satCLubyUnit    = 100   // number of conflicts in each unit of the Luby sequence
+	satCPurgeFirst  = 2000  // number of conflicts before the first purge
+	satCPurgeDelta  = 300   // increase of the purge interval after each purge
+	satCRescale     = 1e100 // activities are rescaled when they exceed this
+	satCMaxGlueKept = 2     // learned clauses with glue this small are never purged
+	satCUnassigned  = -1    // value of a variable which is not set
+	satCNoReason    = -1    // reason of a decision or an unset variable
+)
+
+// satCClause is a single clause in the memory of Algorithm C, original or
+// learned. The first two literals are watched, and the first literal of a
+// clause which forced a value is the forced literal.
+type satCClause struct {
+	L        []int   // literals
+	Learned  bool    // learned clauses may be purged
+	Activity float64 // bumped when the clause is used to resolve a conflict
+	Glue     int     // number of distinct levels in the clause when learned
+}
+
+// satCWatcher is an entry in the watch list of a literal. The clause need not
+// be examined while its blocker, another of its literals, is true.
+type satCWatcher struct {
+	C       int // clause
+	Blocker int // literal
+}
+
+// SatSolver is an incremental satisfiability solver using Algorithm C. It
+// keeps its clauses, learned clauses, variable activities and saved
+// polarities from one call of Solve to the next, so a sequence of closely
+// related problems may be solved by adding clauses, or by solving under
+// different assumptions, without starting again from scratch.
+type SatSolver struct {
+	n          int             // number of variables
+	stats      *SatStats       // SAT processing statistics
+	mem        []satCClause    // all clauses, original and learned
+	watch      [][]satCWatcher // clauses watching each literal
+	x          []int           // value of each variable, or satCUnassigned
+	level      []int           // level at which each variable was set
+	reason     []int           // clause which forced each variable, or satCNoReason
+	oval       []int           // saved polarity of each variable, initially true
+	trail      []int           // literals which are true, in order of assignment
+	lim        []int           // length of the trail at the start of each level
+	g          int             // number of trail literals already propagated
+	d          int             // current decision level
+	act        []float64       // activity of each variable
+	inc        float64         // current variable activity increment
+	incClause  float64         // current clause activity increment
+	heap       []int           // free variables, in a max-heap by activity
+	hloc       []int           // location of each variable in heap, or -1
+	seen       []bool          // variables marked during conflict analysis
+	conflicts  int             // total number of conflicts
+	nextLuby   int             // index of the next term of the Luby sequence
+	restartAt  int             // number of conflicts which triggers a restart
+	purgeAt    int             // number of conflicts which triggers a purge
+	purgeDist  int             // current interval between purges
+	learnedNum int             // number of learned clauses in memory
+	proof      *satProof       // DRAT proof of unsatisfiability, or nil
+	empty      bool            // the empty clause has been derived
+	debug      bool            // debugging is enabled
+	progress   bool            // progress tracking is enabled
+}
+
+// NewSatSolver returns an incremental solver for clauses on the variables
+// 1..n, with no clauses. Clauses on larger variables may be added later.
+//
+// Arguments:
+// n       -- initial number of variables
+// stats   -- SAT processing statistics, accumulated over all calls of Solve
+// options -- runtime options
+func NewSatSolver(n int, stats *SatStats, options *SatOptions) *SatSolver {
+
+	s := &SatSolver{
+		stats:     stats,
+		watch:     make([][]satCWatcher, 2),
+		x:         make([]int, 1),
+		level:     make([]int, 1),
+		reason:    make([]int, 1),
+		oval:      make([]int, 1),
+		act:       make([]float64, 1),
+		hloc:      make([]int, 1),
+		seen:      make([]bool, 1),
+		inc:       1,
+		incClause: 1,
+		purgeDist: satCPurgeFirst,
+		purgeAt:   satCPurgeFirst,
+		proof:     newSatProof(options),
+	}
+	s.restartAt = satCLubyUnit * s.luby(s.nextLuby)
+	s.nextLuby++
+
+	if stats != nil {
+		stats.Theta = stats.Delta
+		stats.MaxLevel = -1
+		s.debug = stats.Debug
+		s.progress = stats.Progress
+	}
+
+	s.grow(n)
+
+	return s
+}
+
+// grow increases the number of variables to n
+func (s *SatSolver) grow(n int) {
+	for s.n < n {
+		s.n++
+		k := s.n
+		s.watch = append(s.watch, nil, nil)
+		s.x = append(s.x, satCUnassigned)
+		s.level = append(s.level, 0)
+		s.reason = append(s.reason, satCNoReason)
+		s.oval = append(s.oval, 1)
+		s.act = append(s.act, 0)
+		s.hloc = append(s.hloc, -1)
+		s.seen = append(s.seen, false)
+		s.heapInsert(k)
+	}
+
+	if s.stats != nil {
+		for len(s.stats.Levels) < s.n {
+			s.stats.Levels = append(s.stats.Levels, 0)
+		}
+	}
+}
+
+// literal returns the literal of Algorithm C, 2k or 2k+1, for x_k or ~x_k
+func (s *SatSolver) literal(k int) int {
+	if k >= 0 {
+		return 2 * k
+	}
+	return -2*k + 1
+}
+
+// isFalse returns true if literal l is false
+func (s *SatSolver) isFalse(l int) bool {
+	return s.x[l>>1] == l&1
+}
+
+// isTrue returns true if literal l is true
+func (s *SatSolver) isTrue(l int) bool {
+	return s.x[l>>1] == l&1^1
+}
+
+// heapUp moves the variable at location i up the heap
+func (s *SatSolver) heapUp(i int) {
+	k := s.heap[i]
+	for i > 0 {
+		parent := (i - 1) / 2
+		if s.act[s.heap[parent]] >= s.act[k] {
+			break
+		}
+		s.heap[i] = s.heap[parent]
+		s.hloc[s.heap[i]] = i
+		i = parent
+	}
+	s.heap[i] = k
+	s.hloc[k] = i
+}
+
+// heapDown moves the variable at location i down the heap
+func (s *SatSolver) heapDown(i int) {
+	k := s.heap[i]
+	for {
+		child := 2*i + 1
+		if child >= len(s.heap) {
+			break
+		}
+		if child+1 < len(s.heap) && s.act[s.heap[child+1]] > s.act[s.heap[child]] {
+			child++
+		}
+		if s.act[s.heap[child]] <= s.act[k] {
+			break
+		}
+		s.heap[i] = s.heap[child]
+		s.hloc[s.heap[i]] = i
+		i = child
+	}
+	s.heap[i] = k
+	s.hloc[k] = i
+}
+
+// heapInsert inserts variable k into the heap, if not already present
+func (s *SatSolver) heapInsert(k int) {
+	if s.hloc[k] >= 0 {
+		return
+	}
+	s.heap = append(s.heap, k)
+	s.heapUp(len(s.heap) - 1)
+}
+
+// heapPop removes and returns the variable of maximum activity
+func (s *SatSolver) heapPop() int {
+	k := s.heap[0]
+	s.hloc[k] = -1
+	last := s.heap[len(s.heap)-1]
+	s.heap = s.heap[:len(s.heap)-1]
+	if len(s.heap) > 0 {
+		s.heap[0] = last
+		s.heapDown(0)
+	}
+	return k
+}
+
+// bump increases the activity of variable k
+func (s *SatSolver) bump(k int) {
+	s.act[k] += s.inc
+	if s.act[k] > satCRescale {
+		for i := range s.act {
+			s.act[i] /= satCRescale
+		}
+		s.inc /= satCRescale
+	}
+	if s.hloc[k] >= 0 {
+		s.heapUp(s.hloc[k])
+	}
+}
+
+// bumpClause increases the activity of learned clause c
+func (s *SatSolver) bumpClause(c int) {
+	if !s.mem[c].Learned {
+		return
+	}
+	s.mem[c].Activity += s.incClause
+	if s.mem[c].Activity > satCRescale {
+		for i := range s.mem {
+			s.mem[i].Activity /= satCRescale
+		}
+		s.incClause /= satCRescale
+	}
+}
+
+// luby returns term i of the Luby sequence 1, 1, 2, 1, 1, 2, 4, ...
+func (s *SatSolver) luby(i int) int {
+	size, seq := 1, 0
+	for size < i+1 {
+		seq++
+		size = 2*size + 1
+	}
+	for size-1 != i {
+		size = (size - 1) / 2
+		seq--
+		i %= size
+	}
+	return 1 << seq
+}
+
+// assign makes literal l true at the current level, forced by clause c
+func (s *SatSolver) assign(l, c int) {
+	k := l >> 1
+	s.x[k] = l&1 ^ 1
+	s.level[k] = s.d
+	s.reason[k] = c
+	s.trail = append(s.trail, l)
+}
+
+// addWatches makes clause c watch its first two literals
+func (s *SatSolver) addWatches(c int) {
+	l0, l1 := s.mem[c].L[0], s.mem[c].L[1]
+	s.watch[l0] = append(s.watch[l0], satCWatcher{c, l1})
+	s.watch[l1] = append(s.watch[l1], satCWatcher{c, l0})
+}
+
+// propagate makes the unpropagated trail literals true, forcing the literals
+// of clauses which have become units. Returns the clause which has become
+// false, or -1 if there is no conflict.
+func (s *SatSolver) propagate() int {
+	for s.g < len(s.trail) {
+		// l has become false
+		l := s.trail[s.g] ^ 1
+		s.g++
+
+		ws := s.watch[l]
+		i, j := 0, 0
+	Watchers:
+		for i < len(ws) {
+			w := ws[i]
+			i++
+
+			// Is the clause satisfied by its blocker?
+			if s.isTrue(w.Blocker) {
+				ws[j] = w
+				j++
+				continue
+			}
+
+			c := w.C
+			lits := s.mem[c].L
+
+			// Make l the second literal
+			if lits[0] == l {
+				lits[0], lits[1] = lits[1], lits[0]
+			}
+
+			// Is the clause already satisfied?
+			w.Blocker = lits[0]
+			if s.isTrue(lits[0]) {
+				ws[j] = w
+				j++
+				continue
+			}
+
+			// Look for a new literal to watch
+			for k := 2; k < len(lits); k++ {
+				if !s.isFalse(lits[k]) {
+					lits[1], lits[k] = lits[k], lits[1]
+					s.watch[lits[1]] = append(s.watch[lits[1]], satCWatcher{c, lits[0]})
+					continue Watchers
+				}
+			}
+
+			// The clause is a unit, or false
+			ws[j] = w
+			j++
+			if s.isFalse(lits[0]) {
+				for i < len(ws) {
+					ws[j] = ws[i]
+					i++
+					j++
+				}
+				s.watch[l] = ws[:j]
+				return c
+			}
+			s.assign(lits[0], c)
+			if s.stats != nil {
+				s.stats.Propagations++
+			}
+		}
+		s.watch[l] = ws[:j]
+	}
+
+	return -1
+}
+
+// backjump undoes all assignments above level b
+func (s *SatSolver) backjump(b int) {
+	if s.d <= b {
+		return
+	}
+	for i := len(s.trail) - 1; i >= s.lim[b]; i-- {
+		k := s.trail[i] >> 1
+		s.oval[k] = s.x[k]
+		s.x[k] = satCUnassigned
+		s.reason[k] = satCNoReason
+		s.heapInsert(k)
+	}
+	s.trail = s.trail[:s.lim[b]]
+	s.lim = s.lim[:b]
+	s.g = len(s.trail)
+	s.d = b
+}
+
+// analyze resolves the conflict in clause c, returning the learned clause,
+// with the literal to be forced first and a literal of the backjump level
+// second, and the backjump level
+func (s *SatSolver) analyze(c int) ([]int, int) {
+	learned := []int{0}
+	count := 0 // number of marked literals at the current level
+	l := -1
+	t := len(s.trail) - 1
+
+	for {
+		s.bumpClause(c)
+		lits := s.mem[c].L
+		if l >= 0 {
+			// Skip the literal forced by c
+			lits = lits[1:]
+		}
+		for _, lp := range lits {
+			k := lp >> 1
+			if !s.seen[k] && s.level[k] > 0 {
+				s.seen[k] = true
+				s.bump(k)
+				if s.level[k] == s.d {
+					count++
+				} else {
+					learned = append(learned, lp)
+				}
+			}
+		}
+
+		// Find the next marked literal on the trail
+		for !s.seen[s.trail[t]>>1] {
+			t--
+		}
+		l = s.trail[t]
+		t--
+		s.seen[l>>1] = false
+		count--
+		if count == 0 {
+			break
+		}
+		c = s.reason[l>>1]
+	}
+	learned[0] = l ^ 1
+
+	// Remove the literals implied by the other marked literals
+	marked := slices.Clone(learned[1:])
+	j := 1
+	for _, lp := range learned[1:] {
+		redundant := false
+		if r := s.reason[lp>>1]; r != satCNoReason {
+			redundant = true
+			for _, lpp := range s.mem[r].L[1:] {
+				if k := lpp >> 1; !s.seen[k] && s.level[k] > 0 {
+					redundant = false
+					break
+				}
+			}
+		}
+		if !redundant {
+			learned[j] = lp
+			j++
+		}
+	}
+	learned = learned[:j]
+	for _, lp := range marked {
+		s.seen[lp>>1] = false
+	}
+
+	// Find the backjump level
+	b := 0
+	for i := 2; i < len(learned); i++ {
+		if s.level[learned[i]>>1] > s.level[learned[1]>>1] {
+			learned[1], learned[i] = learned[i], learned[1]
+		}
+	}
+	if len(learned) > 1 {
+		b = s.level[learned[1]>>1]
+	}
+
+	return learned, b
+}
+
+// analyzeFinal returns the assumptions which force the assumption l to be
+// false, including l itself
+func (s *SatSolver) analyzeFinal(l int) []int {
+	core := []int{l}
+	k := l >> 1
+	if s.level[k] == 0 {
+		return core
+	}
+
+	s.seen[k] = true
+	for i := len(s.trail) - 1; i >= s.lim[0]; i-- {
+		k := s.trail[i] >> 1
+		if !s.seen[k] {
+			continue
+		}
+		if r := s.reason[k]; r == satCNoReason {
+			// Every decision below the current level is an assumption
+			core = append(core, s.trail[i])
+		} else {
+			for _, lp := range s.mem[r].L[1:] {
+				if s.level[lp>>1] > 0 {
+					s.seen[lp>>1] = true
+				}
+			}
+		}
+		s.seen[k] = false
+	}
+	s.seen[l>>1] = false
+
+	return core
+}
+
+// glue returns the number of distinct levels of the literals of clause
+func (s *SatSolver) glue(clause []int) int {
+	levels := make(map[int]bool)
+	for _, l := range clause {
+		levels[s.level[l>>1]] = true
+	}
+	return len(levels)
+}
+
+// purge removes the learned clauses of larger glue and smaller activity,
+// keeping those which are the reason for a current value
+func (s *SatSolver) purge() {
+	var candidates []int
+	for c := range s.mem {
+		if s.mem[c].Learned && s.mem[c].Glue > satCMaxGlueKept && s.reason[s.mem[c].L[0]>>1] != c {
+			candidates = append(candidates, c)
+		}
+	}
+	slices.SortFunc(candidates, func(a, b int) int {
+		if s.mem[a].Glue != s.mem[b].Glue {
+			return s.mem[b].Glue - s.mem[a].Glue
+		}
+		if s.mem[a].Activity < s.mem[b].Activity {
+			return -1
+		} else if s.mem[a].Activity > s.mem[b].Activity {
+			return 1
+		}
+		return 0
+	})
+
+	purged := make([]bool, len(s.mem))
+	for _, c := range candidates[:len(candidates)/2] {
+		purged[c] = true
+		s.proof.delete(s.mem[c].L)
+	}
+
+	// Compact the memory and renumber the reasons
+	renumber := make([]int, len(s.mem))
+	c := 0
+	for cp := range s.mem {
+		if purged[cp] {
+			renumber[cp] = -1
+			s.learnedNum--
+			continue
+		}
+		renumber[cp] = c
+		s.mem[c] = s.mem[cp]
+		c++
+	}
+	s.mem = s.mem[:c]
+	for k := 1; k <= s.n; k++ {
+		if s.reason[k] != satCNoReason {
+			s.reason[k] = renumber[s.reason[k]]
+		}
+	}
+
+	for l := range s.watch {
+		s.watch[l] = s.watch[l][:0]
+	}
+	for c := range s.mem {
+		s.addWatches(c)
+	}
+
+	if s.debug {
+		log.Printf("Purged %d learned clauses, %d remain", len(candidates)/2, s.learnedNum)
+	}
+}
+
+// AddClause adds a clause to the solver, in the same form as the clauses of
+// SatClauses. Variables larger than the current number of variables are
+// added to the solver.
+func (s *SatSolver) AddClause(clause SatClause) {
+
+	// Return to level 0, where the clause may be simplified
+	s.backjump(0)
+
+	for _, k := range clause {
+		if k > s.n || -k > s.n {
+			s.grow(max(k, -k))
+		}
+	}
+
+	if s.empty {
+		return
+	}
+
+	// Compute the literals, removing duplicates and literals which are false
+	// at level 0; the clause is discarded if it is a tautology or is true at
+	// level 0
+	var lits []int
+	for _, k := range clause {
+		l := s.literal(k)
+		if slices.Contains(lits, l^1) || s.isTrue(l) {
+			return
+		}
+		if !slices.Contains(lits, l) && !s.isFalse(l) {
+			lits = append(lits, l)
+		}
+	}
+
+	switch len(lits) {
+	case 0:
+		if s.debug {
+			log.Printf("C1. Empty clause")
+		}
+		s.empty = true
+		s.proof.add(nil)
+	case 1:
+		// Unit clauses are forced at level 0
+		s.assign(lits[0], satCNoReason)
+	default:
+		s.mem = append(s.mem, satCClause{L: lits})
+		s.addWatches(len(s.mem) - 1)
+	}
+}
+
+// Solve determines if the clauses added so far are satisfiable when all of
+// the assumptions, literals in the same form as the clauses, are true. If
+// they are satisfiable, it returns true and one satisfying assignment of
+// every variable known to the solver. If not, it returns false and the core,
+// the assumptions which can't all be true; the core is empty if the clauses
+// are unsatisfiable without any assumptions.
+func (s *SatSolver) Solve(assumptions []int) (bool, []int, []int) {
+
+	defer s.proof.flush()
+
+	var (
+		stats     = s.stats
+		debug     = s.debug
+		progress  = s.progress
+		assumeLit = make([]int, len(assumptions)) // literals of the assumptions
+	)
+
+	for i, k := range assumptions {
+		if k > s.n || -k > s.n {
+			s.grow(max(k, -k))
+		}
+		assumeLit[i] = s.literal(k)
+	}
+
+	// showProgress
+	showProgress := func() {
+		log.Printf("Nodes=%d, d=%d, conflicts=%d, learned=%d, trail=%d",
+			stats.Nodes, s.d, s.conflicts, s.learnedNum, len(s.trail))
+	}
+
+	// core converts the literals of the failed assumptions
+	core := func(lits []int) []int {
+		core := make([]int, len(lits))
+		for i, l := range lits {
+			core[i] = l >> 1
+			if l&1 == 1 {
+				core[i] = -core[i]
+			}
+		}
+		return core
+	}
+
+	s.backjump(0)
+
+	stats.telemetryStart("C")
+	defer stats.telemetryDone()
+
+	if s.empty {
+		return false, nil, []int{}
+	}
+
+	for {
+		//
+		// C3. [Advance G.] Propagate the consequences of the trail.
+		//
+		c := s.propagate()
+
+		if c >= 0 {
+			//
+			// C7. [Resolve a conflict.]
+			//
+			s.conflicts++
+			if stats != nil {
+				stats.Conflicts++
+				stats.telemetryTick()
+			}
+
+			if s.d == 0 {
+				if debug {
+					log.Printf("C7. Conflict at level 0 in clause (%s)", satAlgorithmCString(s.mem[c].L))
+				}
+				s.empty = true
+				s.proof.add(nil)
+				return false, nil, []int{}
+			}
+
+			learned, b := s.analyze(c)
+
+			if debug {
+				log.Printf("C7. Conflict in clause (%s) at level %d, learned (%s), backjump to %d",
+					satAlgorithmCString(s.mem[c].L), s.d, satAlgorithmCString(learned), b)
+			}
+
+			//
+			// C8. [Backjump.]
+			//
+			s.backjump(b)
+
+			//
+			// C9. [Learn.]
+			//
+			s.proof.add(learned)
+			if stats != nil {
+				stats.Learned++
+			}
+			if len(learned) == 1 {
+				s.assign(learned[0], satCNoReason)
+			} else {
+				s.mem = append(s.mem, satCClause{
+					L:        learned,
+					Learned:  true,
+					Activity: s.incClause,
+					Glue:     s.glue(learned),
+				})
+				s.learnedNum++
+				s.addWatches(len(s.mem) - 1)
+				s.assign(learned[0], len(s.mem)-1)
+			}
+
+			s.inc /= satCRho
+			s.incClause /= satCRhoClause
+
+			continue
+		}
+
+		//
+		// C5. [New level?]
+		//
+
+		// Restart?
+		if s.conflicts >= s.restartAt {
+			if debug {
+				log.Printf("C5. Restart after %d conflicts", s.conflicts)
+			}
+			if stats != nil {
+				stats.Restarts++
+			}
+			s.restartAt = s.conflicts + satCLubyUnit*s.luby(s.nextLuby)
+			s.nextLuby++
+			s.backjump(0)
+		}
+
+		// Purge?
+		if s.conflicts >= s.purgeAt {
+			s.purgeDist += satCPurgeDelta
+			s.purgeAt = s.conflicts + s.purgeDist
+			s.purge()
+		}
+
+		//
+		// C6. [Make a decision.]
+		//
+		l := -1
+		for s.d < len(assumeLit) {
+			// The next level is the next assumption
+			a := assumeLit[s.d]
+			if s.isTrue(a) {
+				// Already true, so the level is empty
+				s.lim = append(s.lim, len(s.trail))
+				s.d++
+				continue
+			}
+			if s.isFalse(a) {
+				lits := s.analyzeFinal(a)
+				failed := core(lits)
+				if debug {
+					log.Printf("C6. Assumption %d failed, core %v", assumptions[s.d], failed)
+				}
+
+				// The assumptions of the core can't all be true
+				for i := range lits {
+					lits[i] ^= 1
+				}
+				s.proof.add(lits)
+
+				s.backjump(0)
+				return false, nil, failed
+			}
+			l = a
+			break
+		}
+
+		if l < 0 {
+			k := 0
+			for len(s.heap) > 0 {
+				if kp := s.heapPop(); s.x[kp] == satCUnassigned {
+					k = kp
+					break
+				}
+			}
+
+			if k == 0 {
+				// All variables are set, visit the solution
+				if debug {
+					log.Println("C5. [Success!]")
+				}
+				if stats != nil {
+					stats.Solutions++
+				}
+
+				solution := slices.Clone(s.x[1:])
+				if debug {
+					log.Printf("visit solution=%v", solution)
+				}
+				s.backjump(0)
+
+				return true, solution, nil
+			}
+
+			l = 2*k + (s.oval[k] ^ 1)
+		}
+
+		s.lim = append(s.lim, len(s.trail))
+		s.d++
+
+		if stats != nil {
+			for len(stats.Levels) < s.d {
+				stats.Levels = append(stats.Levels, 0)
+			}
+			stats.Levels[s.d-1]++
+			stats.Nodes++
+			stats.Decisions++
+			if s.d > stats.MaxLevel {
+				stats.MaxLevel = s.d
+			}
+			stats.telemetryTick()
+
+			if progress {
+				if stats.Nodes >= stats.Theta {
+					showProgress()
+					stats.Theta += stats.Delta
+				}
+			}
+		}
+
+		if debug {
+			log.Printf("C6. [Make a decision.] d=%d, l=%s", s.d, satAlgorithmCString([]int{l}))
+		}
+
+		s.assign(l, satCNoReason)
+	}
+}
+
+// SatAlgorithmC implements Algorithm C (7.2.2.2), satisfiability by
+// conflict-driven clause learning. The task is to determine if the clause set
+// is satisfiable, and if it is return one satisfying assignment of the
+// clauses.
+//
+// Each clause watches its first two literals. Decisions choose the free
+// variable of maximum activity, bumped for every variable involved in a
+// conflict and decayed geometrically (VSIDS), with its saved polarity. Every
+// conflict is resolved to a first-UIP learned clause, which is minimized and
+// then forces a literal after backjumping. The search restarts following the
+// Luby sequence, and periodically purges the half of the learned clauses with
+// the most distinct levels (glue) and least activity.
+//
+// Arguments:
+// n       -- number of strictly distinct literals
+// clauses -- list of clauses to satisfy
+// stats   -- SAT processing statistics
+// options -- runtime options
+func SatAlgorithmC(n int, clauses SatClauses,
+	stats *SatStats, options *SatOptions) (bool, []int) {
+
+	solver := NewSatSolver(n, stats, options)
+	for _, clause := range clauses {
+		solver.AddClause(clause)
+	}
+
+	sat, solution, _ := solver.Solve(nil)
+
+	return sat, solution
+}
+
+// satAlgorithmCString returns a string representation of the internal
+// literals of a clause, using ~ for negated variables
+func satAlgorithmCString(lits []int) string {
+	var b strings.Builder
+	for i, l := range lits {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		if l&1 == 1 {
+			b.WriteString("~")
+		}
+		b.WriteString(fmt.Sprintf("%d", l>>1))
+	}
+	return b.String()
+}
@@ -0,0 +1,368 @@
+package taocp
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSatAlgorithmC(t *testing.T) {
+
+	log.SetFlags(log.Flags() &^ (log.Ldate | log.Ltime))
+
+	cases := []struct {
+		n       int        // number of strictly distinct literals
+		sat     bool       // is satisfiable
+		clauses SatClauses // clauses to satisfy
+	}{
+		{0, true, SatClauses{}},
+		{1, false, SatClauses{{}}},
+		{1, true, SatClauses{{1}}},
+		{1, true, SatClauses{{-1}}},
+		{1, false, SatClauses{{1}, {-1}}},
+		{1, true, SatClauses{{1, -1}}},
+		{3, true, SatClauses{{1}, {2}, {-3}}},
+		{2, true, SatClauses{{1, 2}, {1, -2}}},
+		{2, false, SatClauses{{1, 2}, {-1, -2}, {1, -2}, {-1, 2}}},
+		{5, true, SatClauses{{1, -2}, {2, 2}, {-1, 3}, {2, 4}, {-4, 5}}},
+		{100, true, SatRand(2, 80, 100, 0)},
+		{100, true, SatRand(2, 100, 100, 0)},
+		{100, false, SatRand(2, 400, 100, 0)},
+		{1000, true, SatRand(2, 1000, 1000, 0)},
+		{1000, true, SatRand(2, 1100, 1000, 0)},
+		{1000, false, SatRand(2, 2000, 1000, 0)},
+		{100, true, SatRand(3, 420, 100, 0)},
+		{100, false, SatRand(3, 500, 100, 0)},
+		{3, true, SatClauses{{1, -2}, {2, 3}, {-1, -3}, {-1, -2, 3}}},
+		{3, false, SatClauses{{1, -2}, {2, 3}, {-1, -3}, {-1, -2, 3}, {1, 2, -3}}},
+		{4, true, ClausesRPrime},
+		{4, false, ClausesR},
+		{9, false, ClausesWaerden339},
+		{8, true, SatWaerdan(3, 3, 8)},
+		{21, true, SatWaerdan(3, 5, 21)},
+		{22, false, SatWaerdan(3, 5, 22)},
+		{5, false, SatComplete(5)},
+	}
+
+	for i, c := range cases {
+
+		stats := SatStats{
+			// Debug: true,
+			// Progress: true,
+		}
+		options := SatOptions{}
+
+		sat, solution := SatAlgorithmC(c.n, c.clauses, &stats, &options)
+
+		if sat != c.sat {
+			t.Errorf("expected satisfiable=%t for case %d, clauses %v; got %t", c.sat, i, c.clauses, sat)
+		} else if sat {
+			validSolution := SatTest(c.n, c.clauses, solution)
+			if !validSolution {
+				t.Errorf("expected a valid solution for case %d, n=%d, clauses=%v; did not get one (solution=%v)", i, c.n, c.clauses, solution)
+			}
+		}
+	}
+}
+
+func TestSatAlgorithmCFromFile(t *testing.T) {
+
+	log.SetFlags(log.Flags() &^ (log.Ldate | log.Ltime))
+
+	cases := []struct {
+		filename     string // file name of the SAT data file
+		numVariables int    // number of variable
+		numClauses   int    // number of clauses to satisfy
+		sat          bool   // is satisfiable
+	}{
+		{"testdata/SATExamples/L1.sat", 130, 2437, false},
+		{"testdata/SATExamples/L2.sat", 273, 1020, false},
+		{"testdata/SATExamples/L5.sat", 1472, 102922, true},
+		{"testdata/SATExamples/X2.sat", 129, 354, false},
+		{"testdata/SATExamples/P3.sat", 144, 529, true},
+		{"testdata/SATExamples/P4.sat", 400, 2509, true},
+	}
+
+	for _, c := range cases {
+
+		t.Run(c.filename, func(t *testing.T) {
+			t.Parallel()
+
+			clauses, variables, err := SatRead(c.filename)
+
+			if err != nil {
+				t.Errorf("expected to read file %s; got error %v", c.filename, err)
+				return
+			}
+			if len(variables) != c.numVariables {
+				t.Errorf("expected %d variables; got %d", c.numVariables, len(variables))
+				return
+			}
+			if len(clauses) != c.numClauses {
+				t.Errorf("expected %d clauses; got %d", c.numClauses, len(clauses))
+				return
+			}
+
+			stats := SatStats{}
+			options := SatOptions{}
+
+			sat, solution := SatAlgorithmC(len(variables), clauses, &stats, &options)
+
+			if sat != c.sat {
+				t.Errorf("expected satisfiable=%t for filename %s; got %t", c.sat, c.filename, sat)
+			} else if sat {
+				validSolution := SatTest(c.numVariables, clauses, solution)
+				if !validSolution {
+					t.Errorf("expected a valid solution for filename %s; did not get one", c.filename)
+				}
+			}
+		})
+	}
+}
+
+func TestSatAlgorithmCLangford(t *testing.T) {
+
+	log.SetFlags(log.Flags() &^ (log.Ldate | log.Ltime))
+
+	for n := 2; n <= 12; n++ {
+
+		t.Run(fmt.Sprintf("langford(%d)", n), func(t *testing.T) {
+			t.Parallel()
+
+			stats := SatStats{}
+			options := SatOptions{}
+
+			expected := false
+			if n%4 == 0 || n%4 == 3 {
+				expected = true
+			}
+
+			clauses, coverOptions := SatLangford(n)
+
+			sat, solution := SatAlgorithmC(len(coverOptions), clauses, &stats, &options)
+
+			if sat != expected {
+				t.Errorf("expected langford(%d) satisfiable=%t; got %t", n, expected, sat)
+			} else if sat {
+				validSolution := SatTest(len(coverOptions), clauses, solution)
+				if !validSolution {
+					t.Errorf("expected a valid solution for langford(%d); did not get one", n)
+				}
+			}
+		})
+	}
+}
+
+// TestSatAlgorithmCAgreesWithD compares the results of Algorithms C and D on
+// random 3SAT near the threshold
+func TestSatAlgorithmCAgreesWithD(t *testing.T) {
+
+	for seed := int64(0); seed < 50; seed++ {
+		n := 40
+		clauses := SatRand(3, 170+int(seed%20), n, seed)
+
+		satD, _ := SatAlgorithmD(n, clauses, &SatStats{}, &SatOptions{})
+
+		stats := SatStats{}
+		sat, solution := SatAlgorithmC(n, clauses, &stats, &SatOptions{})
+
+		if sat != satD {
+			t.Errorf("For seed %d, expected satisfiable=%t (Algorithm D); got %t", seed, satD, sat)
+		} else if sat && !SatTest(n, clauses, solution) {
+			t.Errorf("For seed %d, expected a valid solution; did not get one", seed)
+		}
+	}
+}
+
+func BenchmarkSatAlgorithmCFromFile(b *testing.B) {
+
+	cases := []string{
+		"testdata/SATExamples/L1.sat",
+		"testdata/SATExamples/L2.sat",
+		"testdata/SATExamples/L5.sat",
+		"testdata/SATExamples/X2.sat",
+		"testdata/SATExamples/P3.sat",
+		"testdata/SATExamples/P4.sat",
+	}
+
+	for _, filename := range cases {
+
+		firstExecution := true
+
+		clauses, variables, _ := SatRead(filename)
+
+		b.Run(filename, func(b *testing.B) {
+
+			for i := 0; i < b.N; i++ {
+				stats := SatStats{}
+				options := SatOptions{}
+
+				sat, _ := SatAlgorithmC(len(variables), clauses, &stats, &options)
+
+				if firstExecution {
+					b.Logf("SAT=%t, n=%d, m=%d, nodes=%d, conflicts=%d", sat, len(variables), len(clauses), stats.Nodes, stats.Conflicts)
+					firstExecution = false
+				}
+			}
+		})
+	}
+}
+
+func TestSatSolverIncremental(t *testing.T) {
+
+	cases := []struct {
+		j, k int // van der Waerden parameters
+		w    int // W(j,k), the smallest n which is unsatisfiable
+	}{
+		{3, 3, 9},
+		{3, 4, 18},
+		{3, 5, 22},
+		{4, 4, 35},
+	}
+
+	for _, c := range cases {
+
+		stats := SatStats{}
+		solver := NewSatSolver(0, &stats, &SatOptions{})
+		added := make(map[string]bool)
+
+		for n := 1; n <= c.w; n++ {
+
+			// Add the clauses of waerden(j,k;n) which weren't in waerden(j,k;n-1)
+			clauses := SatWaerdan(c.j, c.k, n)
+			for _, clause := range clauses {
+				key := fmt.Sprint(clause)
+				if !added[key] {
+					solver.AddClause(clause)
+					added[key] = true
+				}
+			}
+
+			sat, solution, core := solver.Solve(nil)
+
+			if sat != (n < c.w) {
+				t.Errorf("For waerden(%d,%d;%d), expected satisfiable=%t; got %t", c.j, c.k, n, n < c.w, sat)
+			} else if sat && !SatTest(len(solution), clauses, solution) {
+				t.Errorf("For waerden(%d,%d;%d), expected a valid solution; got %v", c.j, c.k, n, solution)
+			} else if !sat && len(core) != 0 {
+				t.Errorf("For waerden(%d,%d;%d), expected an empty core; got %v", c.j, c.k, n, core)
+			}
+		}
+	}
+}
+
+func TestSatSolverAssumptions(t *testing.T) {
+
+	solver := NewSatSolver(3, nil, nil)
+	solver.AddClause(SatClause{1, 2})
+	solver.AddClause(SatClause{-1, 3})
+
+	cases := []struct {
+		assumptions []int // literals assumed to be true
+		sat         bool  // is satisfiable
+		core        []int // expected core, sorted
+	}{
+		{nil, true, nil},
+		{[]int{-3}, true, nil},
+		{[]int{-3, -2}, false, []int{-3, -2}},
+		{[]int{-2, 1, -3}, false, []int{-3, -2}}, // 1 is implied by -2
+		{[]int{2, -2}, false, []int{-2, 2}},
+		{[]int{4, -3, 1}, false, []int{-3, 1}},
+		{[]int{4}, true, nil},
+		{nil, true, nil},
+	}
+
+	for i, c := range cases {
+		sat, solution, core := solver.Solve(c.assumptions)
+
+		if sat != c.sat {
+			t.Errorf("For case #%d, expected satisfiable=%t; got %t", i, c.sat, sat)
+			continue
+		}
+
+		if sat {
+			clauses := SatClauses{{1, 2}, {-1, 3}}
+			for _, a := range c.assumptions {
+				clauses = append(clauses, SatClause{a})
+			}
+			if !SatTest(len(solution), clauses, solution) {
+				t.Errorf("For case #%d, expected a valid solution; got %v", i, solution)
+			}
+		} else {
+			slices.Sort(core)
+			if !reflect.DeepEqual(core, c.core) {
+				t.Errorf("For case #%d, expected core %v; got %v", i, c.core, core)
+			}
+		}
+	}
+
+	// Once the clauses are unsatisfiable, they remain so
+	solver.AddClause(SatClause{-3})
+	solver.AddClause(SatClause{-2})
+	for i := 0; i < 2; i++ {
+		if sat, _, core := solver.Solve([]int{1}); sat || len(core) != 0 {
+			t.Errorf("expected unsatisfiable with an empty core; got satisfiable=%t, core %v", sat, core)
+		}
+	}
+}
+
+// TestSatSolverCores checks the cores of random assumptions against
+// Algorithm D
+func TestSatSolverCores(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(0))
+
+	for seed := int64(0); seed < 20; seed++ {
+		n := 30
+		clauses := SatRand(3, 100, n, seed)
+
+		solver := NewSatSolver(n, nil, nil)
+		for _, clause := range clauses {
+			solver.AddClause(clause)
+		}
+
+		for trial := 0; trial < 10; trial++ {
+			var assumptions []int
+			for _, k := range rng.Perm(n)[:8] {
+				if rng.Intn(2) == 0 {
+					assumptions = append(assumptions, k+1)
+				} else {
+					assumptions = append(assumptions, -(k + 1))
+				}
+			}
+
+			sat, solution, core := solver.Solve(assumptions)
+
+			expected := slices.Clone(clauses)
+			for _, a := range assumptions {
+				expected = append(expected, SatClause{a})
+			}
+			satD, _ := SatAlgorithmD(n, expected, &SatStats{}, &SatOptions{})
+
+			if sat != satD {
+				t.Errorf("For seed %d, trial %d, expected satisfiable=%t; got %t", seed, trial, satD, sat)
+				continue
+			}
+			if sat {
+				if !SatTest(n, expected, solution) {
+					t.Errorf("For seed %d, trial %d, expected a valid solution; got %v", seed, trial, solution)
+				}
+				continue
+			}
+
+			// The core must be a subset of the assumptions which is
+			// unsatisfiable by itself
+			for _, a := range core {
+				if !slices.Contains(assumptions, a) {
+					t.Errorf("For seed %d, trial %d, expected core %v to be a subset of %v", seed, trial, core, assumptions)
+				}
+			}
+			if sat, _, _ := solver.Solve(core); sat {
+				t.Errorf("For seed %d, trial %d, expected core %v to be unsatisfiable", seed, trial, core)
+			}
+		}
+	}
+}
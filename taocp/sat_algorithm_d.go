@@ -3,6 +3,7 @@ package taocp
 import (
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 )
 
@@ -18,32 +19,56 @@ import (
 func SatAlgorithmD(n int, clauses SatClauses,
 	stats *SatStats, options *SatOptions) (bool, []int) {
 
+	var solution []int
+
+	sat := satAlgorithmD(n, clauses, stats, options, nil, func(s []int) bool {
+		solution = s
+		return false
+	})
+
+	return sat, solution
+}
+
+// satAlgorithmD implements Algorithm D, passing each satisfying assignment to
+// visit until it returns false. If project is not nil, only the variables k
+// with project[k] true are projected: they are all set before branching on
+// any other variable, and once a solution has been visited the search
+// backtracks to the last branch on a projected variable, so the solutions
+// visited are distinct in the projected variables. Variables which are still
+// unset when the clauses are satisfied are free: every value of the free
+// projected variables is visited, and the others are false. Returns true if
+// any solution was visited.
+func satAlgorithmD(n int, clauses SatClauses,
+	stats *SatStats, options *SatOptions, project []bool, visit func([]int) bool) bool {
+
 	// State represents a single cell in the state table
 	type State struct {
 		L int // literal
 	}
 
 	var (
-		m           int     // total number of clauses
-		stateSize   int     // total size of the state table
-		state       []State // search state
-		start       []int   // start of each clause in the table
-		watch       []int   // list of all clauses that currently watch l
-		link        []int   // the number of another clause with the same watch literal
-		h           []int   // the literal being watched at depth d
-		next        []int   // active ring : not-yet-set variables whose watch lists aren't empty
-		head        int     // head pointer into the active ring
-		tail        int     // tail pointer into the active ring
-		d           int     // depth of the implicit search tree
-		x           []int   // selected literal at depth d
-		l, lp       int     // literal
-		p, q        int     // index into the state table
-		b           int     // branch on literal?
-		f           int     // flag?
-		i, j, jp, k int     // indices
-		moves       []int   // store current progress
-		debug       bool    // debugging is enabled
-		progress    bool    // progress tracking is enabled
+		m           int          // total number of clauses
+		stateSize   int          // total size of the state table
+		state       []State      // search state
+		start       []int        // start of each clause in the table
+		watch       []int        // list of all clauses that currently watch l
+		link        []int        // the number of another clause with the same watch literal
+		h           []int        // the literal being watched at depth d
+		next        []int        // active ring : not-yet-set variables whose watch lists aren't empty
+		head        int          // head pointer into the active ring
+		tail        int          // tail pointer into the active ring
+		d           int          // depth of the implicit search tree
+		x           []int        // selected literal at depth d
+		l, lp       int          // literal
+		p, q        int          // index into the state table
+		b           int          // branch on literal?
+		f           int          // flag?
+		i, j, jp, k int          // indices
+		moves       []int        // store current progress
+		branching   SatBranching // heuristic of options.Branching, or nil
+		found       bool         // a solution has been visited
+		debug       bool         // debugging is enabled
+		progress    bool         // progress tracking is enabled
 	)
 
 	// activeRing returns a string with members of the active ring
@@ -174,6 +199,9 @@ func SatAlgorithmD(n int, clauses SatClauses,
 		h = make([]int, n+1)
 		next = make([]int, n+1)
 		x = make([]int, n+1)
+		if options != nil {
+			branching = options.Branching
+		}
 
 		for _, clause := range clauses {
 			stateSize += len(clause)
@@ -228,19 +256,104 @@ func SatAlgorithmD(n int, clauses SatClauses,
 		}
 	}
 
-	// lvisit prepares the solution
-	lvisit := func() []int {
+	// isProjected determines if variable k is projected
+	isProjected := func(k int) bool {
+		return project == nil || project[k]
+	}
+
+	// lvisit prepares the solutions for each value of the free projected
+	// variables and passes them to visit(); returns false if visit() halts
+	lvisit := func() bool {
 		solution := make([]int, n)
-		for i := 1; i < n+1; i++ {
-			if h[i] > 0 {
-				solution[h[i]-1] = (moves[i] % 2) ^ 1
+		var free []int
+		for k := 1; k < n+1; k++ {
+			if x[k] >= 0 {
+				solution[k-1] = x[k]
+			} else if isProjected(k) {
+				free = append(free, k)
 			}
 		}
-		if debug {
-			log.Printf("visit solution=%v", solution)
+
+		for {
+			if debug {
+				log.Printf("visit solution=%v", solution)
+			}
+			if stats != nil {
+				stats.Solutions++
+			}
+			if !visit(slices.Clone(solution)) {
+				return false
+			}
+
+			// Advance the free variables as a binary counter
+			i := 0
+			for i < len(free) && solution[free[i]-1] == 1 {
+				solution[free[i]-1] = 0
+				i++
+			}
+			if i == len(free) {
+				return true
+			}
+			solution[free[i]-1] = 1
+		}
+	}
+
+	// branch makes an unset projected variable the head of the active ring,
+	// if there is one, so the projected variables are set before any others
+	branch := func() {
+		if isProjected(head) {
+			return
+		}
+
+		// Rotate the ring to a projected variable
+		for k := head; next[k] != head; k = next[k] {
+			if isProjected(next[k]) {
+				tail = k
+				head = next[k]
+				return
+			}
 		}
 
-		return solution
+		// Insert a projected variable with empty watch lists into the ring
+		for k := 1; k <= n; k++ {
+			if x[k] < 0 && isProjected(k) {
+				next[k] = head
+				next[tail] = k
+				head = k
+				return
+			}
+		}
+	}
+
+	// proof is the DRAT proof of unsatisfiability, or nil
+	proof := newSatProof(options)
+
+	// refute writes to the proof the clause which negates the literals set at
+	// depths 1 through depth, which can't all be true
+	refute := func(depth int) {
+		if proof == nil {
+			return
+		}
+		clause := make([]int, depth)
+		for i := 1; i <= depth; i++ {
+			clause[i-1] = 2*h[i] + x[h[i]]
+		}
+		proof.add(clause)
+	}
+
+	// forget deletes from the proof the clauses written by refute(depth) for
+	// either value of x_h[depth], which are subsumed by refute(depth-1)
+	forget := func(depth int) {
+		if proof == nil {
+			return
+		}
+		clause := make([]int, depth)
+		for i := 1; i <= depth; i++ {
+			clause[i-1] = 2*h[i] + x[h[i]]
+		}
+		proof.delete(clause)
+		clause[depth-1] ^= 1
+		proof.delete(clause)
 	}
 
 	// isUnit determines if literal l is being watched in some clause whose other
@@ -276,6 +389,9 @@ func SatAlgorithmD(n int, clauses SatClauses,
 	//
 
 	initialize()
+	defer proof.flush()
+	stats.telemetryStart("D")
+	defer stats.telemetryDone()
 
 	if debug {
 		log.Printf("D1. Initialize")
@@ -318,11 +434,32 @@ D2:
 		if debug {
 			log.Println("D2. [Success!]")
 		}
-		if stats != nil {
-			stats.Solutions++
+
+		found = true
+		if !lvisit() {
+			return true
 		}
 
-		return true, lvisit()
+		// Backtrack to the last branch on a projected variable whose other
+		// value hasn't been tried
+		for d > 0 && (moves[d] >= 2 || !isProjected(h[d])) {
+			k = h[d]
+			x[k] = -1
+			if watch[2*k] != 0 || watch[2*k+1] != 0 {
+				if tail == 0 {
+					tail = k
+					head = k
+					next[tail] = head
+				} else {
+					next[k] = head
+					head = k
+					next[tail] = head
+				}
+			}
+			d -= 1
+		}
+
+		goto D8
 	}
 
 	k = tail
@@ -350,6 +487,9 @@ D3:
 	if f == 1 || f == 2 {
 		moves[d+1] = f + 3
 		tail = k
+		if stats != nil {
+			stats.Propagations++
+		}
 		goto D5 // [Move on.]
 	}
 
@@ -363,16 +503,48 @@ D3:
 	//
 
 	head = next[tail]
-	if watch[2*head] == 0 || watch[2*head+1] != 0 {
-		moves[d+1] = 1
+	branch()
+	if branching == nil {
+		if watch[2*head] == 0 || watch[2*head+1] != 0 {
+			moves[d+1] = 1
+		} else {
+			moves[d+1] = 0
+		}
 	} else {
-		moves[d+1] = 0
+		// Choose a variable of the ring which is projected if the head is
+		var candidates []int
+		for k := head; ; k = next[k] {
+			if isProjected(k) == isProjected(head) {
+				candidates = append(candidates, k)
+			}
+			if next[k] == head {
+				break
+			}
+		}
+
+		lp = branching.Branch(clauses, x, candidates)
+		if lp > 0 {
+			moves[d+1] = 0
+		} else {
+			lp = -lp
+			moves[d+1] = 1
+		}
+
+		// Rotate the ring to make it the head
+		for next[tail] != lp {
+			tail = next[tail]
+		}
+		head = lp
 	}
 
 	if debug {
 		log.Printf("D4. [Two-way branch.] d=%d, x=%v, moves=%v", d, x[1:], moves[1:d+1])
 	}
 
+	if stats != nil {
+		stats.Decisions++
+	}
+
 D5:
 	//
 	// D5. [Move on.]
@@ -391,6 +563,7 @@ D5:
 		if d > stats.MaxLevel {
 			stats.MaxLevel = d
 		}
+		stats.telemetryTick()
 
 		if progress {
 			if stats.Nodes >= stats.Theta {
@@ -486,7 +659,18 @@ D7:
 
 	tail = k
 
+	if stats != nil {
+		stats.Conflicts++
+	}
+
+	// Both values of the head of the ring are forced
+	refute(d)
+
 	for moves[d] >= 2 {
+		// Both values of x_h[d] have failed
+		refute(d - 1)
+		forget(d)
+
 		k = h[d]
 		x[k] = -1
 		if watch[2*k] != 0 || watch[2*k+1] != 0 {
@@ -500,6 +684,7 @@ D7:
 	//
 	// D8. [Failure?]
 	//
+D8:
 	if debug {
 		log.Printf("D8. [Failure?]")
 	}
@@ -510,6 +695,6 @@ D7:
 		goto D6
 	}
 
-	// Terminate, the clauses aren't satisfiable
-	return false, nil
+	// Terminate, there are no more solutions
+	return found
 }
@@ -0,0 +1,51 @@
+package taocp
+
+import (
+	"iter"
+)
+
+// SatAlgorithmDAll implements Algorithm D (7.2.2.2), satisfiability by cyclic
+// DPLL, returning all satisfying assignments by chronological backtracking.
+// When the clauses are satisfied before every variable is set, a solution is
+// returned for each value of the variables which are still free.
+//
+// If projection is not nil, the solutions are distinct only in the variables
+// of projection: for each assignment of those variables which can be extended
+// to a solution, a single solution is returned. This avoids the duplicates
+// which differ only in auxiliary variables, such as those created by SatMaxR
+// or Sat3. The variables of projection are branched on before any others.
+// They must be in 1..n, or it panics.
+//
+// options.Proof is ignored, since there is no proof of unsatisfiability to
+// write.
+//
+// Arguments:
+// n          -- number of strictly distinct literals
+// clauses    -- list of clauses to satisfy
+// stats      -- SAT processing statistics
+// options    -- runtime options
+// projection -- variables in which the solutions are distinct, or nil for all
+func SatAlgorithmDAll(n int, clauses SatClauses,
+	stats *SatStats, options *SatOptions, projection []int) iter.Seq[[]int] {
+
+	return func(yield func([]int) bool) {
+
+		satCheckProjection(n, projection)
+
+		var project []bool
+		if projection != nil {
+			project = make([]bool, n+1)
+			for _, k := range projection {
+				project[k] = true
+			}
+		}
+
+		var optionsD SatOptions
+		if options != nil {
+			optionsD = *options
+		}
+		optionsD.Proof = nil
+
+		satAlgorithmD(n, clauses, stats, &optionsD, project, yield)
+	}
+}
@@ -0,0 +1,76 @@
+package taocp
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestSatAlgorithmDAll(t *testing.T) {
+
+	maxR, numV := SatMaxR(2, SatClause{1, 2, 3, 4, 5}, 6)
+
+	cases := []struct {
+		n          int        // number of strictly distinct literals
+		clauses    SatClauses // clauses to satisfy
+		projection []int      // variables in which the solutions are distinct
+	}{
+		{0, SatClauses{}, nil},
+		{3, SatClauses{}, nil},
+		{3, SatClauses{}, []int{2}},
+		{3, SatClauses{{1, -2}, {2, 3}, {-1, -3}, {-1, -2, 3}}, nil},
+		{3, SatClauses{{1, -2}, {2, 3}, {-1, -3}, {-1, -2, 3}, {1, 2, -3}}, nil},
+		{4, ClausesRPrime, nil},
+		{4, ClausesRPrime, []int{4, 1}},
+		{4, ClausesR, nil},
+		{8, SatWaerdan(3, 3, 8), nil},
+		{5 + numV, maxR, nil},
+		{5 + numV, maxR, []int{1, 2, 3, 4, 5}},
+	}
+
+	for i, c := range cases {
+		solutions := SatAlgorithmDAll(c.n, c.clauses, &SatStats{}, &SatOptions{}, c.projection)
+		testSatAll(t, fmt.Sprintf("case #%d", i), c.n, c.clauses, c.projection, solutions)
+	}
+
+	// Random clauses with random projections
+	rng := rand.New(rand.NewSource(0))
+	for seed := int64(0); seed < 30; seed++ {
+		n := 10
+		clauses := SatRand(3, 20+int(seed), n, seed)
+		projection := rng.Perm(n)[:rng.Intn(n)]
+		for i := range projection {
+			projection[i]++
+		}
+
+		solutions := SatAlgorithmDAll(n, clauses, nil, &SatOptions{}, projection)
+		testSatAll(t, fmt.Sprintf("seed %d", seed), n, clauses, projection, solutions)
+	}
+}
+
+func TestSatAlgorithmDAllLangford(t *testing.T) {
+
+	cases := []struct {
+		n     int // Langford pairs
+		count int // number of solutions, up to reversal
+	}{
+		{3, 1},
+		{4, 1},
+		{5, 0},
+		{7, 26},
+		{8, 150},
+	}
+
+	for _, c := range cases {
+		clauses, options := SatLangford(c.n)
+
+		count := 0
+		for range SatAlgorithmDAll(len(options), clauses, nil, &SatOptions{}, nil) {
+			count++
+		}
+
+		if count != c.count {
+			t.Errorf("For langford(%d), expected %d solutions; got %d", c.n, c.count, count)
+		}
+	}
+}
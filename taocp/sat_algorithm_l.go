@@ -12,6 +12,7 @@ const (
 	rt     = MaxInt - 1 // RT - real truth
 	nt     = MaxInt - 3 // NT - near truth
 	pt     = MaxInt - 5 // PT - proto truth
+	dpt    = MaxInt - 7 // DPT - proto truth within a double lookahead
 )
 
 // SatAlgorithmLOptions provides optional features
@@ -25,6 +26,15 @@ type SatAlgorithmLOptions struct {
 	// Optional threshold for swapping free literals in CINX,
 	// used with Big Clauses (Exercise 143)
 	Theta float64
+
+	// Optional lookahead at both values of each free variable before each
+	// decision, forcing the complement of a literal which fails - default false
+	Lookahead bool
+
+	// Optional double lookahead at each decision literal l, looking ahead
+	// at each free literal with l fixed, forcing the complement of l if it
+	// fails - default false
+	DoubleLookahead bool
 }
 
 // NewSatAlgorithmLOptions creates a new NewSatAlgorithmLOptions
@@ -41,6 +51,13 @@ func NewSatAlgorithmLOptions() *SatAlgorithmLOptions {
 // The task is to determine if the clause set is satisfiable, and if it is return
 // one satisfying assignment of the clauses.
 //
+// Without optionsL.Lookahead or optionsL.DoubleLookahead this is L^0, which
+// decides on the first free variable. With them, a literal is probed by
+// propagating it through the binary clauses and the ternary or big clauses
+// whose other literals are false. A literal whose probe has a conflict
+// fails, and its complement is forced. stats.Probes counts the literals
+// probed and stats.DoubleLookaheads the double lookaheads.
+//
 // Arguments:
 // n       -- number of strictly distinct literals
 // clauses -- list of clauses to satisfy
@@ -256,6 +273,10 @@ func SatAlgorithmL(n int, clauses SatClauses,
 			return "PF"
 		case pt:
 			return "PT"
+		case dpt + 1:
+			return "DPF"
+		case dpt:
+			return "DPT"
 		default:
 			return fmt.Sprintf("%d", t)
 		}
@@ -599,6 +620,9 @@ func SatAlgorithmL(n int, clauses SatClauses,
 					VAL[xp] = T + (lp & 1)
 					R[E] = lp
 					E += 1
+					if stats != nil {
+						stats.Propagations++
+					}
 				}
 			}
 		}
@@ -650,6 +674,188 @@ func SatAlgorithmL(n int, clauses SatClauses,
 		BSIZE[l] += 1
 	}
 
+	// proof is the DRAT proof of unsatisfiability, or nil
+	var proof *satProof
+
+	// decided returns the number of decisions in effect at depth d
+	decided := func() int {
+		if BRANCH[d] >= 0 {
+			return d + 1
+		}
+		return d
+	}
+
+	// refute writes to the proof the clause which negates the decisions at
+	// depths 0 through depth-1 and the literals lits, which can't all be true
+	refute := func(depth int, lits ...int) {
+		if proof == nil {
+			return
+		}
+		clause := make([]int, 0, depth+len(lits))
+		for i := 0; i < depth; i++ {
+			clause = append(clause, DEC[i]^1)
+		}
+		for _, l := range lits {
+			clause = append(clause, l^1)
+		}
+		proof.add(clause)
+	}
+
+	// forget deletes from the proof the clauses written by refute(depth) for
+	// either value of DEC[depth-1], which are subsumed by refute(depth-1)
+	forget := func(depth int) {
+		if proof == nil {
+			return
+		}
+		clause := make([]int, depth)
+		for i := 0; i < depth; i++ {
+			clause[i] = DEC[i] ^ 1
+		}
+		proof.delete(clause)
+		clause[depth-1] ^= 1
+		proof.delete(clause)
+	}
+
+	// lookahead_fix fixes literal l in context T, pushing it onto R.
+	// Returns true if l is already fixed false in context T.
+	lookahead_fix := func(l int) bool {
+		x := l >> 1
+		if VAL[x] >= T {
+			return VAL[x]&1 != l&1
+		}
+		VAL[x] = T + (l & 1)
+		R[E] = l
+		E += 1
+		return false
+	}
+
+	// lookahead_propagation propagates the consequences of a literal l in
+	// context T, like binary_propagation, and also the ternary or big
+	// clauses whose other literals become false. Returns false if no
+	// conflict, true if there is conflict.
+	lookahead_propagation := func(l int) bool {
+
+		H := E
+
+		if lookahead_fix(l) {
+			return true
+		}
+
+		// isTrue and isFalse determine if literal u is fixed true or false
+		isTrue := func(u int) bool {
+			return VAL[u>>1] >= T && VAL[u>>1]&1 == u&1
+		}
+		isFalse := func(u int) bool {
+			return VAL[u>>1] >= T && VAL[u>>1]&1 != u&1
+		}
+
+		for H < E {
+			l = R[H]
+			H += 1
+
+			// For each l' in BIMP(l)
+			for j := 0; j < BSIZE[l]; j++ {
+				if lookahead_fix(BIMP[l][j]) {
+					return true
+				}
+			}
+
+			if bigClauses {
+				// For each active big clause c containing ¬l
+				for i := 0; i < KSIZE[l^1]; i++ {
+					c := KINX[l^1][i]
+
+					free, last := 0, 0 // number of free literals, and the last one
+					satisfied := false
+					for _, u := range CINX[c] {
+						if isTrue(u) {
+							satisfied = true
+							break
+						} else if !isFalse(u) {
+							free += 1
+							last = u
+						}
+					}
+
+					if satisfied {
+						continue
+					} else if free == 0 {
+						return true
+					} else if free == 1 && lookahead_fix(last) {
+						return true
+					}
+				}
+
+			} else {
+				// For each pair (u, v) in TIMP(l)
+				for i := 0; i < TSIZE[l]; i++ {
+					p := TIMP[l] + 2*i
+					u, v := TIMP[p], TIMP[p+1]
+
+					if isTrue(u) || isTrue(v) {
+						continue
+					} else if isFalse(u) && isFalse(v) {
+						return true
+					} else if isFalse(u) && lookahead_fix(v) {
+						return true
+					} else if isFalse(v) && lookahead_fix(u) {
+						return true
+					}
+				}
+			}
+		}
+
+		return false
+	}
+
+	// lookahead_unfix removes the literals fixed by lookahead above R[e]
+	lookahead_unfix := func(e int) {
+		for E > e {
+			E -= 1
+			VAL[R[E]>>1] = 0
+		}
+	}
+
+	// double_lookahead fixes literal l in context PT, then looks ahead at
+	// each free literal l' in context DPT. If l' fails, ¬l' is fixed in
+	// context PT along with l. Returns true if l fails.
+	double_lookahead := func(l int) bool {
+
+		T = pt
+		failed := lookahead_propagation(l)
+
+		for k := 0; k < N && !failed; k++ {
+			y := VAR[k]
+			for _, lp := range []int{2 * y, 2*y + 1} {
+				if VAL[y] >= pt {
+					// y is fixed along with l
+					break
+				}
+
+				e := E
+				T = dpt
+				if stats != nil {
+					stats.Probes++
+					stats.telemetryTick()
+				}
+				conflict := lookahead_propagation(lp)
+				lookahead_unfix(e)
+				T = pt
+
+				if conflict {
+					refute(d, l, lp)
+					if lookahead_propagation(lp ^ 1) {
+						failed = true
+						break
+					}
+				}
+			}
+		}
+
+		lookahead_unfix(F)
+		return failed
+	}
+
 	//
 	// @note L1 [Initialize.]
 	//
@@ -669,6 +875,15 @@ func SatAlgorithmL(n int, clauses SatClauses,
 	}
 
 	initialize()
+	stats.telemetryStart("L")
+
+	// The new variables of a conversion to 3SAT are defined by RAT clauses,
+	// which SatCheckProof can't check, so no proof is written for it
+	if n == nOrig {
+		proof = newSatProof(options)
+	}
+	defer proof.flush()
+	defer stats.telemetryDone()
 
 	if debug {
 		log.Printf("L1. Initialize")
@@ -949,6 +1164,10 @@ L2:
 							dump()
 							log.Printf("L2. BIMP table for %d in R contains %d, which contradicts %d in R", l, lp, lpp)
 						}
+						if stats != nil {
+							stats.Conflicts++
+						}
+						refute(d)
 						goto L15
 					}
 				}
@@ -974,16 +1193,79 @@ L2:
 		return true, lvisit()
 	}
 
+	if optionsL.Lookahead {
+		// Look ahead at both values of each free variable. The complement
+		// of a literal which fails is forced; if both fail, there is a
+		// conflict.
+		T = pt
+		for k := 0; k < N; k++ {
+			y := VAR[k]
+
+			var failed [2]bool
+			for b := 0; b < 2; b++ {
+				if stats != nil {
+					stats.Probes++
+					stats.telemetryTick()
+				}
+				failed[b] = lookahead_propagation(2*y + b)
+				lookahead_unfix(F)
+				if failed[b] {
+					refute(d, 2*y+b)
+				}
+			}
+
+			if failed[0] && failed[1] {
+				if debug {
+					log.Printf("  Lookahead: both values of variable %d fail", y)
+				}
+				if stats != nil {
+					stats.Conflicts++
+				}
+				refute(d)
+				goto L15
+			}
+			for b := 0; b < 2; b++ {
+				if failed[b] {
+					FORCE[U] = (2*y + b) ^ 1
+					U += 1
+				}
+			}
+		}
+
+		if U > 0 {
+			if debug {
+				log.Printf("  Lookahead: forced %v", FORCE[:U])
+			}
+			goto L5
+		}
+	}
+
 	// Choose whatever literal happens to be first in the current list
 	// of free variables.
 	x = VAR[0]
 	l = 2 * x
 
+	if optionsL.DoubleLookahead {
+		if stats != nil {
+			stats.DoubleLookaheads++
+		}
+		if double_lookahead(l) {
+			if debug {
+				log.Printf("  Double lookahead: %d fails, forcing %d", l, l^1)
+			}
+			refute(d, l)
+			FORCE[0] = l ^ 1
+			U = 1
+			goto L5
+		}
+	}
+
 	stats.Levels[d]++
 	stats.Nodes++
 	if d > stats.MaxLevel {
 		stats.MaxLevel = d
 	}
+	stats.telemetryTick()
 
 	if debug {
 		log.Printf("  Selected d=%d, branch=%v, l=%d from free variable list", d, BRANCH[0:d], l)
@@ -995,7 +1277,6 @@ L2:
 	//
 	// @note L3 [Choose l.]
 	//
-L3:
 	if debug {
 		log.Printf("L3. Choose l")
 	}
@@ -1014,6 +1295,10 @@ L3:
 	BACKI[d] = I
 	BRANCH[d] = 0 // We are trying l
 
+	if stats != nil {
+		stats.Decisions++
+	}
+
 	//
 	// @note L4 [Try l.]
 	//
@@ -1428,6 +1713,7 @@ L6:
 
 							} else if BST[w^1] == BSTAMP {
 								// ¬u implies both w and ¬w, so let's try and propagate u
+								refute(decided(), u^1)
 								if binary_propagation(u) {
 									switch CONFLICT {
 									case 11:
@@ -1464,6 +1750,7 @@ L6:
 
 			if notvInBimp {
 				// ¬v ∈ BIMP[¬u], so select u as true
+				refute(decided(), u^1)
 				if binary_propagation(u) {
 					switch CONFLICT {
 					case 11:
@@ -1488,6 +1775,7 @@ L6:
 
 				if notuInBimp {
 					// ¬u ∈ BIMP[¬v], so select v as true
+					refute(decided(), v^1)
 					if binary_propagation(v) {
 						switch CONFLICT {
 						case 11:
@@ -1526,17 +1814,11 @@ L10:
 			log.Printf("  branch[%d]=%d, incremented d to %d, going to L2", d-1, BRANCH[d-1], d)
 		}
 		goto L2
-	} else if d > 0 {
-		// Does not occur for L^0, because we never "don't decide"
-		log.Panic("Can't get here for L^0")
-		if debug {
-			log.Printf("  branch[%d]=%d and d=%d > 0, going to L3", d, BRANCH[d], d)
-		}
-		goto L3
-	} else { // d == 0
-		// Only occurs if there are unit clauses in the input
+	} else {
+		// Only occurs for literals forced before a decision, by unit
+		// clauses in the input or by lookahead
 		if debug {
-			log.Printf("  branch[%d]=%d and d=0, going to L2", d, BRANCH[d])
+			log.Printf("  branch[%d]=%d and d=%d, going to L2", d, BRANCH[d], d)
 		}
 		goto L2
 	}
@@ -1550,6 +1832,13 @@ L11:
 		log.Printf("L11. Unfix near truths")
 	}
 
+	if stats != nil {
+		stats.Conflicts++
+	}
+
+	// The decisions in effect can't all be true
+	refute(decided())
+
 	for E > G {
 		E -= 1
 		VAL[R[E]>>1] = 0
@@ -1689,6 +1978,12 @@ L15:
 		log.Printf("L15. Backtrack")
 	}
 
+	if BRANCH[d] == 1 {
+		// Both values of DEC[d] have failed
+		refute(d)
+		forget(d + 1)
+	}
+
 	if d == 0 {
 		// Terminate unsuccessfully
 		return false, nil
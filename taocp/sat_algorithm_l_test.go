@@ -58,39 +58,36 @@ func TestSatAlgorithmL(t *testing.T) {
 		{10, false, true, SatWaerdan(3, 3, 10)},
 	}
 
-	for i, c := range cases {
-
-		stats := SatStats{
-			// Debug:     true,
-			// Verbosity: 1,
-			// Progress:  true,
-		}
-		options := SatOptions{}
-		optionsL := NewSatAlgorithmLOptions()
-		optionsL.CompensationResolvants = false
-		optionsL.SuppressBigClauses = !c.bigClauses
+	// Each case with L^0, with lookahead, and with double lookahead
+	lookaheads := []struct{ single, double bool }{{false, false}, {true, false}, {false, true}, {true, true}}
 
-		// var clausesStr string
-		// if len(c.clauses) < 10 {
-		// 	clausesStr = fmt.Sprintf("%v", c.clauses)
-		// } else {
-		// 	clausesStr = fmt.Sprintf("#%d", len(c.clauses))
-		// }
+	for i, c := range cases {
+		for _, lookahead := range lookaheads {
 
-		// t.Logf("Executing test case #%d, n=%d, sat=%t, bigc=%t, clauses=%s", i, c.n, c.sat, c.bigClauses, clausesStr)
+			stats := SatStats{
+				// Debug:     true,
+				// Verbosity: 1,
+				// Progress:  true,
+			}
+			options := SatOptions{}
+			optionsL := NewSatAlgorithmLOptions()
+			optionsL.CompensationResolvants = false
+			optionsL.SuppressBigClauses = !c.bigClauses
+			optionsL.Lookahead = lookahead.single
+			optionsL.DoubleLookahead = lookahead.double
 
-		sat, solution := SatAlgorithmL(c.n, c.clauses, &stats, &options, optionsL)
+			sat, solution := SatAlgorithmL(c.n, c.clauses, &stats, &options, optionsL)
 
-		if sat != c.sat {
-			t.Errorf("expected satisfiable=%t for case %d, clauses %v; got %t", c.sat, i, c.clauses, sat)
-		}
-		if sat {
-			validSolution := SatTest(c.n, c.clauses, solution)
-			if !validSolution {
-				t.Errorf("expected a valid solution for case %d, n=%d, clauses=%v; did not get one (solution=%v)", i, c.n, c.clauses, solution)
+			if sat != c.sat {
+				t.Errorf("expected satisfiable=%t for case %d, lookahead %+v, clauses %v; got %t", c.sat, i, lookahead, c.clauses, sat)
+			}
+			if sat {
+				validSolution := SatTest(c.n, c.clauses, solution)
+				if !validSolution {
+					t.Errorf("expected a valid solution for case %d, lookahead %+v, n=%d, clauses=%v; did not get one (solution=%v)", i, lookahead, c.n, c.clauses, solution)
+				}
 			}
 		}
-
 	}
 }
 
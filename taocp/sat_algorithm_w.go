@@ -0,0 +1,324 @@
+package taocp
+
+import (
+	"log"
+	"math/rand"
+	"slices"
+)
+
+// SatAlgorithmWOptions provides the parameters of the local search
+// Algorithms W and P
+type SatAlgorithmWOptions struct {
+	// Probability of a random flip when every literal of the chosen clause
+	// breaks some other clause (Algorithm W only) - default 0.567
+	Noise float64
+
+	// Maximum number of flips before starting again from a new random
+	// assignment - default 1000000
+	MaxFlips int
+
+	// Maximum number of random assignments to try - default 10
+	MaxTries int
+
+	// Seed for the pseudorandom generator - default 0
+	Seed int64
+}
+
+// NewSatAlgorithmWOptions creates a new SatAlgorithmWOptions
+// struct with default values
+func NewSatAlgorithmWOptions() *SatAlgorithmWOptions {
+	return &SatAlgorithmWOptions{
+		Noise:    0.567,
+		MaxFlips: 1000000,
+		MaxTries: 10,
+		Seed:     0,
+	}
+}
+
+// SatAlgorithmW implements Algorithm W (7.2.2.2), satisfiability by WalkSAT.
+// Starting from a random assignment, it repeatedly chooses a random false
+// clause and flips one of its literals: a literal whose flip makes no other
+// clause false if there is one; otherwise with probability optionsW.Noise a
+// random literal, else a literal which makes the fewest clauses false.
+//
+// Algorithm W is not complete. It returns false if no satisfying assignment
+// is found within optionsW.MaxTries random assignments of optionsW.MaxFlips
+// flips each, which does not imply that the clauses are unsatisfiable.
+// stats.Flips and stats.Restarts count the flips and the new random
+// assignments after the first, and are reported to stats.Telemetry.
+//
+// options is accepted for the same signature as the other algorithms, but
+// is ignored: a local search derives no clauses for options.Proof and makes
+// no two-way branches for options.Branching.
+//
+// Arguments:
+// n        -- number of strictly distinct literals
+// clauses  -- list of clauses to satisfy
+// stats    -- SAT processing statistics
+// options  -- runtime options, ignored
+// optionsW -- local search parameters, or nil for the defaults
+func SatAlgorithmW(n int, clauses SatClauses,
+	stats *SatStats, options *SatOptions, optionsW *SatAlgorithmWOptions) (bool, []int) {
+
+	return satWalk(n, clauses, stats, optionsW, false)
+}
+
+// SatAlgorithmP implements satisfiability by Papadimitriou's focused random
+// walk (7.2.2.2), the special case of Algorithm W which always flips a random
+// literal of a random false clause. optionsW.Noise is ignored, as is options;
+// otherwise the arguments and results are the same as SatAlgorithmW.
+func SatAlgorithmP(n int, clauses SatClauses,
+	stats *SatStats, options *SatOptions, optionsW *SatAlgorithmWOptions) (bool, []int) {
+
+	return satWalk(n, clauses, stats, optionsW, true)
+}
+
+// satWalk implements Algorithms W and P. If focused is true, every flip is a
+// random literal of the chosen clause.
+func satWalk(n int, clauses SatClauses, stats *SatStats,
+	optionsW *SatAlgorithmWOptions, focused bool) (bool, []int) {
+
+	if optionsW == nil {
+		optionsW = NewSatAlgorithmWOptions()
+	}
+
+	var (
+		m        int        // total number of clauses
+		lits     [][]int    // literals of each clause
+		occ      [][]int    // clauses containing each literal
+		x        []int      // value of each variable
+		numTrue  []int      // number of true literals in each clause
+		keyTrue  []int      // XOR of the variables of the true literals in each clause
+		cost     []int      // number of clauses which flipping each variable makes false
+		falses   []int      // the clauses which are false
+		where    []int      // location of each clause in falses, or -1
+		choices  []int      // literals of the chosen clause with minimum cost
+		rng      *rand.Rand // pseudorandom generator
+		debug    bool       // debugging is enabled
+		progress bool       // progress tracking is enabled
+	)
+
+	if stats != nil {
+		stats.Theta = stats.Delta
+		debug = stats.Debug
+		progress = stats.Progress
+	}
+
+	if focused {
+		stats.telemetryStart("P")
+	} else {
+		stats.telemetryStart("W")
+	}
+	defer stats.telemetryDone()
+
+	//
+	// W1. [Initialize.]
+	//
+
+	// Compute the literals, removing duplicates and tautologies
+	occ = make([][]int, 2*n+2)
+	for _, clause := range clauses {
+		var c []int
+		for _, k := range clause {
+			var l int
+			if k >= 0 {
+				l = 2 * k
+			} else {
+				l = -2*k + 1
+			}
+			if slices.Contains(c, l^1) {
+				c = nil
+				break
+			}
+			if !slices.Contains(c, l) {
+				c = append(c, l)
+			}
+		}
+		if c == nil {
+			if len(clause) == 0 {
+				// The empty clause can't be satisfied
+				return false, nil
+			}
+			continue
+		}
+		for _, l := range c {
+			occ[l] = append(occ[l], len(lits))
+		}
+		lits = append(lits, c)
+	}
+
+	m = len(lits)
+	x = make([]int, n+1)
+	numTrue = make([]int, m)
+	keyTrue = make([]int, m)
+	cost = make([]int, n+1)
+	where = make([]int, m)
+	rng = rand.New(rand.NewSource(optionsW.Seed))
+
+	// isTrue returns true if literal l is true
+	isTrue := func(l int) bool {
+		return x[l>>1] == l&1^1
+	}
+
+	// makeFalse inserts clause c into the false clauses
+	makeFalse := func(c int) {
+		where[c] = len(falses)
+		falses = append(falses, c)
+	}
+
+	// makeTrue deletes clause c from the false clauses
+	makeTrue := func(c int) {
+		last := falses[len(falses)-1]
+		falses[where[c]] = last
+		where[last] = where[c]
+		falses = falses[:len(falses)-1]
+		where[c] = -1
+	}
+
+	// flip complements the value of variable k
+	flip := func(k int) {
+		l := 2*k + x[k] // becomes true
+		lp := l ^ 1     // becomes false
+
+		for _, c := range occ[lp] {
+			numTrue[c]--
+			keyTrue[c] ^= k
+			if numTrue[c] == 0 {
+				makeFalse(c)
+				cost[k]--
+			} else if numTrue[c] == 1 {
+				cost[keyTrue[c]]++
+			}
+		}
+		for _, c := range occ[l] {
+			numTrue[c]++
+			keyTrue[c] ^= k
+			if numTrue[c] == 1 {
+				makeTrue(c)
+				cost[k]++
+			} else if numTrue[c] == 2 {
+				cost[keyTrue[c]^k]--
+			}
+		}
+
+		x[k] ^= 1
+	}
+
+	// lvisit prepares the solution
+	lvisit := func() []int {
+		solution := slices.Clone(x[1:])
+		if debug {
+			log.Printf("visit solution=%v", solution)
+		}
+
+		return solution
+	}
+
+	for try := 0; try < optionsW.MaxTries; try++ {
+
+		if try > 0 && stats != nil {
+			stats.Restarts++
+		}
+
+		// Choose a random assignment and compute the clause tables
+		for k := 1; k <= n; k++ {
+			x[k] = rng.Intn(2)
+			cost[k] = 0
+		}
+		falses = falses[:0]
+		for c, clause := range lits {
+			numTrue[c] = 0
+			keyTrue[c] = 0
+			where[c] = -1
+			for _, l := range clause {
+				if isTrue(l) {
+					numTrue[c]++
+					keyTrue[c] ^= l >> 1
+				}
+			}
+			if numTrue[c] == 0 {
+				makeFalse(c)
+			} else if numTrue[c] == 1 {
+				cost[keyTrue[c]]++
+			}
+		}
+
+		if debug {
+			log.Printf("W1. [Initialize.] try=%d, %d false clauses", try, len(falses))
+		}
+
+		for j := 0; ; j++ {
+
+			//
+			// W2. [Done?]
+			//
+			if len(falses) == 0 {
+				if debug {
+					log.Printf("W2. [Success!] after %d flips", j)
+				}
+				if stats != nil {
+					stats.Solutions++
+				}
+				return true, lvisit()
+			}
+			if j == optionsW.MaxFlips {
+				break
+			}
+
+			//
+			// W3. [Choose c.]
+			//
+			c := falses[rng.Intn(len(falses))]
+
+			//
+			// W4. [Choose l.]
+			//
+			var l int
+			if focused {
+				l = lits[c][rng.Intn(len(lits[c]))]
+			} else {
+				// Find the literals of minimum cost
+				choices = choices[:0]
+				minCost := -1
+				for _, lp := range lits[c] {
+					if cp := cost[lp>>1]; minCost == -1 || cp < minCost {
+						minCost = cp
+						choices = append(choices[:0], lp)
+					} else if cp == minCost {
+						choices = append(choices, lp)
+					}
+				}
+
+				if minCost > 0 && rng.Float64() < optionsW.Noise {
+					l = lits[c][rng.Intn(len(lits[c]))]
+				} else {
+					l = choices[rng.Intn(len(choices))]
+				}
+			}
+
+			//
+			// W5. [Flip l.]
+			//
+			if debug {
+				log.Printf("W5. [Flip l.] j=%d, c=%d, l=%d, %d false clauses", j, c, l, len(falses))
+			}
+
+			flip(l >> 1)
+
+			if stats != nil {
+				stats.Flips++
+				stats.telemetryTick()
+				if progress && stats.Flips >= stats.Theta {
+					log.Printf("Flips=%d, restarts=%d, %d false clauses", stats.Flips, stats.Restarts, len(falses))
+					stats.Theta += stats.Delta
+				}
+			}
+		}
+	}
+
+	if debug {
+		log.Printf("W2. [Failure.] no solution after %d tries", optionsW.MaxTries)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,159 @@
+package taocp
+
+import (
+	"fmt"
+	"log"
+	"testing"
+)
+
+func TestSatAlgorithmW(t *testing.T) {
+
+	log.SetFlags(log.Flags() &^ (log.Ldate | log.Ltime))
+
+	cases := []struct {
+		n       int        // number of strictly distinct literals
+		clauses SatClauses // satisfiable clauses
+	}{
+		{0, SatClauses{}},
+		{1, SatClauses{{1}}},
+		{1, SatClauses{{-1}}},
+		{1, SatClauses{{1, -1}}},
+		{3, SatClauses{{1}, {2}, {-3}}},
+		{5, SatClauses{{1, -2}, {2, 2}, {-1, 3}, {2, 4}, {-4, 5}}},
+		{3, SatClauses{{1, -2}, {2, 3}, {-1, -3}, {-1, -2, 3}}},
+		{4, ClausesRPrime},
+		{8, SatWaerdan(3, 3, 8)},
+		{100, SatRand(2, 80, 100, 0)},
+		{1000, SatRand(2, 1000, 1000, 0)},
+		{100, SatRand(3, 420, 100, 0)},
+		{1000, SatRand(3, 3500, 1000, 0)},
+		{2000, SatRand(3, 8000, 2000, 1)},
+	}
+
+	for i, c := range cases {
+
+		for _, algorithm := range []string{"W", "P"} {
+
+			stats := SatStats{}
+			options := SatOptions{}
+			optionsW := NewSatAlgorithmWOptions()
+
+			var sat bool
+			var solution []int
+			if algorithm == "W" {
+				sat, solution = SatAlgorithmW(c.n, c.clauses, &stats, &options, optionsW)
+			} else {
+				if len(c.clauses) > 3000 {
+					// Beyond the reach of the focused random walk
+					continue
+				}
+				sat, solution = SatAlgorithmP(c.n, c.clauses, &stats, &options, optionsW)
+			}
+
+			if !sat {
+				t.Errorf("For case #%d, expected Algorithm %s to find a solution; got none after %d flips", i, algorithm, stats.Flips)
+			} else if !SatTest(c.n, c.clauses, solution) {
+				t.Errorf("For case #%d, expected a valid solution from Algorithm %s; got %v", i, algorithm, solution)
+			}
+		}
+	}
+}
+
+func TestSatAlgorithmWUnsatisfiable(t *testing.T) {
+
+	cases := []struct {
+		n       int        // number of strictly distinct literals
+		clauses SatClauses // unsatisfiable clauses
+	}{
+		{1, SatClauses{{}}},
+		{1, SatClauses{{1}, {-1}}},
+		{4, ClausesR},
+		{9, ClausesWaerden339},
+	}
+
+	for i, c := range cases {
+
+		stats := SatStats{}
+		optionsW := &SatAlgorithmWOptions{Noise: 0.5, MaxFlips: 100, MaxTries: 3, Seed: 1}
+
+		sat, solution := SatAlgorithmW(c.n, c.clauses, &stats, &SatOptions{}, optionsW)
+
+		if sat || solution != nil {
+			t.Errorf("For case #%d, expected no solution; got %v", i, solution)
+		}
+		if len(c.clauses[0]) == 0 {
+			continue
+		}
+		if stats.Flips != 300 {
+			t.Errorf("For case #%d, expected 300 flips; got %d", i, stats.Flips)
+		}
+		if stats.Restarts != 2 {
+			t.Errorf("For case #%d, expected 2 restarts; got %d", i, stats.Restarts)
+		}
+	}
+}
+
+func TestSatAlgorithmWSeed(t *testing.T) {
+
+	clauses := SatRand(3, 400, 100, 2)
+
+	var first []int
+	for i := 0; i < 3; i++ {
+		optionsW := NewSatAlgorithmWOptions()
+		optionsW.Seed = 7
+
+		sat, solution := SatAlgorithmW(100, clauses, nil, &SatOptions{}, optionsW)
+		if !sat {
+			t.Fatalf("expected a solution with seed 7; got none")
+		}
+		if first == nil {
+			first = solution
+		} else if fmt.Sprint(solution) != fmt.Sprint(first) {
+			t.Errorf("expected the same solution for the same seed; got %v and %v", first, solution)
+		}
+	}
+}
+
+func BenchmarkSatAlgorithmWSatRandom(b *testing.B) {
+
+	cases := []struct {
+		k int // clause length (k-SAT)
+		m int // number of clauses
+		n int // number of strictly distinct literals
+	}{
+		{3, 400, 100},
+		{3, 4000, 1000},
+		{3, 40000, 10000},
+	}
+
+	for _, c := range cases {
+
+		clauses := SatRand(c.k, c.m, c.n, 0)
+
+		for _, algorithm := range []string{"W", "P"} {
+
+			firstExecution := true
+
+			b.Run(fmt.Sprintf("%s,k=%d,m=%d,n=%d", algorithm, c.k, c.m, c.n), func(b *testing.B) {
+
+				for i := 0; i < b.N; i++ {
+					stats := SatStats{}
+					options := SatOptions{}
+					optionsW := NewSatAlgorithmWOptions()
+
+					var sat bool
+					if algorithm == "W" {
+						sat, _ = SatAlgorithmW(c.n, clauses, &stats, &options, optionsW)
+					} else {
+						sat, _ = SatAlgorithmP(c.n, clauses, &stats, &options, optionsW)
+					}
+
+					if firstExecution {
+						b.Logf("SAT=%t, m=%d, n=%d, flips=%d, restarts=%d", sat, c.m, c.n, stats.Flips, stats.Restarts)
+						firstExecution = false
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package taocp
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// SatBranching chooses the literal of a two-way branch in Algorithms B and D,
+// given in SatOptions.Branching. When it is nil, the default, they branch as
+// always: Algorithm B sets x_d at depth d, and Algorithm D sets the variable
+// at the head of its active ring, each trying first the value suggested by
+// the watch lists.
+type SatBranching interface {
+	// Branch returns the literal k or -k to make true first, for a variable
+	// k of candidates, which are unset. value[k] is the value of variable k:
+	// 1 if true, 0 if false, or -1 if unset.
+	Branch(clauses SatClauses, value []int, candidates []int) int
+}
+
+// NewSatBranching returns the branching heuristic with the given name:
+// "default" (nil), "moms", "jw", "dlcs" or "random", which uses seed
+func NewSatBranching(name string, seed int64) (SatBranching, error) {
+	switch name {
+	case "default", "":
+		return nil, nil
+	case "moms":
+		return SatBranchMOMS{}, nil
+	case "jw":
+		return SatBranchJeroslowWang{}, nil
+	case "dlcs":
+		return SatBranchDLCS{}, nil
+	case "random":
+		return NewSatBranchRandom(seed), nil
+	}
+	return nil, fmt.Errorf("unknown branching heuristic '%s'", name)
+}
+
+// satBranchCounts calls count(l, size) for each unset literal l of each
+// clause which isn't yet satisfied, where size is its number of unset
+// literals. Literal k is at index 2k and ~k at 2k+1.
+func satBranchCounts(clauses SatClauses, value []int, count func(l, size int)) {
+	for _, clause := range clauses {
+		size := 0
+		satisfied := false
+		for _, l := range clause {
+			switch v := value[max(l, -l)]; {
+			case v < 0:
+				size++
+			case v == 1 && l > 0 || v == 0 && l < 0:
+				satisfied = true
+			}
+		}
+		if satisfied {
+			continue
+		}
+		for _, l := range clause {
+			if value[max(l, -l)] < 0 {
+				if l > 0 {
+					count(2*l, size)
+				} else {
+					count(-2*l+1, size)
+				}
+			}
+		}
+	}
+}
+
+// satBranchBest returns the literal of the candidate with the greatest score,
+// whose polarity is that with the greater weight; ties go to the first
+// candidate and to the positive literal
+func satBranchBest(candidates []int, score func(k int) float64, weight []float64) int {
+	best := candidates[0]
+	for _, k := range candidates[1:] {
+		if score(k) > score(best) {
+			best = k
+		}
+	}
+	if weight[2*best+1] > weight[2*best] {
+		return -best
+	}
+	return best
+}
+
+// SatBranchMOMS chooses the variable with the Maximum number of Occurrences
+// in the clauses of Minimum Size, scoring each variable by
+// (c(x) + c(~x)) 2^10 + c(x) c(~x), where c(l) counts the shortest clauses
+// containing l, so that both polarities are favored
+type SatBranchMOMS struct{}
+
+// Branch implements SatBranching
+func (SatBranchMOMS) Branch(clauses SatClauses, value []int, candidates []int) int {
+	minimum := 0
+	satBranchCounts(clauses, value, func(l, size int) {
+		if minimum == 0 || size < minimum {
+			minimum = size
+		}
+	})
+
+	c := make([]float64, len(value)*2)
+	satBranchCounts(clauses, value, func(l, size int) {
+		if size == minimum {
+			c[l]++
+		}
+	})
+
+	return satBranchBest(candidates, func(k int) float64 {
+		return (c[2*k]+c[2*k+1])*1024 + c[2*k]*c[2*k+1]
+	}, c)
+}
+
+// SatBranchJeroslowWang chooses the variable by the two-sided Jeroslow–Wang
+// rule, which weights each clause containing a literal by 2^-size: the
+// variable x with the greatest J(x) + J(~x), trying first the literal with
+// the greater J
+type SatBranchJeroslowWang struct{}
+
+// Branch implements SatBranching
+func (SatBranchJeroslowWang) Branch(clauses SatClauses, value []int, candidates []int) int {
+	j := make([]float64, len(value)*2)
+	satBranchCounts(clauses, value, func(l, size int) {
+		j[l] += 1 / float64(uint64(1)<<min(size, 63))
+	})
+
+	return satBranchBest(candidates, func(k int) float64 {
+		return j[2*k] + j[2*k+1]
+	}, j)
+}
+
+// SatBranchDLCS chooses the variable by Dynamic Largest Combined Sum: the
+// variable x occurring most often in the clauses which aren't satisfied,
+// trying first the literal which occurs more often
+type SatBranchDLCS struct{}
+
+// Branch implements SatBranching
+func (SatBranchDLCS) Branch(clauses SatClauses, value []int, candidates []int) int {
+	c := make([]float64, len(value)*2)
+	satBranchCounts(clauses, value, func(l, size int) {
+		c[l]++
+	})
+
+	return satBranchBest(candidates, func(k int) float64 {
+		return c[2*k] + c[2*k+1]
+	}, c)
+}
+
+// SatBranchRandom chooses a random candidate and value, from a seeded
+// pseudorandom generator, so a run can be repeated with a new
+// SatBranchRandom of the same seed
+type SatBranchRandom struct {
+	rng *rand.Rand
+}
+
+// NewSatBranchRandom returns a random branching heuristic with the given seed
+func NewSatBranchRandom(seed int64) *SatBranchRandom {
+	return &SatBranchRandom{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Branch implements SatBranching
+func (b *SatBranchRandom) Branch(clauses SatClauses, value []int, candidates []int) int {
+	k := candidates[b.rng.Intn(len(candidates))]
+	if b.rng.Intn(2) == 0 {
+		return -k
+	}
+	return k
+}
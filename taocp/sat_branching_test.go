@@ -0,0 +1,137 @@
+package taocp
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// satBranchings returns the branching heuristics to test, by name
+func satBranchings() map[string]SatBranching {
+	return map[string]SatBranching{
+		"default": nil,
+		"moms":    SatBranchMOMS{},
+		"jw":      SatBranchJeroslowWang{},
+		"dlcs":    SatBranchDLCS{},
+		"random":  NewSatBranchRandom(1),
+	}
+}
+
+func TestSatBranching(t *testing.T) {
+
+	cases := []struct {
+		n       int        // number of strictly distinct literals
+		sat     bool       // is satisfiable
+		clauses SatClauses // clauses to satisfy
+	}{
+		{3, true, SatClauses{{1, -2}, {2, 3}, {-1, -3}, {-1, -2, 3}}},
+		{3, false, SatClauses{{1, -2}, {2, 3}, {-1, -3}, {-1, -2, 3}, {1, 2, -3}}},
+		{4, true, ClausesRPrime},
+		{4, false, ClausesR},
+		{8, true, SatWaerdan(3, 3, 8)},
+		{9, false, ClausesWaerden339},
+	}
+	for seed := int64(0); seed < 20; seed++ {
+		clauses := SatRand(3, 40+int(seed), 12, seed)
+		sat, _ := SatAlgorithmD(12, clauses, nil, nil)
+		cases = append(cases, struct {
+			n       int
+			sat     bool
+			clauses SatClauses
+		}{12, sat, clauses})
+	}
+
+	algorithms := map[string]func(int, SatClauses, *SatStats, *SatOptions) (bool, []int){
+		"B": SatAlgorithmB,
+		"D": SatAlgorithmD,
+	}
+
+	for name, branching := range satBranchings() {
+		for algorithm, solve := range algorithms {
+			for i, c := range cases {
+				options := SatOptions{Branching: branching}
+				sat, solution := solve(c.n, c.clauses, &SatStats{}, &options)
+				if sat != c.sat {
+					t.Errorf("For %s with %s, case #%d, expected sat=%t; got %t", algorithm, name, i, c.sat, sat)
+					continue
+				}
+				if sat && !SatTest(c.n, c.clauses, solution) {
+					t.Errorf("For %s with %s, case #%d, expected a solution; got %v", algorithm, name, i, solution)
+				}
+			}
+		}
+	}
+}
+
+func TestSatBranchingAll(t *testing.T) {
+
+	// Random clauses with random projections
+	rng := rand.New(rand.NewSource(0))
+	for seed := int64(0); seed < 20; seed++ {
+		n := 10
+		clauses := SatRand(3, 20+int(seed), n, seed)
+		projection := rng.Perm(n)[:rng.Intn(n)]
+		for i := range projection {
+			projection[i]++
+		}
+
+		for name, branching := range satBranchings() {
+			options := SatOptions{Branching: branching}
+			solutions := SatAlgorithmBAll(n, clauses, nil, &options, projection)
+			testSatAll(t, fmt.Sprintf("B with %s, seed %d", name, seed), n, clauses, projection, solutions)
+			solutions = SatAlgorithmDAll(n, clauses, nil, &options, projection)
+			testSatAll(t, fmt.Sprintf("D with %s, seed %d", name, seed), n, clauses, projection, solutions)
+		}
+	}
+}
+
+// TestSatBranchingNodes checks that the default branching is unchanged, by
+// the size of its search trees, and compares those of the heuristics
+func TestSatBranchingNodes(t *testing.T) {
+
+	langford, options := SatLangford(6)
+
+	cases := []struct {
+		name    string     // name of the clauses
+		n       int        // number of strictly distinct literals
+		clauses SatClauses // clauses to satisfy, which are unsatisfiable
+		nodesB  int        // nodes of Algorithm B with the default branching
+		nodesD  int        // nodes of Algorithm D with the default branching
+	}{
+		{"waerden(3,3;9)", 9, ClausesWaerden339, 158, 37},
+		{"langford(6)", len(options), langford, 8164, 873},
+	}
+
+	for _, c := range cases {
+		for name, branching := range satBranchings() {
+			statsB, statsD := SatStats{}, SatStats{}
+			satB, _ := SatAlgorithmB(c.n, c.clauses, &statsB, &SatOptions{Branching: branching})
+			satD, _ := SatAlgorithmD(c.n, c.clauses, &statsD, &SatOptions{Branching: branching})
+			if satB || satD {
+				t.Errorf("For %s with %s, expected unsatisfiable; got B=%t, D=%t", c.name, name, satB, satD)
+			}
+			if branching == nil && (statsB.Nodes != c.nodesB || statsD.Nodes != c.nodesD) {
+				t.Errorf("For %s with %s, expected nodes B=%d, D=%d; got B=%d, D=%d",
+					c.name, name, c.nodesB, c.nodesD, statsB.Nodes, statsD.Nodes)
+			}
+			t.Logf("%s with %s: B=%d, D=%d nodes", c.name, name, statsB.Nodes, statsD.Nodes)
+		}
+	}
+}
+
+func TestNewSatBranching(t *testing.T) {
+
+	for _, name := range []string{"default", "moms", "jw", "dlcs", "random"} {
+		branching, err := NewSatBranching(name, 0)
+		if err != nil {
+			t.Errorf("For %s, expected no error; got %v", name, err)
+		}
+		if (branching == nil) != (name == "default") {
+			t.Errorf("For %s, expected nil=%t; got %v", name, name == "default", branching)
+		}
+	}
+
+	if _, err := NewSatBranching("vsids", 0); err == nil {
+		t.Errorf("For vsids, expected an error")
+	}
+}
@@ -0,0 +1,473 @@
+package taocp
+
+import (
+	"math/bits"
+	"slices"
+)
+
+// The cardinality encoders generate clauses which ensure that the number of
+// true literals of clause, x_1 + ... + x_n, is at least lo and at most hi;
+// =r is the case lo = hi = r, S_(<= r) the case lo = 0, hi = r, and S_(>= r)
+// the case lo = r, hi = n. Like SatMaxR, they return the new clauses and the
+// number of auxiliary variables created, (startV,...,startV+numV-1). If no
+// assignment can satisfy the bounds, the clauses are a contradiction.
+
+// satUnsatisfiable returns the contradictory clauses {startV} and {~startV},
+// and the one auxiliary variable they use
+func satUnsatisfiable(startV int) (SatClauses, int) {
+	return SatClauses{{startV}, {-startV}}, 1
+}
+
+// SatSequentialCounter generates clauses for lo <= x_1 + ... + x_n <= hi
+// using Sinz's sequential counter: SatMaxR for the upper bound, and SatMaxR
+// applied to the complemented literals for the lower bound, since
+// x_1 + ... + x_n >= lo if and only if ~x_1 + ... + ~x_n <= n - lo.
+func SatSequentialCounter(lo, hi int, clause SatClause, startV int) (newclauses SatClauses, numV int) {
+	n := len(clause)
+
+	if lo > n || hi < 0 || lo > hi {
+		return satUnsatisfiable(startV)
+	}
+
+	// at most hi
+	switch {
+	case hi == 0:
+		for _, l := range clause {
+			newclauses = append(newclauses, SatClause{-l})
+		}
+	case hi < n:
+		newclauses, numV = SatMaxR(hi, clause, startV)
+	}
+
+	// at least lo
+	switch {
+	case lo == n:
+		for _, l := range clause {
+			newclauses = append(newclauses, SatClause{l})
+		}
+	case lo == 1:
+		newclauses = append(newclauses, append(SatClause{}, clause...))
+	case lo > 1:
+		negated := make(SatClause, n)
+		for i, l := range clause {
+			negated[i] = -l
+		}
+		clauses, numVLo := SatMaxR(n-lo, negated, startV+numV)
+		newclauses = append(newclauses, clauses...)
+		numV += numVLo
+	}
+
+	return newclauses, numV
+}
+
+// SatTotalizer generates clauses for lo <= x_1 + ... + x_n <= hi using the
+// totalizer of Bailleux and Boufkhad (7.2.2.2): a complete binary tree whose
+// leaves are the literals, with auxiliary variables b_1..b_t at each internal
+// node which count the t leaves below it in unary, b_j true if and only if at
+// least j of them are true. Counts larger than max(lo, hi+1) aren't needed,
+// so each node keeps at most that many.
+func SatTotalizer(lo, hi int, clause SatClause, startV int) (newclauses SatClauses, numV int) {
+	n := len(clause)
+
+	if lo > n || hi < 0 || lo > hi {
+		return satUnsatisfiable(startV)
+	}
+	if lo <= 0 && hi >= n {
+		return nil, 0
+	}
+
+	k := min(n, max(lo, hi+1))
+
+	// count returns the unary counts of the literals of leaves, b_1..b_t
+	var count func(leaves SatClause) []int
+	count = func(leaves SatClause) []int {
+		if len(leaves) == 1 {
+			return []int{leaves[0]}
+		}
+
+		a := count(leaves[:len(leaves)/2])
+		b := count(leaves[len(leaves)/2:])
+
+		t := min(len(a)+len(b), k)
+		r := make([]int, t)
+		for j := range r {
+			r[j] = startV + numV
+			numV++
+		}
+
+		// a_i and b_j imply r_(i+j), with a_0 = b_0 = true
+		for i := 0; i <= len(a); i++ {
+			for j := 0; j <= len(b); j++ {
+				if i+j == 0 {
+					continue
+				}
+				var c SatClause
+				if i > 0 {
+					c = append(c, -a[i-1])
+				}
+				if j > 0 {
+					c = append(c, -b[j-1])
+				}
+				newclauses = append(newclauses, append(c, r[min(i+j, t)-1]))
+			}
+		}
+
+		// ~a_(i+1) and ~b_(j+1) imply ~r_(i+j+1), with a_(|a|+1) = false
+		for i := 0; i <= len(a); i++ {
+			for j := 0; j <= len(b); j++ {
+				if i+j+1 > t {
+					continue
+				}
+				var c SatClause
+				if i < len(a) {
+					c = append(c, a[i])
+				}
+				if j < len(b) {
+					c = append(c, b[j])
+				}
+				newclauses = append(newclauses, append(c, -r[i+j]))
+			}
+		}
+
+		return r
+	}
+
+	r := count(clause)
+
+	if lo > 0 {
+		newclauses = append(newclauses, SatClause{r[lo-1]})
+	}
+	if hi < n {
+		newclauses = append(newclauses, SatClause{-r[hi]})
+	}
+
+	return newclauses, numV
+}
+
+// satWires builds a circuit of gates whose inputs are literals, creating
+// auxiliary variables and clauses only for the gates which are needed.
+// Wire 0 is the constant false, wires 1..n are the literals, and each gate
+// output is a new wire. A wire which must imply its value ("up") needs the
+// clauses making it false unless its inputs justify it; a wire which must be
+// implied by its inputs ("down") needs the clauses making it true when they
+// do.
+type satWires struct {
+	lits  []int     // literal of each wire, once assigned
+	gates []satGate // the gates, in order of creation
+	up    []bool    // the wire must imply its inputs
+	down  []bool    // the wire must be implied by its inputs
+}
+
+// satGate is an OR or AND gate of two wires
+type satGate struct {
+	out  int  // output wire
+	a, b int  // input wires
+	and  bool // AND, otherwise OR
+}
+
+// newSatWires returns a circuit whose inputs are the literals of clause
+func newSatWires(clause SatClause) *satWires {
+	w := &satWires{lits: append([]int{0}, clause...)}
+	w.up = make([]bool, len(w.lits))
+	w.down = make([]bool, len(w.lits))
+	return w
+}
+
+// gate returns the output wire of a gate with inputs a and b, simplifying
+// gates with a constant false input
+func (w *satWires) gate(a, b int, and bool) int {
+	if a == 0 || b == 0 {
+		if and {
+			return 0
+		}
+		return a + b
+	}
+	out := len(w.lits)
+	w.lits = append(w.lits, 0)
+	w.up = append(w.up, false)
+	w.down = append(w.down, false)
+	w.gates = append(w.gates, satGate{out: out, a: a, b: b, and: and})
+	return out
+}
+
+// clauses returns the clauses of the gates needed by the wires marked up
+// or down, numbering their outputs from startV
+func (w *satWires) clauses(startV int) (newclauses SatClauses, numV int) {
+
+	// Propagate the needs from the outputs back to the inputs
+	for i := len(w.gates) - 1; i >= 0; i-- {
+		g := w.gates[i]
+		if w.up[g.out] {
+			w.up[g.a], w.up[g.b] = true, true
+		}
+		if w.down[g.out] {
+			w.down[g.a], w.down[g.b] = true, true
+		}
+	}
+
+	for _, g := range w.gates {
+		if !w.up[g.out] && !w.down[g.out] {
+			continue
+		}
+		w.lits[g.out] = startV + numV
+		numV++
+
+		c, a, b := w.lits[g.out], w.lits[g.a], w.lits[g.b]
+		if g.and {
+			if w.up[g.out] {
+				newclauses = append(newclauses, SatClause{-c, a}, SatClause{-c, b})
+			}
+			if w.down[g.out] {
+				newclauses = append(newclauses, SatClause{-a, -b, c})
+			}
+		} else {
+			if w.up[g.out] {
+				newclauses = append(newclauses, SatClause{-c, a, b})
+			}
+			if w.down[g.out] {
+				newclauses = append(newclauses, SatClause{-a, c}, SatClause{-b, c})
+			}
+		}
+	}
+
+	return newclauses, numV
+}
+
+// SatCardinalityNetwork generates clauses for lo <= x_1 + ... + x_n <= hi
+// using a cardinality network: Batcher's odd-even merge sort of the literals,
+// padded with false to a power of two, by comparators which output the OR
+// and the AND of their inputs. The j-th output of the sort is true if and
+// only if at least j of the literals are true. Following Asín, Nieuwenhuis,
+// Oliveras and Rodríguez-Carbonell, only the comparators on which the
+// outputs lo and hi+1 depend are encoded, each in the one direction needed.
+func SatCardinalityNetwork(lo, hi int, clause SatClause, startV int) (newclauses SatClauses, numV int) {
+	n := len(clause)
+
+	if lo > n || hi < 0 || lo > hi {
+		return satUnsatisfiable(startV)
+	}
+	if lo <= 0 && hi >= n {
+		return nil, 0
+	}
+
+	w := newSatWires(clause)
+
+	// Wires being sorted, largest first
+	size := 1 << bits.Len(uint(n-1))
+	wire := make([]int, size)
+	for i := 0; i < n; i++ {
+		wire[i] = i + 1
+	}
+
+	compare := func(i, j int) {
+		wire[i], wire[j] = w.gate(wire[i], wire[j], false), w.gate(wire[i], wire[j], true)
+	}
+
+	// merge merges the sorted subsequences of the wires lo, lo+r, lo+2r, ...
+	// among the m wires beginning at lo
+	var merge func(lo, m, r int)
+	merge = func(lo, m, r int) {
+		step := 2 * r
+		if step < m {
+			merge(lo, m, step)
+			merge(lo+r, m, step)
+			for i := lo + r; i+r < lo+m; i += step {
+				compare(i, i+r)
+			}
+		} else {
+			compare(lo, lo+r)
+		}
+	}
+
+	var sort func(lo, m int)
+	sort = func(lo, m int) {
+		if m > 1 {
+			sort(lo, m/2)
+			sort(lo+m/2, m/2)
+			merge(lo, m, 1)
+		}
+	}
+
+	sort(0, size)
+
+	if lo > 0 {
+		w.up[wire[lo-1]] = true
+	}
+	if hi < n {
+		w.down[wire[hi]] = true
+	}
+
+	newclauses, numV = w.clauses(startV)
+
+	if lo > 0 {
+		newclauses = append(newclauses, SatClause{w.lits[wire[lo-1]]})
+	}
+	if hi < n {
+		newclauses = append(newclauses, SatClause{-w.lits[wire[hi]]})
+	}
+
+	return newclauses, numV
+}
+
+// SatBinaryAdder generates clauses for lo <= x_1 + ... + x_n <= hi by
+// computing the sum in binary with full and half adders, as described in
+// 7.2.2.2, and comparing it with lo and hi. It is the special case of
+// SatPseudoBoolean with every weight 1.
+func SatBinaryAdder(lo, hi int, clause SatClause, startV int) (newclauses SatClauses, numV int) {
+	weights := make([]int, len(clause))
+	for i := range weights {
+		weights[i] = 1
+	}
+	return SatPseudoBoolean(lo, hi, weights, clause, startV)
+}
+
+// SatPseudoBoolean generates clauses for the pseudo-Boolean constraint
+// lo <= w_1 x_1 + ... + w_n x_n <= hi, where the weights w_j are integers.
+// A negative weight w x is rewritten as w + |w| ~x, and the weighted sum is
+// then computed in binary: each literal contributes to the columns of the
+// 1 bits of its weight, and each column is reduced by full adders (three bits
+// to a sum bit and a carry to the next column) and half adders (two bits)
+// until one bit remains. The bits of the sum are compared with those of lo
+// and hi.
+func SatPseudoBoolean(lo, hi int, weights []int, clause SatClause, startV int) (newclauses SatClauses, numV int) {
+
+	// Make the weights positive
+	lits := make([]int, 0, len(clause))
+	ws := make([]int, 0, len(clause))
+	total := 0
+	for i, l := range clause {
+		switch w := weights[i]; {
+		case w > 0:
+			lits = append(lits, l)
+			ws = append(ws, w)
+		case w < 0:
+			lits = append(lits, -l)
+			ws = append(ws, -w)
+			lo -= w
+			hi -= w
+		}
+		total += max(weights[i], -weights[i])
+	}
+
+	lo = max(lo, 0)
+	if lo > total || hi < 0 || lo > hi {
+		return satUnsatisfiable(startV)
+	}
+	if lo == 0 && hi >= total {
+		return nil, 0
+	}
+
+	// The literals in each column of the sum
+	m := bits.Len(uint(total))
+	columns := make([][]int, m+1)
+	for i, l := range lits {
+		for b := 0; b < m; b++ {
+			if ws[i]>>b&1 == 1 {
+				columns[b] = append(columns[b], l)
+			}
+		}
+	}
+
+	// add adds the literal equivalent to the given function of the literals,
+	// with the clauses defining it
+	add := func(f func(bits int) bool, inputs ...int) int {
+		z := startV + numV
+		numV++
+
+		// For each assignment of the inputs, a clause forcing z
+		for a := 0; a < 1<<len(inputs); a++ {
+			c := make(SatClause, 0, len(inputs)+1)
+			for i, l := range inputs {
+				if a>>i&1 == 1 {
+					c = append(c, -l)
+				} else {
+					c = append(c, l)
+				}
+			}
+			if f(a) {
+				c = append(c, z)
+			} else {
+				c = append(c, -z)
+			}
+			newclauses = append(newclauses, c)
+		}
+		return z
+	}
+
+	parity := func(a int) bool { return bits.OnesCount(uint(a))&1 == 1 }
+	majority := func(a int) bool { return bits.OnesCount(uint(a)) >= 2 }
+	and := func(a int) bool { return a == 3 }
+
+	// z holds the bits of the sum, 0 for a constant false
+	z := make([]int, m)
+	for b := 0; b < m; b++ {
+		col := columns[b]
+		for len(col) >= 2 {
+			var s, c int
+			if len(col) >= 3 {
+				// full adder
+				x := col[len(col)-3:]
+				s = add(parity, x...)
+				c = add(majority, x...)
+				col = col[:len(col)-3]
+			} else {
+				// half adder
+				x := col[len(col)-2:]
+				s = add(parity, x...)
+				c = add(and, x...)
+				col = col[:len(col)-2]
+			}
+			col = append([]int{s}, col...)
+			columns[b+1] = append(columns[b+1], c)
+		}
+		if len(col) == 1 {
+			z[b] = col[0]
+		}
+	}
+
+	// The sum is at most hi unless, for some bit b which is 0 in hi, bit b
+	// of the sum is 1 and every higher bit which is 1 in hi is 1 in the sum;
+	// a constant false bit z[b] = 0 satisfies the clause forbidding that
+	if hi < total {
+	Hi:
+		for b := 0; b < m; b++ {
+			if hi>>b&1 == 1 {
+				continue
+			}
+			var c SatClause
+			for bp := b; bp < m; bp++ {
+				if bp == b || hi>>bp&1 == 1 {
+					if z[bp] == 0 {
+						continue Hi
+					}
+					if !slices.Contains(c, -z[bp]) {
+						c = append(c, -z[bp])
+					}
+				}
+			}
+			newclauses = append(newclauses, c)
+		}
+	}
+
+	// The sum is at least lo unless, for some bit b which is 1 in lo, bit b
+	// of the sum is 0 and every higher bit which is 0 in lo is 0 in the sum
+	if lo > 0 {
+		for b := 0; b < m; b++ {
+			if lo>>b&1 == 0 {
+				continue
+			}
+			var c SatClause
+			for bp := b; bp < m; bp++ {
+				if (bp == b || lo>>bp&1 == 0) && z[bp] != 0 && !slices.Contains(c, z[bp]) {
+					c = append(c, z[bp])
+				}
+			}
+			if len(c) == 0 {
+				return satUnsatisfiable(startV)
+			}
+			newclauses = append(newclauses, c)
+		}
+	}
+
+	return newclauses, numV
+}
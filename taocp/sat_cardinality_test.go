@@ -0,0 +1,132 @@
+package taocp
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testSatEncoding checks that, for every assignment of the variables of
+// clause, 1..n, the clauses of an encoding are satisfiable if and only if
+// lo <= w_1 l_1 + ... + w_n l_n <= hi, where l_j are the literals of clause,
+// and that the auxiliary variables are numbered from n+1
+func testSatEncoding(t *testing.T, name string, lo, hi int, weights []int,
+	clause SatClause, newclauses SatClauses, numV int) {
+
+	n := len(clause)
+	for _, c := range newclauses {
+		for _, l := range c {
+			if v := max(l, -l); v > n+numV {
+				t.Errorf("For %s, expected auxiliary variables in %d..%d; got %d", name, n+1, n+numV, v)
+				return
+			}
+		}
+	}
+
+	for a := 0; a < 1<<n; a++ {
+		clauses := append(SatClauses{}, newclauses...)
+		sum := 0
+		for j, l := range clause {
+			k := max(l, -l)
+			if a>>(k-1)&1 == 1 {
+				clauses = append(clauses, SatClause{k})
+			} else {
+				clauses = append(clauses, SatClause{-k})
+			}
+			if (a>>(k-1)&1 == 1) == (l > 0) {
+				sum += weights[j]
+			}
+		}
+
+		expected := lo <= sum && sum <= hi
+		got, _ := SatAlgorithmD(n+numV, clauses, nil, nil)
+
+		if got != expected {
+			t.Errorf("For %s with sum %d, expected satisfiable=%t; got %t", name, sum, expected, got)
+			return
+		}
+	}
+}
+
+func TestSatCardinality(t *testing.T) {
+
+	encoders := []struct {
+		name string
+		f    func(lo, hi int, clause SatClause, startV int) (SatClauses, int)
+	}{
+		{"SatSequentialCounter", SatSequentialCounter},
+		{"SatTotalizer", SatTotalizer},
+		{"SatCardinalityNetwork", SatCardinalityNetwork},
+		{"SatBinaryAdder", SatBinaryAdder},
+	}
+
+	for _, e := range encoders {
+		for n := 0; n <= 6; n++ {
+			// Mix positive and negative literals
+			clause := make(SatClause, n)
+			weights := make([]int, n)
+			for k := 1; k <= n; k++ {
+				clause[k-1] = k
+				if k%3 == 0 {
+					clause[k-1] = -k
+				}
+				weights[k-1] = 1
+			}
+
+			for lo := -1; lo <= n+1; lo++ {
+				for hi := lo - 1; hi <= n+1; hi++ {
+					newclauses, numV := e.f(lo, hi, clause, n+1)
+					name := fmt.Sprintf("%s(%d,%d,%v)", e.name, lo, hi, clause)
+					testSatEncoding(t, name, lo, hi, weights, clause, newclauses, numV)
+				}
+			}
+		}
+	}
+}
+
+func TestSatPseudoBoolean(t *testing.T) {
+
+	cases := []struct {
+		weights []int     // weight of each literal
+		clause  SatClause // literals
+	}{
+		{[]int{3}, SatClause{1}},
+		{[]int{1, 2, 3}, SatClause{1, 2, 3}},
+		{[]int{5, 3, 3, 2}, SatClause{1, -2, 3, 4}},
+		{[]int{2, -3, 4, 0, 7}, SatClause{-1, 2, 3, 4, -5}},
+		{[]int{6, 6, 6, 1}, SatClause{1, 2, 3, 4}},
+	}
+
+	for _, c := range cases {
+		total := 0
+		for _, w := range c.weights {
+			total += max(w, -w)
+		}
+
+		for lo := -total - 1; lo <= total+1; lo++ {
+			for _, hi := range []int{lo - 1, lo, lo + 1, lo + 4, total + 1} {
+				newclauses, numV := SatPseudoBoolean(lo, hi, c.weights, c.clause, len(c.clause)+1)
+				name := fmt.Sprintf("SatPseudoBoolean(%d,%d,%v,%v)", lo, hi, c.weights, c.clause)
+				testSatEncoding(t, name, lo, hi, c.weights, c.clause, newclauses, numV)
+			}
+		}
+	}
+}
+
+// TestSatCardinalitySize checks that the encodings of S_(<= 2) on 16 literals
+// are smaller than a clause for each of the 560 subsets of 3 literals
+func TestSatCardinalitySize(t *testing.T) {
+
+	clause := make(SatClause, 16)
+	for k := range clause {
+		clause[k] = k + 1
+	}
+
+	for _, f := range []func(int, int, SatClause, int) (SatClauses, int){
+		SatSequentialCounter, SatTotalizer, SatCardinalityNetwork, SatBinaryAdder,
+	} {
+		newclauses, _ := f(0, 2, clause, 17)
+		if len(newclauses) >= 560 {
+			t.Errorf("expected fewer than 560 clauses; got %d", len(newclauses))
+		}
+	}
+}
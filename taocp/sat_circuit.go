@@ -0,0 +1,271 @@
+package taocp
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+)
+
+// SatCircuit builds SAT clauses from a Boolean circuit by the Tseytin
+// encoding: every gate is given a new variable, with clauses which make it
+// equivalent to the function of its inputs. Structural hashing ensures that
+// a gate with the same function of the same inputs is only encoded once.
+//
+// Signals are literals in the same form as the clauses of SatClauses: a
+// variable k, or its complement -k. Named variables are declared by Var and
+// may be combined by the gate methods. The constants True and False are
+// literals of an extra variable, created when first needed.
+type SatCircuit struct {
+	n         int            // number of variables
+	clauses   SatClauses     // clauses of the gates and assertions
+	variables map[int]string // name of each declared variable
+	names     map[string]int // variable of each declared name
+	gates     map[string]int // variable of each gate, by function and inputs
+	t         int            // variable of the constant True, or 0
+}
+
+// NewSatCircuit returns an empty circuit
+func NewSatCircuit() *SatCircuit {
+	return &SatCircuit{
+		variables: make(map[int]string),
+		names:     make(map[string]int),
+		gates:     make(map[string]int),
+	}
+}
+
+// N returns the number of variables of the circuit
+func (c *SatCircuit) N() int {
+	return c.n
+}
+
+// Clauses returns the clauses of the circuit
+func (c *SatCircuit) Clauses() SatClauses {
+	return c.clauses
+}
+
+// Variables returns the names of the declared variables, in the form
+// returned by SatRead. Gate variables are not named.
+func (c *SatCircuit) Variables() map[int]string {
+	return c.variables
+}
+
+// Var returns the variable with the given name, declaring it if necessary
+func (c *SatCircuit) Var(name string) int {
+	if k, ok := c.names[name]; ok {
+		return k
+	}
+	c.n++
+	c.names[name] = c.n
+	c.variables[c.n] = name
+	return c.n
+}
+
+// True returns the constant true
+func (c *SatCircuit) True() int {
+	if c.t == 0 {
+		c.n++
+		c.t = c.n
+		c.clauses = append(c.clauses, SatClause{c.t})
+	}
+	return c.t
+}
+
+// False returns the constant false
+func (c *SatCircuit) False() int {
+	return -c.True()
+}
+
+// Assert requires at least one of the literals to be true, by adding them as
+// a clause
+func (c *SatCircuit) Assert(lits ...int) {
+	c.clauses = append(c.clauses, slices.Clone(lits))
+}
+
+// Encode adds the clauses of an encoding whose auxiliary variables start at
+// startV, such as SatSequentialCounter or SatPseudoBoolean, calling it with
+// the next variable of the circuit
+func (c *SatCircuit) Encode(encoding func(startV int) (SatClauses, int)) {
+	clauses, numV := encoding(c.n + 1)
+	c.n += numV
+	c.clauses = append(c.clauses, clauses...)
+}
+
+// Not returns the complement of a
+func (c *SatCircuit) Not(a int) int {
+	return -a
+}
+
+// gate returns the variable of the gate with the given key, creating it if
+// necessary by appending the clauses returned by define for its variable
+func (c *SatCircuit) gate(key string, define func(g int) SatClauses) int {
+	if g, ok := c.gates[key]; ok {
+		return g
+	}
+	c.n++
+	g := c.n
+	c.gates[key] = g
+	c.clauses = append(c.clauses, define(g)...)
+	return g
+}
+
+// key returns the structural hashing key of a gate
+func (c *SatCircuit) key(op string, inputs ...int) string {
+	b := []byte(op)
+	for _, l := range inputs {
+		b = append(b, ' ')
+		b = strconv.AppendInt(b, int64(l), 10)
+	}
+	return string(b)
+}
+
+// And returns the conjunction of the literals, which is true if there are
+// none
+func (c *SatCircuit) And(lits ...int) int {
+	// Sort the literals by variable, so duplicates and complements are
+	// adjacent
+	inputs := slices.Clone(lits)
+	slices.SortFunc(inputs, func(a, b int) int {
+		return cmp.Or(cmp.Compare(max(a, -a), max(b, -b)), cmp.Compare(a, b))
+	})
+	inputs = slices.Compact(inputs)
+
+	// Remove constants, and look for complementary literals
+	j := 0
+	for i, l := range inputs {
+		switch {
+		case c.t != 0 && l == c.t:
+			continue
+		case c.t != 0 && l == -c.t:
+			return c.False()
+		case i > 0 && l == -inputs[i-1]:
+			return c.False()
+		}
+		inputs[j] = l
+		j++
+	}
+	inputs = inputs[:j]
+
+	switch len(inputs) {
+	case 0:
+		return c.True()
+	case 1:
+		return inputs[0]
+	}
+
+	return c.gate(c.key("and", inputs...), func(g int) SatClauses {
+		// g implies each input, and all the inputs imply g
+		clauses := make(SatClauses, 0, len(inputs)+1)
+		all := SatClause{g}
+		for _, l := range inputs {
+			clauses = append(clauses, SatClause{-g, l})
+			all = append(all, -l)
+		}
+		return append(clauses, all)
+	})
+}
+
+// Or returns the disjunction of the literals, which is false if there are
+// none. It is encoded as the complement of the AND of their complements.
+func (c *SatCircuit) Or(lits ...int) int {
+	inputs := make([]int, len(lits))
+	for i, l := range lits {
+		inputs[i] = -l
+	}
+	return -c.And(inputs...)
+}
+
+// Xor returns the exclusive or of a and b
+func (c *SatCircuit) Xor(a, b int) int {
+	switch {
+	case a == b:
+		return c.False()
+	case a == -b:
+		return c.True()
+	case c.t != 0 && a == c.t:
+		return -b
+	case c.t != 0 && a == -c.t:
+		return b
+	case c.t != 0 && b == c.t:
+		return -a
+	case c.t != 0 && b == -c.t:
+		return a
+	}
+
+	// Xor(~a, b) = Xor(a, ~b) = ~Xor(a, b)
+	sign := 1
+	if a < 0 {
+		a, sign = -a, -sign
+	}
+	if b < 0 {
+		b, sign = -b, -sign
+	}
+	if a > b {
+		a, b = b, a
+	}
+
+	return sign * c.gate(c.key("xor", a, b), func(g int) SatClauses {
+		return SatClauses{
+			{-g, a, b},
+			{-g, -a, -b},
+			{g, -a, b},
+			{g, a, -b},
+		}
+	})
+}
+
+// Equiv returns the equivalence of a and b, the complement of Xor(a, b)
+func (c *SatCircuit) Equiv(a, b int) int {
+	return -c.Xor(a, b)
+}
+
+// Implies returns the implication a => b, which is Or(~a, b)
+func (c *SatCircuit) Implies(a, b int) int {
+	return c.Or(-a, b)
+}
+
+// Ite returns if-then-else, the value of t if s is true, otherwise the value
+// of e
+func (c *SatCircuit) Ite(s, t, e int) int {
+	switch {
+	case c.t != 0 && s == c.t:
+		return t
+	case c.t != 0 && s == -c.t:
+		return e
+	case c.t != 0 && t == c.t:
+		return c.Or(s, e)
+	case c.t != 0 && t == -c.t:
+		return c.And(-s, e)
+	case c.t != 0 && e == c.t:
+		return c.Or(-s, t)
+	case c.t != 0 && e == -c.t:
+		return c.And(s, t)
+	case t == e:
+		return t
+	case s == t:
+		return c.Or(s, e)
+	case s == -t:
+		return c.And(-s, e)
+	case s == e:
+		return c.And(s, t)
+	case s == -e:
+		return c.Or(-s, t)
+	case t == -e:
+		return c.Equiv(s, t)
+	}
+
+	// Ite(~s, t, e) = Ite(s, e, t)
+	if s < 0 {
+		s, t, e = -s, e, t
+	}
+
+	return c.gate(c.key("ite", s, t, e), func(g int) SatClauses {
+		return SatClauses{
+			{-s, -t, g},
+			{-s, t, -g},
+			{s, -e, g},
+			{s, e, -g},
+			{-t, -e, g},
+			{t, e, -g},
+		}
+	})
+}
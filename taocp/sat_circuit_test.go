@@ -0,0 +1,210 @@
+package taocp
+
+import (
+	"bytes"
+	"testing"
+)
+
+// satCircuitValue determines if the clauses of circuit c are satisfiable
+// when output and the variables of inputs have the given values
+func satCircuitValue(c *SatCircuit, inputs []int, values int, output int) bool {
+	clauses := append(SatClauses{}, c.Clauses()...)
+	for i, k := range inputs {
+		if values>>i&1 == 1 {
+			clauses = append(clauses, SatClause{k})
+		} else {
+			clauses = append(clauses, SatClause{-k})
+		}
+	}
+	clauses = append(clauses, SatClause{output})
+
+	sat, _ := SatAlgorithmD(c.N(), clauses, nil, nil)
+	return sat
+}
+
+func TestSatCircuitGates(t *testing.T) {
+
+	cases := []struct {
+		name  string                               // name of the function
+		gate  func(c *SatCircuit, x, y, z int) int // builds the function
+		value func(x, y, z bool) bool              // expected value
+	}{
+		{"and", func(c *SatCircuit, x, y, z int) int { return c.And(x, -y, z) },
+			func(x, y, z bool) bool { return x && !y && z }},
+		{"or", func(c *SatCircuit, x, y, z int) int { return c.Or(-x, y, z) },
+			func(x, y, z bool) bool { return !x || y || z }},
+		{"xor", func(c *SatCircuit, x, y, z int) int { return c.Xor(c.Xor(x, -y), z) },
+			func(x, y, z bool) bool { return x != !y != z }},
+		{"equiv", func(c *SatCircuit, x, y, z int) int { return c.Equiv(x, c.And(y, z)) },
+			func(x, y, z bool) bool { return x == (y && z) }},
+		{"implies", func(c *SatCircuit, x, y, z int) int { return c.Implies(c.Or(x, y), z) },
+			func(x, y, z bool) bool { return !(x || y) || z }},
+		{"ite", func(c *SatCircuit, x, y, z int) int { return c.Ite(x, y, z) },
+			func(x, y, z bool) bool { return x && y || !x && z }},
+		{"ite not", func(c *SatCircuit, x, y, z int) int { return c.Ite(-x, -y, z) },
+			func(x, y, z bool) bool { return !x && !y || x && z }},
+		{"ite equal", func(c *SatCircuit, x, y, z int) int { return c.Ite(x, x, c.Not(x)) },
+			func(x, y, z bool) bool { return true }},
+		{"ite constant", func(c *SatCircuit, x, y, z int) int { return c.Ite(x, c.True(), z) },
+			func(x, y, z bool) bool { return x || z }},
+		{"majority", func(c *SatCircuit, x, y, z int) int { return c.Or(c.And(x, y), c.And(x, z), c.And(y, z)) },
+			func(x, y, z bool) bool { return x && y || x && z || y && z }},
+		{"true", func(c *SatCircuit, x, y, z int) int { return c.Or(x, -x) },
+			func(x, y, z bool) bool { return true }},
+		{"false", func(c *SatCircuit, x, y, z int) int { return c.And(x, y, -x) },
+			func(x, y, z bool) bool { return false }},
+		{"xor self", func(c *SatCircuit, x, y, z int) int { return c.Xor(c.Xor(y, y), z) },
+			func(x, y, z bool) bool { return z }},
+		{"empty and", func(c *SatCircuit, x, y, z int) int { return c.And() },
+			func(x, y, z bool) bool { return true }},
+		{"empty or", func(c *SatCircuit, x, y, z int) int { return c.Or() },
+			func(x, y, z bool) bool { return false }},
+	}
+
+	for _, tc := range cases {
+		c := NewSatCircuit()
+		x, y, z := c.Var("x"), c.Var("y"), c.Var("z")
+		output := tc.gate(c, x, y, z)
+
+		for values := 0; values < 8; values++ {
+			expected := tc.value(values&1 == 1, values&2 == 2, values&4 == 4)
+			got := satCircuitValue(c, []int{x, y, z}, values, output)
+			if got != expected {
+				t.Errorf("For %s with x,y,z=%03b, expected %t; got %t", tc.name, values, expected, got)
+			}
+		}
+	}
+}
+
+func TestSatCircuitHashing(t *testing.T) {
+
+	c := NewSatCircuit()
+	x, y, z := c.Var("x"), c.Var("y"), c.Var("z")
+
+	and := c.And(x, -y, z)
+	n, m := c.N(), len(c.Clauses())
+
+	cases := []struct {
+		got      int // literal of a gate
+		expected int // literal of the equivalent gate
+	}{
+		{c.And(z, x, -y), and},
+		{c.And(-y, z, z, x), and},
+		{c.Or(-x, y, -z), -and},
+		{c.Xor(x, y), c.Xor(y, x)},
+		{c.Xor(-x, y), -c.Xor(x, y)},
+		{c.Xor(-x, -y), c.Xor(x, y)},
+		{c.Equiv(x, y), -c.Xor(x, y)},
+		{c.Implies(x, y), c.Or(y, -x)},
+		{c.Ite(-x, y, z), c.Ite(x, z, y)},
+		{c.Var("x"), x},
+		{c.And(x), x},
+		{c.Xor(x, c.False()), x},
+	}
+
+	for i, tc := range cases {
+		if tc.got != tc.expected {
+			t.Errorf("For case #%d, expected literal %d; got %d", i, tc.expected, tc.got)
+		}
+	}
+
+	// Only the XOR, OR, ITE and constant gates are new
+	if c.N() != n+4 {
+		t.Errorf("expected %d variables; got %d", n+4, c.N())
+	}
+	if len(c.Clauses()) != m+4+3+6+1 {
+		t.Errorf("expected %d clauses; got %d", m+4+3+6+1, len(c.Clauses()))
+	}
+
+	// Repeating everything adds nothing
+	c.And(x, -y, z)
+	c.Ite(x, z, y)
+	if c.N() != n+4 {
+		t.Errorf("expected %d variables; got %d", n+4, c.N())
+	}
+}
+
+func TestSatCircuitEncode(t *testing.T) {
+
+	c := NewSatCircuit()
+	x, y, z := c.Var("x"), c.Var("y"), c.Var("z")
+
+	// At most one of x, y, z, then a gate after the auxiliary variables
+	c.Encode(func(startV int) (SatClauses, int) {
+		return SatSequentialCounter(0, 1, SatClause{x, y, z}, startV)
+	})
+	n := c.N()
+	output := c.Or(x, y, z)
+	if output != -(n + 1) {
+		t.Errorf("Expected the gate to follow the %d variables; got %d", n, output)
+	}
+
+	for values := 0; values < 8; values++ {
+		expected := values == 1 || values == 2 || values == 4
+		if got := satCircuitValue(c, []int{x, y, z}, values, output); got != expected {
+			t.Errorf("For x,y,z=%03b, expected %t; got %t", values, expected, got)
+		}
+	}
+}
+
+// TestSatCircuitAdder builds a ripple carry adder of two 3-bit numbers and
+// checks the sum of each pair, writing the clauses in Knuth's format
+func TestSatCircuitAdder(t *testing.T) {
+
+	c := NewSatCircuit()
+
+	var x, y, s [4]int
+	carry := c.False()
+	for i := 0; i < 3; i++ {
+		x[i] = c.Var("x" + string(rune('0'+i)))
+		y[i] = c.Var("y" + string(rune('0'+i)))
+		s[i] = c.Xor(c.Xor(x[i], y[i]), carry)
+		carry = c.Or(c.And(x[i], y[i]), c.And(x[i], carry), c.And(y[i], carry))
+	}
+	s[3] = carry
+
+	// The variables are named, and the gates are not
+	variables := c.Variables()
+	if len(variables) != 6 || variables[x[0]] != "x0" || variables[y[2]] != "y2" {
+		t.Errorf("expected six named variables; got %v", variables)
+	}
+
+	var buf bytes.Buffer
+	if err := SatWrite(&buf, c.Clauses(), variables); err != nil {
+		t.Fatalf("expected to write the clauses; got error %v", err)
+	}
+	clauses, read, err := SatParse(&buf)
+	if err != nil {
+		t.Fatalf("expected to parse the clauses; got error %v", err)
+	}
+	if len(read) != c.N() || len(clauses) != len(c.Clauses()) {
+		t.Errorf("expected %d variables and %d clauses; got %d and %d", c.N(), len(c.Clauses()), len(read), len(clauses))
+	}
+
+	for a := 0; a < 8; a++ {
+		for b := 0; b < 8; b++ {
+			clauses := append(SatClauses{}, c.Clauses()...)
+			for i := 0; i < 3; i++ {
+				clauses = append(clauses, SatClause{x[i] * (a>>i&1*2 - 1)}, SatClause{y[i] * (b>>i&1*2 - 1)})
+			}
+
+			sat, solution := SatAlgorithmC(c.N(), clauses, nil, nil)
+			if !sat {
+				t.Errorf("For %d+%d, expected satisfiable; got unsatisfiable", a, b)
+				continue
+			}
+
+			sum := 0
+			for i, l := range s {
+				value := solution[max(l, -l)-1]
+				if l < 0 {
+					value ^= 1
+				}
+				sum += value << i
+			}
+			if sum != a+b {
+				t.Errorf("For %d+%d, expected sum %d; got %d", a, b, a+b, sum)
+			}
+		}
+	}
+}
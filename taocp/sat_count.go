@@ -0,0 +1,349 @@
+package taocp
+
+import (
+	"log"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SatCount returns the number of satisfying assignments of the clauses on the
+// variables 1..n, by DPLL search which splits the clauses into connected
+// components (sets of clauses which share no variables) whose counts are
+// multiplied and cached, so each component is counted only once.
+//
+// If projection is not nil, it returns the number of assignments of the
+// variables of projection which can be extended to a satisfying assignment of
+// all the variables. Only projected variables are branched on until a
+// component contains none, when it only remains to determine if that
+// component is satisfiable. The variables of projection must be in 1..n, or
+// it panics.
+//
+// stats.Nodes counts the branches on a variable.
+//
+// Arguments:
+// n          -- number of strictly distinct literals
+// clauses    -- list of clauses to satisfy
+// stats      -- SAT processing statistics
+// projection -- variables to count assignments of, or nil for all
+func SatCount(n int, clauses SatClauses, stats *SatStats, projection []int) *big.Int {
+
+	var (
+		project  []bool              // variables which are counted
+		cache    map[string]*big.Int // count of each component, by key
+		value    []int               // scratch value of each variable: 1, -1 or 0 if unset
+		stamp    []int               // scratch mark of each variable
+		stamps   int                 // current mark
+		progress bool                // progress tracking is enabled
+		debug    bool                // debugging is enabled
+	)
+
+	satCheckProjection(n, projection)
+
+	project = make([]bool, n+1)
+	if projection == nil {
+		for k := 1; k <= n; k++ {
+			project[k] = true
+		}
+	} else {
+		for _, k := range projection {
+			project[k] = true
+		}
+	}
+
+	cache = make(map[string]*big.Int)
+	value = make([]int, n+1)
+	stamp = make([]int, n+1)
+
+	if stats != nil {
+		stats.Theta = stats.Delta
+		progress = stats.Progress
+		debug = stats.Debug
+	}
+
+	// variables returns the variables of the clauses, in increasing order
+	variables := func(clauses [][]int) []int {
+		stamps++
+		var vars []int
+		for _, clause := range clauses {
+			for _, l := range clause {
+				if v := max(l, -l); stamp[v] != stamps {
+					stamp[v] = stamps
+					vars = append(vars, v)
+				}
+			}
+		}
+		slices.Sort(vars)
+		return vars
+	}
+
+	// literal returns 1 if literal l is true, -1 if it is false, or 0
+	literal := func(l int) int {
+		if l > 0 {
+			return value[l]
+		}
+		return -value[-l]
+	}
+
+	// simplify makes the literals of units true and propagates them, returning
+	// the remaining clauses which are not yet satisfied, with their false
+	// literals removed, and the variables which were set; returns false if a
+	// clause becomes false
+	simplify := func(clauses [][]int, units []int) ([][]int, []int, bool) {
+		var set []int
+
+		// Clear the values when done
+		defer func() {
+			for _, v := range set {
+				value[v] = 0
+			}
+		}()
+
+		// assign makes literal l true, returning false if it is already false
+		assign := func(l int) bool {
+			switch literal(l) {
+			case -1:
+				return false
+			case 0:
+				v := max(l, -l)
+				value[v] = l / v
+				set = append(set, v)
+			}
+			return true
+		}
+
+		for _, l := range units {
+			if !assign(l) {
+				return nil, nil, false
+			}
+		}
+
+		for {
+			var remaining [][]int
+			found := len(set)
+
+		Clauses:
+			for _, clause := range clauses {
+				free := 0
+				for _, l := range clause {
+					switch literal(l) {
+					case 1:
+						continue Clauses
+					case 0:
+						free++
+					}
+				}
+
+				switch {
+				case free == len(clause):
+					remaining = append(remaining, clause)
+				case free == 0:
+					return nil, nil, false
+				case free == 1:
+					for _, l := range clause {
+						if literal(l) == 0 {
+							assign(l)
+						}
+					}
+				default:
+					reduced := make([]int, 0, free)
+					for _, l := range clause {
+						if literal(l) == 0 {
+							reduced = append(reduced, l)
+						}
+					}
+					remaining = append(remaining, reduced)
+				}
+			}
+
+			clauses = remaining
+			if found == len(set) {
+				break
+			}
+		}
+
+		return clauses, slices.Clone(set), true
+	}
+
+	// components splits the clauses into connected components
+	components := func(clauses [][]int) [][][]int {
+		// occurs lists the clauses containing each variable
+		occurs := make(map[int][]int)
+		for i, clause := range clauses {
+			for _, l := range clause {
+				v := max(l, -l)
+				occurs[v] = append(occurs[v], i)
+			}
+		}
+
+		var result [][][]int
+		visited := make([]bool, len(clauses))
+		for i := range clauses {
+			if visited[i] {
+				continue
+			}
+			var component [][]int
+			visited[i] = true
+			stack := []int{i}
+			for len(stack) > 0 {
+				j := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				component = append(component, clauses[j])
+				for _, l := range clauses[j] {
+					for _, jp := range occurs[max(l, -l)] {
+						if !visited[jp] {
+							visited[jp] = true
+							stack = append(stack, jp)
+						}
+					}
+				}
+			}
+			result = append(result, component)
+		}
+
+		return result
+	}
+
+	// key returns a canonical form of the clauses, whose literals are sorted
+	key := func(clauses [][]int) string {
+		clauses = slices.Clone(clauses)
+		slices.SortFunc(clauses, slices.Compare)
+
+		var b strings.Builder
+		for _, clause := range clauses {
+			for _, l := range clause {
+				b.WriteString(strconv.Itoa(l))
+				b.WriteByte(' ')
+			}
+			b.WriteByte(';')
+		}
+		return b.String()
+	}
+
+	var countComponent func(clauses [][]int) *big.Int
+
+	// countFree returns the number of assignments of the projected variables
+	// of vars, given the remaining clauses and the variables set so far: free
+	// projected variables, which are neither set nor in the clauses, have two
+	// values each, and the components of the clauses are counted separately
+	countFree := func(vars []int, clauses [][]int, set []int) *big.Int {
+		count := big.NewInt(1)
+		for _, component := range components(clauses) {
+			count.Mul(count, countComponent(component))
+			if count.Sign() == 0 {
+				return count
+			}
+		}
+
+		variables(clauses)
+		for _, v := range set {
+			stamp[v] = stamps
+		}
+		free := 0
+		for _, v := range vars {
+			if project[v] && stamp[v] != stamps {
+				free++
+			}
+		}
+
+		return count.Lsh(count, uint(free))
+	}
+
+	// countComponent returns the number of assignments of the projected
+	// variables of a connected component of clauses, none of which are units
+	countComponent = func(clauses [][]int) *big.Int {
+		k := key(clauses)
+		if count, ok := cache[k]; ok {
+			return count
+		}
+
+		vars := variables(clauses)
+
+		// Branch on the variable occurring most often, preferring those which
+		// are projected
+		occurrences := make(map[int]int)
+		for _, clause := range clauses {
+			for _, l := range clause {
+				occurrences[max(l, -l)]++
+			}
+		}
+		x := 0
+		for _, v := range vars {
+			if x == 0 ||
+				project[v] && !project[x] ||
+				project[v] == project[x] && occurrences[v] > occurrences[x] {
+				x = v
+			}
+		}
+
+		if stats != nil {
+			stats.Nodes++
+			if progress && stats.Nodes >= stats.Theta {
+				log.Printf("Nodes=%d, cached components=%d", stats.Nodes, len(cache))
+				stats.Theta += stats.Delta
+			}
+		}
+
+		count := new(big.Int)
+		for _, l := range []int{x, -x} {
+			remaining, set, ok := simplify(clauses, []int{l})
+			if !ok {
+				continue
+			}
+			count.Add(count, countFree(vars, remaining, set))
+
+			if !project[x] && count.Sign() > 0 {
+				// None of the variables are projected, so it only matters
+				// that the component is satisfiable
+				count.SetInt64(1)
+				break
+			}
+		}
+
+		if debug {
+			log.Printf("component of %d clauses on %d variables, count=%v", len(clauses), len(vars), count)
+		}
+
+		cache[k] = count
+		return count
+	}
+
+	// Convert the clauses to sorted literals, without duplicates or
+	// tautologies, and set their units
+	var (
+		initial [][]int
+		units   []int
+	)
+Clauses:
+	for _, clause := range clauses {
+		lits := slices.Clone(clause)
+		slices.Sort(lits)
+		lits = slices.Compact(lits)
+		for _, l := range lits {
+			if l < 0 && slices.Contains(lits, -l) {
+				continue Clauses
+			}
+		}
+		switch len(lits) {
+		case 0:
+			return new(big.Int)
+		case 1:
+			units = append(units, lits[0])
+		default:
+			initial = append(initial, lits)
+		}
+	}
+
+	remaining, set, ok := simplify(initial, units)
+	if !ok {
+		return new(big.Int)
+	}
+
+	all := make([]int, n)
+	for k := range all {
+		all[k] = k + 1
+	}
+
+	return countFree(all, remaining, set)
+}
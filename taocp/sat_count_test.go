@@ -0,0 +1,107 @@
+package taocp
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestSatCount(t *testing.T) {
+
+	maxR, numV := SatMaxR(2, SatClause{1, 2, 3, 4, 5}, 6)
+	langford, langfordOptions := SatLangford(8)
+
+	cases := []struct {
+		n          int        // number of strictly distinct literals
+		clauses    SatClauses // clauses to satisfy
+		projection []int      // variables to count assignments of
+		count      string     // expected count
+	}{
+		{0, SatClauses{}, nil, "1"},
+		{3, SatClauses{}, nil, "8"},
+		{1, SatClauses{{}}, nil, "0"},
+		{1, SatClauses{{1}, {-1}}, nil, "0"},
+		{2, SatClauses{{1, -1}}, nil, "4"},
+		{3, SatClauses{{1, -2}, {2, 3}, {-1, -3}, {-1, -2, 3}}, nil, "1"},
+		{4, ClausesRPrime, nil, "2"},
+		{4, ClausesRPrime, []int{4}, "1"},
+		{4, ClausesR, nil, "0"},
+		{8, SatWaerdan(3, 3, 8), nil, "6"},
+		{9, ClausesWaerden339, nil, "0"},
+		{200, SatClauses{{1, 2}}, nil, new(big.Int).Lsh(big.NewInt(3), 198).String()},
+		{200, SatClauses{{1, 2}, {-1, -2}, {3, -200}}, []int{1, 2, 3, 4}, "8"},
+		{5 + numV, maxR, []int{1, 2, 3, 4, 5}, "16"},
+		{len(langfordOptions), langford, nil, "150"},
+	}
+
+	for i, c := range cases {
+		stats := SatStats{}
+		got := SatCount(c.n, c.clauses, &stats, c.projection)
+
+		if got.String() != c.count {
+			t.Errorf("For case #%d, expected count %s; got %v", i, c.count, got)
+		}
+	}
+}
+
+// TestSatCountAgreesWithDAll compares the counts of random clauses with the
+// solutions enumerated by Algorithm D
+func TestSatCountAgreesWithDAll(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(0))
+
+	for seed := int64(0); seed < 100; seed++ {
+		n := 12 + int(seed%8)
+		clauses := SatRand(3, 2*n+int(seed%20), n, seed)
+
+		var projection []int
+		if seed%2 == 1 {
+			projection = rng.Perm(n)[:rng.Intn(n)]
+			for i := range projection {
+				projection[i]++
+			}
+		}
+
+		expected := 0
+		for range SatAlgorithmDAll(n, clauses, nil, nil, projection) {
+			expected++
+		}
+
+		got := SatCount(n, clauses, nil, projection)
+		if got.Cmp(big.NewInt(int64(expected))) != 0 {
+			t.Errorf("For seed %d, projection %v, expected count %d; got %v", seed, projection, expected, got)
+		}
+	}
+}
+
+func TestSatCountProjectionRange(t *testing.T) {
+
+	clauses := SatClauses{{1, 2}, {-1, 3}}
+
+	testSatProjectionRange(t, "SatCount", func(projection []int) {
+		SatCount(3, clauses, nil, projection)
+	})
+}
+
+func BenchmarkSatCount(b *testing.B) {
+
+	cases := []struct {
+		k int // clause length (k-SAT)
+		m int // number of clauses
+		n int // number of strictly distinct literals
+	}{
+		{3, 100, 50},
+		{3, 200, 100},
+	}
+
+	for _, c := range cases {
+		clauses := SatRand(c.k, c.m, c.n, 0)
+
+		b.Run(fmt.Sprintf("k=%d,m=%d,n=%d", c.k, c.m, c.n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				SatCount(c.n, clauses, nil, nil)
+			}
+		})
+	}
+}
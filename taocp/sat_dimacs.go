@@ -0,0 +1,219 @@
+package taocp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DIMACS CNF is the standard input format of the SAT competitions. The file
+// begins with optional "c" comment lines, followed by a "p cnf <variables>
+// <clauses>" problem line, followed by the clauses. Each clause is a list of
+// nonzero integer literals terminated by 0, and may span several lines.
+//
+// Variable names are preserved as comments of the form "c var <k> <name>",
+// which allows conversion to and from Knuth format without losing the
+// mapping of variables (numeric to string name).
+
+// dimacsVarComment is the comment prefix used to record variable names
+const dimacsVarComment = "var"
+
+// SatReadDimacs reads a SAT file in DIMACS CNF format and returns the number
+// of variables declared in the problem line, the list of clauses, and the
+// mapping of variables (numeric to string name) recorded in the comments
+func SatReadDimacs(filename string) (int, SatClauses, map[int]string, error) {
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("error opening %s for reading: %v", filename, err)
+	}
+	defer f.Close()
+
+	n, clauses, variables, err := SatParseDimacs(f)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("error scanning %s: %v", filename, err)
+	}
+
+	return n, clauses, variables, nil
+}
+
+// SatParseDimacs parses SAT clauses in DIMACS CNF format from r and returns
+// the number of variables declared in the problem line, the list of clauses,
+// and the mapping of variables (numeric to string name) recorded in the
+// comments
+func SatParseDimacs(r io.Reader) (int, SatClauses, map[int]string, error) {
+
+	var (
+		n        int        // number of variables, from the problem line
+		m        int        // number of clauses, from the problem line
+		header   bool       // has the problem line been read?
+		clauses  SatClauses // clauses read so far
+		clause   SatClause  // clause currently being read
+		lineNum  int        // current line number
+		startNum int        // line number where the current clause began
+	)
+
+	variables := make(map[int]string)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			continue
+		}
+
+		// Comment line
+		if line[0] == 'c' {
+			fields := strings.Fields(line[1:])
+			if len(fields) == 3 && fields[0] == dimacsVarComment {
+				k, err := strconv.Atoi(fields[1])
+				if err != nil || k < 1 {
+					return 0, nil, nil, fmt.Errorf("line %d: invalid variable number '%s' in name comment", lineNum, fields[1])
+				}
+				variables[k] = fields[2]
+			}
+			continue
+		}
+
+		// Problem line
+		if line[0] == 'p' {
+			if header {
+				return 0, nil, nil, fmt.Errorf("line %d: duplicate problem line", lineNum)
+			}
+
+			fields := strings.Fields(line)
+			if len(fields) != 4 || fields[0] != "p" || fields[1] != "cnf" {
+				return 0, nil, nil, fmt.Errorf("line %d: expected 'p cnf <variables> <clauses>'; got '%s'", lineNum, line)
+			}
+
+			var err error
+			if n, err = strconv.Atoi(fields[2]); err != nil || n < 0 {
+				return 0, nil, nil, fmt.Errorf("line %d: invalid number of variables '%s'", lineNum, fields[2])
+			}
+			if m, err = strconv.Atoi(fields[3]); err != nil || m < 0 {
+				return 0, nil, nil, fmt.Errorf("line %d: invalid number of clauses '%s'", lineNum, fields[3])
+			}
+
+			header = true
+			clauses = make(SatClauses, 0, m)
+			continue
+		}
+
+		// End of data marker used by some SATLIB benchmarks
+		if line[0] == '%' {
+			break
+		}
+
+		if !header {
+			return 0, nil, nil, fmt.Errorf("line %d: clause found before problem line 'p cnf <variables> <clauses>'", lineNum)
+		}
+
+		// Clause literals, possibly spanning several lines
+		for _, field := range strings.Fields(line) {
+			literal, err := strconv.Atoi(field)
+			if err != nil {
+				return 0, nil, nil, fmt.Errorf("line %d: invalid literal '%s'", lineNum, field)
+			}
+
+			if literal == 0 {
+				if len(clauses) == m {
+					return 0, nil, nil, fmt.Errorf("line %d: more than %d clauses", lineNum, m)
+				}
+				clauses = append(clauses, clause)
+				clause = nil
+				continue
+			}
+
+			if literal > n || -literal > n {
+				return 0, nil, nil, fmt.Errorf("line %d: literal %d is out of range for %d variables", lineNum, literal, n)
+			}
+
+			if clause == nil {
+				startNum = lineNum
+				clause = make(SatClause, 0, 4)
+			}
+			clause = append(clause, literal)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, nil, nil, err
+	}
+
+	if !header {
+		return 0, nil, nil, fmt.Errorf("missing problem line 'p cnf <variables> <clauses>'")
+	}
+
+	if clause != nil {
+		return 0, nil, nil, fmt.Errorf("line %d: clause is not terminated by 0", startNum)
+	}
+
+	if len(clauses) != m {
+		return 0, nil, nil, fmt.Errorf("line %d: expected %d clauses; got %d", lineNum, m, len(clauses))
+	}
+
+	return n, clauses, variables, nil
+}
+
+// SatWriteDimacs writes n variables and clauses to w in DIMACS CNF format.
+// The names in variables, if any, are written as comments before the
+// problem line.
+func SatWriteDimacs(w io.Writer, n int, clauses SatClauses, variables map[int]string) error {
+
+	bw := bufio.NewWriter(w)
+
+	// Variable names, in variable order
+	keys := make([]int, 0, len(variables))
+	for k := range variables {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	for _, k := range keys {
+		fmt.Fprintf(bw, "c %s %d %s\n", dimacsVarComment, k, variables[k])
+	}
+
+	// Problem line
+	fmt.Fprintf(bw, "p cnf %d %d\n", n, len(clauses))
+
+	// Clauses
+	for _, clause := range clauses {
+		for _, literal := range clause {
+			bw.WriteString(strconv.Itoa(literal))
+			bw.WriteString(" ")
+		}
+		bw.WriteString("0\n")
+	}
+
+	return bw.Flush()
+}
+
+// SatKnuthToDimacs converts SAT clauses in Knuth format read from r into
+// DIMACS CNF format written to w, keeping the variable names as comments
+func SatKnuthToDimacs(r io.Reader, w io.Writer) error {
+
+	clauses, variables, err := SatParse(r)
+	if err != nil {
+		return err
+	}
+
+	return SatWriteDimacs(w, len(variables), clauses, variables)
+}
+
+// SatDimacsToKnuth converts SAT clauses in DIMACS CNF format read from r into
+// Knuth format written to w, using the variable names from the comments when
+// present and the variable number otherwise
+func SatDimacsToKnuth(r io.Reader, w io.Writer) error {
+
+	_, clauses, variables, err := SatParseDimacs(r)
+	if err != nil {
+		return err
+	}
+
+	return SatWrite(w, clauses, variables)
+}
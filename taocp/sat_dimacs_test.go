@@ -0,0 +1,198 @@
+package taocp
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSatParseDimacs(t *testing.T) {
+
+	cases := []struct {
+		input     string         // DIMACS CNF input
+		n         int            // number of variables
+		clauses   SatClauses     // expected clauses
+		variables map[int]string // expected variable names
+	}{
+		{
+			"c simple\np cnf 3 2\n1 -2 0\n2 3 0\n",
+			3,
+			SatClauses{{1, -2}, {2, 3}},
+			map[int]string{},
+		},
+		{
+			"c multi-line clauses\np cnf 4 3\n1 -2\n 3 0 -4\n0 2\n4 0\n",
+			4,
+			SatClauses{{1, -2, 3}, {-4}, {2, 4}},
+			map[int]string{},
+		},
+		{
+			"c var 1 x1B\nc var 2 x1S\np cnf 2 2\n1 2 0\n-1 -2 0\n%\n0\n",
+			2,
+			SatClauses{{1, 2}, {-1, -2}},
+			map[int]string{1: "x1B", 2: "x1S"},
+		},
+		{
+			"p cnf 5 0\n",
+			5,
+			SatClauses{},
+			map[int]string{},
+		},
+	}
+
+	for i, c := range cases {
+		n, clauses, variables, err := SatParseDimacs(strings.NewReader(c.input))
+
+		if err != nil {
+			t.Errorf("For case #%d, expected no error; got %v", i, err)
+			continue
+		}
+		if n != c.n {
+			t.Errorf("For case #%d, expected n=%d; got %d", i, c.n, n)
+		}
+		if !reflect.DeepEqual(clauses, c.clauses) {
+			t.Errorf("For case #%d, expected clauses %v; got %v", i, c.clauses, clauses)
+		}
+		if !reflect.DeepEqual(variables, c.variables) {
+			t.Errorf("For case #%d, expected variables %v; got %v", i, c.variables, variables)
+		}
+	}
+}
+
+func TestSatParseDimacsErrors(t *testing.T) {
+
+	cases := []struct {
+		input string // DIMACS CNF input
+		err   string // expected error message
+	}{
+		{"1 2 0\n", "line 1: clause found before problem line 'p cnf <variables> <clauses>'"},
+		{"c nothing\n", "missing problem line 'p cnf <variables> <clauses>'"},
+		{"p cnf 3\n", "line 1: expected 'p cnf <variables> <clauses>'; got 'p cnf 3'"},
+		{"p dnf 3 1\n", "line 1: expected 'p cnf <variables> <clauses>'; got 'p dnf 3 1'"},
+		{"p cnf x 1\n", "line 1: invalid number of variables 'x'"},
+		{"p cnf 3 -1\n", "line 1: invalid number of clauses '-1'"},
+		{"p cnf 3 1\np cnf 3 1\n", "line 2: duplicate problem line"},
+		{"p cnf 3 1\n1 a 0\n", "line 2: invalid literal 'a'"},
+		{"p cnf 3 1\n1 -4 0\n", "line 2: literal -4 is out of range for 3 variables"},
+		{"p cnf 3 1\n1 2 0\n3 0\n", "line 3: more than 1 clauses"},
+		{"p cnf 3 2\n1 2 0\n", "line 2: expected 2 clauses; got 1"},
+		{"p cnf 3 2\n1 2 0\n\n3\n", "line 4: clause is not terminated by 0"},
+		{"c var x y\np cnf 3 0\n", "line 1: invalid variable number 'x' in name comment"},
+	}
+
+	for i, c := range cases {
+		_, _, _, err := SatParseDimacs(strings.NewReader(c.input))
+
+		if err == nil {
+			t.Errorf("For case #%d, expected error %q; got none", i, c.err)
+		} else if err.Error() != c.err {
+			t.Errorf("For case #%d, expected error %q; got %q", i, c.err, err.Error())
+		}
+	}
+}
+
+func TestSatWriteDimacs(t *testing.T) {
+
+	var b bytes.Buffer
+
+	clauses := SatClauses{{1, -2}, {2, 3}, {-3}}
+	variables := map[int]string{2: "y", 1: "x"}
+
+	if err := SatWriteDimacs(&b, 3, clauses, variables); err != nil {
+		t.Fatalf("expected no error; got %v", err)
+	}
+
+	expected := "c var 1 x\nc var 2 y\np cnf 3 3\n1 -2 0\n2 3 0\n-3 0\n"
+	if b.String() != expected {
+		t.Errorf("expected output %q; got %q", expected, b.String())
+	}
+}
+
+func TestSatDimacsRoundTrip(t *testing.T) {
+
+	filename := "testdata/SATExamples/test.sat"
+
+	clauses, variables, err := SatRead(filename)
+	if err != nil {
+		t.Fatalf("expected to read file %s; got error %v", filename, err)
+	}
+
+	// Knuth -> DIMACS
+	var knuth bytes.Buffer
+	if err := SatWrite(&knuth, clauses, variables); err != nil {
+		t.Fatalf("expected no error writing Knuth format; got %v", err)
+	}
+
+	var dimacs bytes.Buffer
+	if err := SatKnuthToDimacs(&knuth, &dimacs); err != nil {
+		t.Fatalf("expected no error converting to DIMACS; got %v", err)
+	}
+
+	n, clausesD, variablesD, err := SatParseDimacs(bytes.NewReader(dimacs.Bytes()))
+	if err != nil {
+		t.Fatalf("expected no error parsing DIMACS; got %v", err)
+	}
+	if n != len(variables) {
+		t.Errorf("expected %d variables; got %d", len(variables), n)
+	}
+	if !reflect.DeepEqual(clausesD, clauses) {
+		t.Errorf("expected DIMACS clauses to match the original clauses")
+	}
+	if !reflect.DeepEqual(variablesD, variables) {
+		t.Errorf("expected variables %v; got %v", variables, variablesD)
+	}
+
+	// DIMACS -> Knuth
+	var knuth2 bytes.Buffer
+	if err := SatDimacsToKnuth(bytes.NewReader(dimacs.Bytes()), &knuth2); err != nil {
+		t.Fatalf("expected no error converting to Knuth format; got %v", err)
+	}
+
+	clauses2, variables2, err := SatParse(&knuth2)
+	if err != nil {
+		t.Fatalf("expected no error parsing Knuth format; got %v", err)
+	}
+	if !reflect.DeepEqual(clauses2, clauses) {
+		t.Errorf("expected Knuth clauses to match the original clauses")
+	}
+	if !reflect.DeepEqual(variables2, variables) {
+		t.Errorf("expected variables %v; got %v", variables, variables2)
+	}
+
+	// Variables without a name are written using their number, which
+	// SatParse reads as a name and renumbers by first appearance. DIMACS
+	// keeps the numbering.
+	clauses = SatClauses{{3, -1}, {2, 1}}
+	variables = map[int]string{1: "a"}
+
+	var knuth3 bytes.Buffer
+	if err := SatWrite(&knuth3, clauses, variables); err != nil {
+		t.Fatalf("expected no error writing Knuth format; got %v", err)
+	}
+	if expected := "3 ~a\n2 a\n"; knuth3.String() != expected {
+		t.Errorf("expected Knuth format %q; got %q", expected, knuth3.String())
+	}
+	clauses3, variables3, err := SatParse(&knuth3)
+	if err != nil {
+		t.Fatalf("expected no error parsing Knuth format; got %v", err)
+	}
+	if expected := (SatClauses{{1, -2}, {3, 2}}); !reflect.DeepEqual(clauses3, expected) {
+		t.Errorf("expected renumbered clauses %v; got %v", expected, clauses3)
+	}
+	if expected := map[int]string{1: "3", 2: "a", 3: "2"}; !reflect.DeepEqual(variables3, expected) {
+		t.Errorf("expected variables %v; got %v", expected, variables3)
+	}
+
+	var dimacs3 bytes.Buffer
+	if err := SatWriteDimacs(&dimacs3, 3, clauses, variables); err != nil {
+		t.Fatalf("expected no error writing DIMACS; got %v", err)
+	}
+	n, clausesD, variablesD, err = SatParseDimacs(&dimacs3)
+	if err != nil {
+		t.Fatalf("expected no error parsing DIMACS; got %v", err)
+	}
+	if n != 3 || !reflect.DeepEqual(clausesD, clauses) || !reflect.DeepEqual(variablesD, variables) {
+		t.Errorf("expected DIMACS to keep n=3, %v, %v; got n=%d, %v, %v", clauses, variables, n, clausesD, variablesD)
+	}
+}
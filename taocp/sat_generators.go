@@ -0,0 +1,485 @@
+package taocp
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/yourbasic/graph"
+)
+
+// The generators of this file return the clauses of more of the benchmark
+// families of 7.2.2.2, along with the name of each variable in the form
+// returned by SatRead, so that they can be written with SatWrite. Auxiliary
+// variables, introduced by an encoding rather than by the problem, are
+// named _k.
+
+// satExactlyOne appends the clauses for exactly one of the literals: one
+// clause for at least one, and the binary clauses for at most one
+func satExactlyOne(clauses SatClauses, literals []int) SatClauses {
+	clauses = append(clauses, append(SatClause{}, literals...))
+	for i := 0; i < len(literals); i++ {
+		for j := i + 1; j < len(literals); j++ {
+			clauses = append(clauses, SatClause{-literals[i], -literals[j]})
+		}
+	}
+	return clauses
+}
+
+// satNameAuxiliary names the variables 1..n which don't yet have a name _k
+func satNameAuxiliary(variables map[int]string, n int) {
+	for k := 1; k <= n; k++ {
+		if _, ok := variables[k]; !ok {
+			variables[k] = fmt.Sprintf("_%d", k)
+		}
+	}
+}
+
+// SatPigeonhole returns the clauses for hole(n), which are satisfiable if
+// n+1 pigeons can occupy n holes with at most one pigeon per hole; they
+// never can, but the clauses are hard for resolution. The variable named
+// "p<j>h<k>" means pigeon j (0 <= j <= n) is in hole k (1 <= k <= n).
+func SatPigeonhole(n int) (SatClauses, map[int]string) {
+	var clauses SatClauses
+	variables := make(map[int]string)
+
+	x := func(j, k int) int {
+		return j*n + k
+	}
+	for j := 0; j <= n; j++ {
+		for k := 1; k <= n; k++ {
+			variables[x(j, k)] = fmt.Sprintf("p%dh%d", j, k)
+		}
+	}
+
+	// Every pigeon is in a hole
+	for j := 0; j <= n; j++ {
+		var clause SatClause
+		for k := 1; k <= n; k++ {
+			clause = append(clause, x(j, k))
+		}
+		clauses = append(clauses, clause)
+	}
+
+	// No hole has two pigeons
+	for k := 1; k <= n; k++ {
+		for i := 0; i <= n; i++ {
+			for j := i + 1; j <= n; j++ {
+				clauses = append(clauses, SatClause{-x(i, k), -x(j, k)})
+			}
+		}
+	}
+
+	return clauses, variables
+}
+
+// SatColoring returns the clauses which are satisfiable if the vertices of
+// g can be colored with d colors such that adjacent vertices have different
+// colors. The variable named "v<v>c<c>" means vertex v (0 <= v < g.Order())
+// has color c (1 <= c <= d); each vertex has exactly one color. The edges
+// of g are treated as undirected, and a loop can't be colored.
+func SatColoring(g graph.Iterator, d int) (SatClauses, map[int]string) {
+	var clauses SatClauses
+	variables := make(map[int]string)
+
+	x := func(v, c int) int {
+		return v*d + c
+	}
+	for v := 0; v < g.Order(); v++ {
+		for c := 1; c <= d; c++ {
+			variables[x(v, c)] = fmt.Sprintf("v%dc%d", v, c)
+		}
+	}
+
+	// Every vertex has exactly one color
+	for v := 0; v < g.Order(); v++ {
+		colors := make([]int, d)
+		for c := 1; c <= d; c++ {
+			colors[c-1] = x(v, c)
+		}
+		clauses = satExactlyOne(clauses, colors)
+	}
+
+	// Adjacent vertices have different colors, with each edge once
+	type edge struct{ v, w int }
+	seen := make(map[edge]bool)
+	for v := 0; v < g.Order(); v++ {
+		g.Visit(v, func(w int, _ int64) bool {
+			e := edge{min(v, w), max(v, w)}
+			if seen[e] {
+				return false
+			}
+			seen[e] = true
+			for c := 1; c <= d; c++ {
+				if v == w {
+					clauses = append(clauses, SatClause{-x(v, c)})
+				} else {
+					clauses = append(clauses, SatClause{-x(e.v, c), -x(e.w, c)})
+				}
+			}
+			return false
+		})
+	}
+
+	return clauses, variables
+}
+
+// SatMutilatedChessboard returns the clauses for mchess(n), which are
+// satisfiable if the n x n board with two opposite corners removed can be
+// covered by dominoes; it never can, since the corners have the same color,
+// but the clauses are hard for resolution. The variable named "h<i>.<j>" is
+// a horizontal domino covering cells (i,j) and (i,j+1), and "v<i>.<j>" a
+// vertical domino covering cells (i,j) and (i+1,j), for 0 <= i, j < n.
+// Requires n >= 3.
+func SatMutilatedChessboard(n int) (SatClauses, map[int]string) {
+	var clauses SatClauses
+	variables := make(map[int]string)
+
+	removed := func(i, j int) bool {
+		return (i == 0 && j == 0) || (i == n-1 && j == n-1)
+	}
+
+	// The dominoes covering each cell
+	covers := make([][]int, n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if removed(i, j) {
+				continue
+			}
+			if j+1 < n && !removed(i, j+1) {
+				k := len(variables) + 1
+				variables[k] = fmt.Sprintf("h%d.%d", i, j)
+				covers[i*n+j] = append(covers[i*n+j], k)
+				covers[i*n+j+1] = append(covers[i*n+j+1], k)
+			}
+			if i+1 < n && !removed(i+1, j) {
+				k := len(variables) + 1
+				variables[k] = fmt.Sprintf("v%d.%d", i, j)
+				covers[i*n+j] = append(covers[i*n+j], k)
+				covers[(i+1)*n+j] = append(covers[(i+1)*n+j], k)
+			}
+		}
+	}
+
+	// Every cell is covered by exactly one domino
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if !removed(i, j) {
+				clauses = satExactlyOne(clauses, covers[i*n+j])
+			}
+		}
+	}
+
+	return clauses, variables
+}
+
+// SatFlowerSnark returns the clauses for fsnark(q), which are satisfiable if
+// the edges of the flower snark J_q can be colored with 3 colors such that
+// the edges at each vertex have different colors. J_q has vertices a_j,
+// b_j, c_j, d_j for 0 <= j < q, with edges a_j b_j, a_j c_j, a_j d_j, the
+// cycle b_0 ... b_{q-1}, and the cycle c_0 ... c_{q-1} d_0 ... d_{q-1}; it
+// can't be colored if q is odd. The variable named "<e>.<c>" means edge e,
+// such as a0b0, has color c (1 <= c <= 3). Requires q >= 3.
+func SatFlowerSnark(q int) (SatClauses, map[int]string) {
+	var clauses SatClauses
+	variables := make(map[int]string)
+
+	// The edges at each vertex, by name
+	at := make(map[string][]int)
+	m := 0 // number of edges
+	edge := func(u, v string) {
+		for c := 1; c <= 3; c++ {
+			variables[3*m+c] = fmt.Sprintf("%s%s.%d", u, v, c)
+		}
+		at[u] = append(at[u], m)
+		at[v] = append(at[v], m)
+		m++
+	}
+	vertex := func(x byte, j int) string {
+		return fmt.Sprintf("%c%d", x, j)
+	}
+
+	for j := 0; j < q; j++ {
+		edge(vertex('a', j), vertex('b', j))
+		edge(vertex('a', j), vertex('c', j))
+		edge(vertex('a', j), vertex('d', j))
+		edge(vertex('b', j), vertex('b', (j+1)%q))
+		if j < q-1 {
+			edge(vertex('c', j), vertex('c', j+1))
+			edge(vertex('d', j), vertex('d', j+1))
+		}
+	}
+	edge(vertex('c', q-1), vertex('d', 0))
+	edge(vertex('d', q-1), vertex('c', 0))
+
+	// Every edge has exactly one color
+	for e := 0; e < m; e++ {
+		clauses = satExactlyOne(clauses, []int{3*e + 1, 3*e + 2, 3*e + 3})
+	}
+
+	// The edges at each vertex have different colors
+	for x := byte('a'); x <= 'd'; x++ {
+		for j := 0; j < q; j++ {
+			edges := at[vertex(x, j)]
+			for c := 1; c <= 3; c++ {
+				for i := 0; i < len(edges); i++ {
+					for k := i + 1; k < len(edges); k++ {
+						clauses = append(clauses, SatClause{-(3*edges[i] + c), -(3*edges[k] + c)})
+					}
+				}
+			}
+		}
+	}
+
+	return clauses, variables
+}
+
+// SatLife returns the clauses which are satisfiable if there is a pattern of
+// Conway's Game of Life which becomes the given pattern after r generations.
+// The pattern is given by rows of equal length, with '*' for a live cell and
+// any other character for a dead one. Every generation is confined to the
+// box of the pattern: the cells outside the box are dead, and must remain
+// so. The variable named "x<i>.<j>.<t>" means cell (i,j) of the box is alive
+// at time t (0 <= t <= r); the cells of time r are fixed by unit clauses.
+func SatLife(pattern []string, r int) (SatClauses, map[int]string) {
+	var clauses SatClauses
+	variables := make(map[int]string)
+
+	rows := len(pattern)
+	cols := 0
+	if rows > 0 {
+		cols = len(pattern[0])
+	}
+
+	x := func(i, j, t int) int {
+		return (t*rows+i)*cols + j + 1
+	}
+	for t := 0; t <= r; t++ {
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				variables[x(i, j, t)] = fmt.Sprintf("x%d.%d.%d", i, j, t)
+			}
+		}
+	}
+
+	// neighbors returns the cells of the box around cell (i,j) at time t
+	neighbors := func(i, j, t int) (cells []int) {
+		for di := -1; di <= 1; di++ {
+			for dj := -1; dj <= 1; dj++ {
+				ii, jj := i+di, j+dj
+				if (di != 0 || dj != 0) && ii >= 0 && ii < rows && jj >= 0 && jj < cols {
+					cells = append(cells, x(ii, jj, t))
+				}
+			}
+		}
+		return cells
+	}
+
+	// subsets calls visit with each subset of cells of size k, as the cells
+	// in and out of the subset
+	subsets := func(cells []int, k int, visit func(in, out []int)) {
+		for mask := 0; mask < 1<<len(cells); mask++ {
+			if bits.OnesCount(uint(mask)) != k {
+				continue
+			}
+			var in, out []int
+			for i, cell := range cells {
+				if mask&(1<<i) != 0 {
+					in = append(in, cell)
+				} else {
+					out = append(out, cell)
+				}
+			}
+			visit(in, out)
+		}
+	}
+
+	// clause returns the clause which excludes the cells of in being alive
+	// and those of out being dead, then appends lits
+	clause := func(in, out []int, lits ...int) SatClause {
+		var c SatClause
+		for _, cell := range in {
+			c = append(c, -cell)
+		}
+		c = append(c, out...)
+		return append(c, lits...)
+	}
+
+	for t := 1; t <= r; t++ {
+		for i := -1; i <= rows; i++ {
+			for j := -1; j <= cols; j++ {
+				cells := neighbors(i, j, t-1)
+				if i < 0 || i == rows || j < 0 || j == cols {
+					// A cell outside the box is never born
+					if len(cells) >= 3 {
+						subsets(cells, 3, func(in, out []int) {
+							clauses = append(clauses, clause(in, out))
+						})
+					}
+					continue
+				}
+
+				self, next := x(i, j, t-1), x(i, j, t)
+
+				// A cell with 4 or more live neighbors dies
+				subsets(cells, 4, func(in, _ []int) {
+					clauses = append(clauses, clause(in, nil, -next))
+				})
+				// A cell with 0 or 1 live neighbors dies
+				if len(cells) >= 1 {
+					subsets(cells, len(cells)-1, func(out, _ []int) {
+						clauses = append(clauses, clause(nil, out, -next))
+					})
+				} else {
+					clauses = append(clauses, SatClause{-next})
+				}
+				// A cell with 3 live neighbors lives
+				subsets(cells, 3, func(in, out []int) {
+					clauses = append(clauses, clause(in, out, next))
+				})
+				// A cell with 2 live neighbors stays the same
+				subsets(cells, 2, func(in, out []int) {
+					clauses = append(clauses, clause(in, out, self, -next))
+					clauses = append(clauses, clause(in, out, -self, next))
+				})
+			}
+		}
+	}
+
+	// The final generation is the pattern
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			if pattern[i][j] == '*' {
+				clauses = append(clauses, SatClause{x(i, j, r)})
+			} else {
+				clauses = append(clauses, SatClause{-x(i, j, r)})
+			}
+		}
+	}
+
+	return clauses, variables
+}
+
+// SatTomography returns the clauses for a problem of digital tomography:
+// find an image with the same number of black pixels in each row, column,
+// diagonal and antidiagonal as the given image. The image is given by rows
+// of equal length, with '*' for a black pixel and any other character for a
+// white one. The variable named "x<i>.<j>" means pixel (i,j) is black; the
+// sums are encoded by SatSequentialCounter.
+func SatTomography(image []string) (SatClauses, map[int]string) {
+	var clauses SatClauses
+	variables := make(map[int]string)
+
+	rows := len(image)
+	cols := 0
+	if rows > 0 {
+		cols = len(image[0])
+	}
+
+	x := func(i, j int) int {
+		return i*cols + j + 1
+	}
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			variables[x(i, j)] = fmt.Sprintf("x%d.%d", i, j)
+		}
+	}
+	n := rows * cols
+
+	// sum requires the pixels (i,j) with key(i,j) = k to have the same
+	// number of black pixels as the image, for each k
+	sum := func(key func(i, j int) int) {
+		pixels := make(map[int]SatClause)
+		black := make(map[int]int)
+		var keys []int
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				k := key(i, j)
+				if _, ok := pixels[k]; !ok {
+					keys = append(keys, k)
+				}
+				pixels[k] = append(pixels[k], x(i, j))
+				if image[i][j] == '*' {
+					black[k]++
+				}
+			}
+		}
+		for _, k := range keys {
+			newclauses, numV := SatSequentialCounter(black[k], black[k], pixels[k], n+1)
+			clauses = append(clauses, newclauses...)
+			n += numV
+		}
+	}
+
+	sum(func(i, j int) int { return i })     // rows
+	sum(func(i, j int) int { return j })     // columns
+	sum(func(i, j int) int { return i + j }) // diagonals
+	sum(func(i, j int) int { return i - j }) // antidiagonals
+
+	satNameAuxiliary(variables, n)
+
+	return clauses, variables
+}
+
+// SatFactor returns the clauses which are satisfiable if z = xy, for an
+// m-bit number x > 1 and an n-bit number y > 1, using the Tseytin encoding
+// of an array multiplier built by SatCircuit. The variables named "x<j>" and
+// "y<j>" are the bits of x and y with weight 2^(j-1). Requires m, n >= 2.
+func SatFactor(z uint64, m, n int) (SatClauses, map[int]string) {
+	c := NewSatCircuit()
+
+	x := make([]int, m)
+	for j := range x {
+		x[j] = c.Var(fmt.Sprintf("x%d", j+1))
+	}
+	y := make([]int, n)
+	for j := range y {
+		y[j] = c.Var(fmt.Sprintf("y%d", j+1))
+	}
+
+	// The partial products, by weight
+	columns := make([][]int, m+n+1)
+	for i := range x {
+		for j := range y {
+			columns[i+j] = append(columns[i+j], c.And(x[i], y[j]))
+		}
+	}
+
+	// Reduce each column to one bit with full and half adders, carrying
+	// into the next
+	for w := 0; w < m+n; w++ {
+		for len(columns[w]) > 1 {
+			a, b := columns[w][0], columns[w][1]
+			if len(columns[w]) == 2 {
+				columns[w] = []int{c.Xor(a, b)}
+				columns[w+1] = append(columns[w+1], c.And(a, b))
+				continue
+			}
+			d := columns[w][2]
+			ab := c.Xor(a, b)
+			columns[w] = append(columns[w][3:], c.Xor(ab, d))
+			columns[w+1] = append(columns[w+1], c.Or(c.And(a, b), c.And(ab, d)))
+		}
+	}
+
+	// The product is z
+	for w := 0; w < 64; w++ {
+		one := z>>w&1 == 1
+		switch {
+		case w < m+n && len(columns[w]) == 1 && one:
+			c.Assert(columns[w][0])
+		case w < m+n && len(columns[w]) == 1:
+			c.Assert(-columns[w][0])
+		case one:
+			// The product has no bit of this weight
+			c.Assert(c.False())
+		}
+	}
+
+	// The factors aren't 0 or 1
+	c.Assert(x[1:]...)
+	c.Assert(y[1:]...)
+
+	satNameAuxiliary(c.Variables(), c.N())
+
+	return c.Clauses(), c.Variables()
+}
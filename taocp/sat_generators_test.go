@@ -0,0 +1,325 @@
+package taocp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/wallberg/sandbox-go/graph"
+	ybgraph "github.com/yourbasic/graph"
+)
+
+// testSatGenerated solves the generated clauses with Algorithm C, checks the
+// result and the variable names, and returns the solution by variable name
+func testSatGenerated(t *testing.T, name string, clauses SatClauses, variables map[int]string, sat bool) map[string]bool {
+	t.Helper()
+
+	n := len(variables)
+	for _, clause := range clauses {
+		for _, l := range clause {
+			if l == 0 || l > n || -l > n {
+				t.Errorf("For %s, expected literals of variables 1..%d; got %v", name, n, clause)
+				return nil
+			}
+		}
+	}
+	for k := 1; k <= n; k++ {
+		if variables[k] == "" || strings.ContainsAny(variables[k], " ~") {
+			t.Errorf("For %s, expected a name for variable %d; got %q", name, k, variables[k])
+		}
+	}
+
+	got, solution := SatAlgorithmC(n, clauses, nil, nil)
+	if got != sat {
+		t.Errorf("For %s, expected sat=%t; got %t", name, sat, got)
+		return nil
+	}
+	if !sat {
+		return nil
+	}
+	if !SatTest(n, clauses, solution) {
+		t.Errorf("For %s, expected a solution; got %v", name, solution)
+		return nil
+	}
+
+	values := make(map[string]bool)
+	for k, value := range solution {
+		values[variables[k+1]] = value == 1
+	}
+	return values
+}
+
+func TestSatPigeonhole(t *testing.T) {
+
+	for n := 1; n <= 5; n++ {
+		clauses, variables := SatPigeonhole(n)
+		if len(variables) != n*(n+1) {
+			t.Errorf("For hole(%d), expected %d variables; got %d", n, n*(n+1), len(variables))
+		}
+		testSatGenerated(t, fmt.Sprintf("hole(%d)", n), clauses, variables, false)
+	}
+}
+
+func TestSatColoring(t *testing.T) {
+
+	cases := []struct {
+		name string           // name of the graph
+		g    ybgraph.Iterator // graph to color
+		d    int              // number of colors
+		sat  bool             // is colorable
+	}{
+		{"P5", graph.Path(5), 2, true},
+		{"C5", graph.Cycle(5), 2, false},
+		{"C5", graph.Cycle(5), 3, true},
+		{"C6", graph.Cycle(6), 2, true},
+		{"K4", graph.Complete(4), 3, false},
+		{"K4", graph.Complete(4), 4, true},
+		{"C5xC5", graph.CartesianProduct(graph.Cycle(5), graph.Cycle(5)), 3, true},
+		{"K3xK3", graph.CartesianProduct(graph.Complete(3), graph.Complete(3)), 2, false},
+	}
+
+	for i, c := range cases {
+		clauses, variables := SatColoring(c.g, c.d)
+		values := testSatGenerated(t, fmt.Sprintf("%s with %d colors", c.name, c.d), clauses, variables, c.sat)
+		if values == nil {
+			continue
+		}
+
+		// Decode and check the coloring
+		color := make([]int, c.g.Order())
+		for v := range color {
+			for k := 1; k <= c.d; k++ {
+				if values[variables[v*c.d+k]] {
+					color[v] = k
+				}
+			}
+		}
+		for v := 0; v < c.g.Order(); v++ {
+			c.g.Visit(v, func(w int, _ int64) bool {
+				if color[v] == color[w] {
+					t.Errorf("For case #%d, expected different colors for %d and %d; got %v", i, v, w, color)
+				}
+				return false
+			})
+		}
+	}
+
+	// A loop can't be colored
+	g := ybgraph.New(2)
+	g.AddBoth(0, 1)
+	g.Add(1, 1)
+	clauses, variables := SatColoring(g, 3)
+	testSatGenerated(t, "loop", clauses, variables, false)
+}
+
+func TestSatMutilatedChessboard(t *testing.T) {
+
+	for n := 3; n <= 5; n++ {
+		clauses, variables := SatMutilatedChessboard(n)
+		if expected := 2*n*(n-1) - 4; len(variables) != expected {
+			t.Errorf("For mchess(%d), expected %d variables; got %d", n, expected, len(variables))
+		}
+		testSatGenerated(t, fmt.Sprintf("mchess(%d)", n), clauses, variables, false)
+	}
+}
+
+func TestSatFlowerSnark(t *testing.T) {
+
+	cases := []struct {
+		q   int  // order of the flower snark
+		sat bool // is 3-edge-colorable
+	}{
+		{4, true},
+		{5, false},
+		{6, true},
+		{7, false},
+	}
+
+	for _, c := range cases {
+		clauses, variables := SatFlowerSnark(c.q)
+		if len(variables) != 3*6*c.q {
+			t.Errorf("For fsnark(%d), expected %d variables; got %d", c.q, 3*6*c.q, len(variables))
+		}
+		testSatGenerated(t, fmt.Sprintf("fsnark(%d)", c.q), clauses, variables, c.sat)
+	}
+}
+
+// lifeStep returns the next generation of the cells of the pattern, and
+// whether any cell outside its box is born
+func lifeStep(pattern []string) ([]string, bool) {
+	rows, cols := len(pattern), len(pattern[0])
+	alive := func(i, j int) bool {
+		return i >= 0 && i < rows && j >= 0 && j < cols && pattern[i][j] == '*'
+	}
+
+	var next []string
+	escaped := false
+	for i := -1; i <= rows; i++ {
+		var b strings.Builder
+		for j := -1; j <= cols; j++ {
+			count := 0
+			for di := -1; di <= 1; di++ {
+				for dj := -1; dj <= 1; dj++ {
+					if (di != 0 || dj != 0) && alive(i+di, j+dj) {
+						count++
+					}
+				}
+			}
+			live := count == 3 || (count == 2 && alive(i, j))
+			if i < 0 || i == rows || j < 0 || j == cols {
+				escaped = escaped || live
+			} else if live {
+				b.WriteByte('*')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		if i >= 0 && i < rows {
+			next = append(next, b.String())
+		}
+	}
+	return next, escaped
+}
+
+func TestSatLife(t *testing.T) {
+
+	cases := []struct {
+		pattern []string // final generation
+		r       int      // number of generations
+		sat     bool     // has an ancestor in the box
+	}{
+		{[]string{".....", "..*..", "..*..", "..*..", "....."}, 1, true},
+		{[]string{".....", ".....", ".***.", ".....", "....."}, 2, true},
+		{[]string{"....", ".**.", ".**.", "...."}, 3, true},
+		// A lone cell of a 1x3 box needs all three alive, which gives births
+		// outside the box
+		{[]string{".*."}, 1, false},
+	}
+
+	// A pattern with a known ancestor
+	ancestor := []string{"**.*.", "*..**", ".**..", "*.*.*", "..**."}
+	if next, escaped := lifeStep(ancestor); !escaped {
+		cases = append(cases, struct {
+			pattern []string
+			r       int
+			sat     bool
+		}{next, 1, true})
+	}
+
+	for i, c := range cases {
+		clauses, variables := SatLife(c.pattern, c.r)
+		values := testSatGenerated(t, fmt.Sprintf("life case #%d", i), clauses, variables, c.sat)
+		if values == nil {
+			continue
+		}
+
+		// Decode time 0 and run it forward
+		rows, cols := len(c.pattern), len(c.pattern[0])
+		pattern := make([]string, rows)
+		for k := 1; k <= rows*cols; k++ {
+			row := (k - 1) / cols
+			if values[variables[k]] {
+				pattern[row] += "*"
+			} else {
+				pattern[row] += "."
+			}
+		}
+		for step := 0; step < c.r; step++ {
+			var escaped bool
+			if pattern, escaped = lifeStep(pattern); escaped {
+				t.Errorf("For case #%d, expected the generations to stay in the box", i)
+			}
+		}
+		if strings.Join(pattern, "/") != strings.Join(c.pattern, "/") {
+			t.Errorf("For case #%d, expected %v; got %v", i, c.pattern, pattern)
+		}
+	}
+}
+
+func TestSatTomography(t *testing.T) {
+
+	images := [][]string{
+		{"*"},
+		{"*.", ".*"},
+		{".**.", "*..*", "*..*", ".**."},
+		{"**...", "*.*.*", "..***", "*...*", ".***."},
+	}
+
+	for i, image := range images {
+		clauses, variables := SatTomography(image)
+		values := testSatGenerated(t, fmt.Sprintf("tomography image #%d", i), clauses, variables, true)
+		if values == nil {
+			continue
+		}
+
+		// Compare the sums of the image and the solution
+		sums := func(black func(i, j int) bool) map[[2]int]int {
+			s := make(map[[2]int]int)
+			for i := range image {
+				for j := range image[i] {
+					if black(i, j) {
+						s[[2]int{0, i}]++
+						s[[2]int{1, j}]++
+						s[[2]int{2, i + j}]++
+						s[[2]int{3, i - j}]++
+					}
+				}
+			}
+			return s
+		}
+		expected := sums(func(i, j int) bool { return image[i][j] == '*' })
+		got := sums(func(i, j int) bool { return values[variables[i*len(image[0])+j+1]] })
+		for key, count := range expected {
+			if got[key] != count {
+				t.Errorf("For image #%d, expected sums %v; got %v", i, expected, got)
+				break
+			}
+		}
+	}
+}
+
+func TestSatFactor(t *testing.T) {
+
+	cases := []struct {
+		z    uint64 // number to factor
+		m, n int    // bits of the factors
+		sat  bool   // has factors
+	}{
+		{35, 3, 3, true},
+		{49, 3, 3, true},
+		{37, 3, 3, false},
+		{64, 3, 3, false},
+		{143, 4, 4, true},
+		{143, 3, 5, false},
+		{15, 2, 3, true},
+		{143, 3, 3, false},
+		{1 << 40, 3, 3, false},
+		{899, 5, 5, true},
+	}
+
+	for i, c := range cases {
+		clauses, variables := SatFactor(c.z, c.m, c.n)
+		values := testSatGenerated(t, fmt.Sprintf("factor(%d,%d,%d)", c.z, c.m, c.n), clauses, variables, c.sat)
+		if values == nil {
+			continue
+		}
+
+		var x, y uint64
+		for j := c.m; j >= 1; j-- {
+			x <<= 1
+			if values["x"+strconv.Itoa(j)] {
+				x |= 1
+			}
+		}
+		for j := c.n; j >= 1; j-- {
+			y <<= 1
+			if values["y"+strconv.Itoa(j)] {
+				y |= 1
+			}
+		}
+		if x*y != c.z || x < 2 || y < 2 {
+			t.Errorf("For case #%d, expected factors of %d; got %d x %d", i, c.z, x, y)
+		}
+	}
+}
@@ -0,0 +1,447 @@
+package taocp
+
+import (
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SatReconstruction records the clauses removed by SatPreprocess which a
+// solution of the reduced clauses might not satisfy, each with a witness
+// literal which satisfies it, so the solution can be extended to one of the
+// original clauses.
+type SatReconstruction struct {
+	n         int        // number of variables
+	witnesses []int      // literal which satisfies each clause
+	clauses   SatClauses // removed clauses, in order of removal
+}
+
+// push records a removed clause with its witness
+func (r *SatReconstruction) push(witness int, clause SatClause) {
+	r.witnesses = append(r.witnesses, witness)
+	r.clauses = append(r.clauses, slices.Clone(clause))
+}
+
+// Extend returns a solution of the original clauses given to SatPreprocess,
+// from a solution of the reduced clauses in the form returned by the SAT
+// algorithms: solution[k-1] is 1 if variable k is true, otherwise 0. A
+// shorter solution is padded with zeros.
+//
+// Every witness is first made false. Then, in the reverse order of their
+// removal, each clause which isn't satisfied is satisfied by making its
+// witness true.
+func (r *SatReconstruction) Extend(solution []int) []int {
+	result := make([]int, r.n)
+	copy(result, solution)
+
+	for _, l := range r.witnesses {
+		if l > 0 {
+			result[l-1] = 0
+		} else {
+			result[-l-1] = 1
+		}
+	}
+
+	for i := len(r.clauses) - 1; i >= 0; i-- {
+		satisfied := false
+		for _, l := range r.clauses[i] {
+			if l > 0 && result[l-1] == 1 || l < 0 && result[-l-1] == 0 {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
+			if l := r.witnesses[i]; l > 0 {
+				result[l-1] = 1
+			} else {
+				result[-l-1] = 0
+			}
+		}
+	}
+
+	return result
+}
+
+// SatPreprocess simplifies clauses before they are given to a SAT algorithm,
+// with the techniques of 7.2.2.2 "Preprocessing":
+//
+//   - unit propagation: a unit clause l is removed with every clause
+//     containing l, and ~l is removed from every other clause
+//   - pure literal elimination: a literal l whose complement doesn't occur is
+//     made true, removing the clauses which contain it
+//   - subsumption: a clause C subsumes (and removes) every clause D ⊇ C
+//   - self-subsuming resolution: if C = C' ∪ l and D ⊇ C' ∪ ~l, then ~l is
+//     removed from D
+//   - bounded variable elimination: a variable x is eliminated by replacing
+//     the clauses containing x or ~x by their resolvents on x, if there are
+//     no more of them and none has more than satPreprocessMaxResolvent
+//     literals
+//
+// Duplicate literals, duplicate clauses and tautologies are also removed.
+//
+// Returns false if the clauses are found to be unsatisfiable. Otherwise
+// returns the reduced clauses on the same variables 1..n, which have a
+// solution if and only if the original clauses do, and the reconstruction
+// which extends a solution of the reduced clauses to one of the original
+// clauses. The reduced clauses may be empty, when every assignment is a
+// solution.
+//
+// Arguments:
+// n       -- number of strictly distinct literals
+// clauses -- list of clauses to simplify
+// stats   -- SAT processing statistics
+func SatPreprocess(n int, clauses SatClauses, stats *SatStats) (sat bool, reduced SatClauses, reconstruction *SatReconstruction) {
+
+	var (
+		lits       [][]int        // sorted literals of each clause, or nil if removed
+		occurs     [][]int        // clauses containing each literal, possibly stale
+		keys       map[string]int // clause with each key, for duplicates
+		eliminated []bool         // variables which no longer occur
+		units      []int          // clauses which may be units
+		queue      []int          // clauses which may subsume others
+		queued     []bool         // clauses in queue
+		debug      bool           // debugging is enabled
+	)
+
+	reconstruction = &SatReconstruction{n: n}
+	occurs = make([][]int, 2*n+2)
+	keys = make(map[string]int)
+	eliminated = make([]bool, n+1)
+
+	if stats != nil {
+		debug = stats.Debug
+	}
+
+	// lit returns the index of literal l in occurs, 2k for k and 2k+1 for ~k
+	lit := func(l int) int {
+		if l > 0 {
+			return 2 * l
+		}
+		return -2*l + 1
+	}
+
+	// key returns the key of a clause
+	key := func(clause []int) string {
+		var b strings.Builder
+		for _, l := range clause {
+			b.WriteString(strconv.Itoa(l))
+			b.WriteByte(' ')
+		}
+		return b.String()
+	}
+
+	// occurrences returns the clauses which contain l, removing stale entries
+	occurrences := func(l int) []int {
+		list := occurs[lit(l)]
+		j := 0
+		for _, c := range list {
+			if lits[c] != nil && slices.Contains(lits[c], l) {
+				list[j] = c
+				j++
+			}
+		}
+		occurs[lit(l)] = list[:j]
+		return list[:j]
+	}
+
+	// enqueue adds a clause to the subsumption queue
+	enqueue := func(c int) {
+		if !queued[c] {
+			queued[c] = true
+			queue = append(queue, c)
+		}
+	}
+
+	// remove removes a clause
+	remove := func(c int) {
+		delete(keys, key(lits[c]))
+		lits[c] = nil
+	}
+
+	// add adds a clause, unless it is a tautology or duplicate; returns false
+	// if it is empty
+	add := func(clause []int) bool {
+		clause = slices.Clone(clause)
+		slices.SortFunc(clause, func(a, b int) int { return lit(a) - lit(b) })
+		clause = slices.Compact(clause)
+		for i := 1; i < len(clause); i++ {
+			if clause[i] == -clause[i-1] {
+				return true
+			}
+		}
+		if len(clause) == 0 {
+			return false
+		}
+
+		k := key(clause)
+		if _, ok := keys[k]; ok {
+			return true
+		}
+
+		c := len(lits)
+		keys[k] = c
+		lits = append(lits, clause)
+		queued = append(queued, false)
+		for _, l := range clause {
+			occurs[lit(l)] = append(occurs[lit(l)], c)
+		}
+		if len(clause) == 1 {
+			units = append(units, c)
+		}
+		enqueue(c)
+		return true
+	}
+
+	// strengthen removes literal l from a clause; returns false if it
+	// becomes empty
+	strengthen := func(c int, l int) bool {
+		delete(keys, key(lits[c]))
+		i := slices.Index(lits[c], l)
+		lits[c] = slices.Delete(slices.Clone(lits[c]), i, i+1)
+		if len(lits[c]) == 0 {
+			return false
+		}
+
+		k := key(lits[c])
+		if _, ok := keys[k]; ok {
+			lits[c] = nil
+			return true
+		}
+		keys[k] = c
+		if len(lits[c]) == 1 {
+			units = append(units, c)
+		}
+		enqueue(c)
+		return true
+	}
+
+	// propagate makes the literals of the unit clauses true; returns false
+	// if a clause becomes empty
+	propagate := func() bool {
+		for len(units) > 0 {
+			c := units[len(units)-1]
+			units = units[:len(units)-1]
+			if lits[c] == nil || len(lits[c]) != 1 {
+				continue
+			}
+
+			l := lits[c][0]
+			v := max(l, -l)
+			eliminated[v] = true
+			reconstruction.push(l, SatClause{l})
+
+			for _, d := range slices.Clone(occurrences(l)) {
+				remove(d)
+			}
+			for _, d := range slices.Clone(occurrences(-l)) {
+				if !strengthen(d, -l) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	// pure eliminates the pure literals; returns true if there were any
+	pure := func() bool {
+		found := false
+		for v := 1; v <= n; v++ {
+			if eliminated[v] {
+				continue
+			}
+			pos, neg := occurrences(v), occurrences(-v)
+			var l int
+			switch {
+			case len(pos) > 0 && len(neg) == 0:
+				l = v
+			case len(neg) > 0 && len(pos) == 0:
+				l = -v
+			default:
+				continue
+			}
+
+			found = true
+			eliminated[v] = true
+			reconstruction.push(l, SatClause{l})
+			for _, c := range slices.Clone(occurrences(l)) {
+				remove(c)
+			}
+		}
+		return found
+	}
+
+	// subsume removes the clauses subsumed by clause c, and strengthens those
+	// by self-subsuming resolution; returns false if a clause becomes empty
+	subsume := func(c int) (changed bool, ok bool) {
+		clause := lits[c]
+
+		// Every such clause contains the variable of clause c which occurs
+		// least often
+		best := 0
+		for _, l := range clause {
+			v := max(l, -l)
+			if best == 0 || len(occurrences(v))+len(occurrences(-v)) <
+				len(occurrences(best))+len(occurrences(-best)) {
+				best = v
+			}
+		}
+
+		candidates := append(slices.Clone(occurrences(best)), occurrences(-best)...)
+	Candidates:
+		for _, d := range candidates {
+			if d == c || lits[d] == nil || lits[c] == nil || len(lits[d]) < len(clause) {
+				continue
+			}
+
+			// Every literal of clause c is in d, except at most one whose
+			// complement is in d
+			flip := 0
+			for _, l := range clause {
+				switch {
+				case slices.Contains(lits[d], l):
+				case flip == 0 && slices.Contains(lits[d], -l):
+					flip = l
+				default:
+					continue Candidates
+				}
+			}
+
+			changed = true
+			if flip == 0 {
+				remove(d)
+			} else if !strengthen(d, -flip) {
+				return changed, false
+			}
+		}
+
+		return changed, true
+	}
+
+	// eliminate eliminates variable x by resolution, if it doesn't increase
+	// the number of clauses; returns false if a resolvent is empty
+	eliminate := func(x int) (changed bool, ok bool) {
+		pos := slices.Clone(occurrences(x))
+		neg := slices.Clone(occurrences(-x))
+		if len(pos)*len(neg) > satPreprocessMaxProduct {
+			return false, true
+		}
+
+		var resolvents [][]int
+		for _, p := range pos {
+		Resolvents:
+			for _, q := range neg {
+				resolvent := slices.Clone(lits[p])
+				for _, l := range lits[q] {
+					switch {
+					case l == -x || slices.Contains(resolvent, l):
+					case slices.Contains(resolvent, -l):
+						continue Resolvents
+					default:
+						resolvent = append(resolvent, l)
+					}
+				}
+				resolvent = slices.DeleteFunc(resolvent, func(l int) bool { return l == x })
+				if len(resolvent) > satPreprocessMaxResolvent ||
+					len(resolvents) == len(pos)+len(neg) {
+					return false, true
+				}
+				resolvents = append(resolvents, resolvent)
+			}
+		}
+
+		// The clauses containing x are satisfied by making x true, if needed;
+		// those containing ~x are then satisfied, since their resolvents are
+		eliminated[x] = true
+		for _, p := range pos {
+			reconstruction.push(x, lits[p])
+			remove(p)
+		}
+		for _, q := range neg {
+			remove(q)
+		}
+		for _, resolvent := range resolvents {
+			if !add(resolvent) {
+				return true, false
+			}
+		}
+
+		return true, true
+	}
+
+	// Add the clauses
+	for _, clause := range clauses {
+		if !add(clause) {
+			return false, nil, nil
+		}
+	}
+
+	for round := 1; ; round++ {
+		if !propagate() {
+			return false, nil, nil
+		}
+
+		changed := pure()
+
+		for len(queue) > 0 {
+			c := queue[0]
+			queue = queue[1:]
+			queued[c] = false
+			if lits[c] == nil {
+				continue
+			}
+			found, ok := subsume(c)
+			if !ok || !propagate() {
+				return false, nil, nil
+			}
+			changed = changed || found
+		}
+
+		// Try to eliminate the variables in order of their occurrences
+		var vars []int
+		for v := 1; v <= n; v++ {
+			if !eliminated[v] && len(occurrences(v))+len(occurrences(-v)) > 0 {
+				vars = append(vars, v)
+			}
+		}
+		slices.SortStableFunc(vars, func(a, b int) int {
+			return len(occurrences(a)) + len(occurrences(-a)) -
+				len(occurrences(b)) - len(occurrences(-b))
+		})
+		for _, v := range vars {
+			if eliminated[v] {
+				continue
+			}
+			found, ok := eliminate(v)
+			if !ok || !propagate() {
+				return false, nil, nil
+			}
+			changed = changed || found
+		}
+
+		if debug {
+			log.Printf("round %d: %d clauses, %d reconstruction clauses",
+				round, len(keys), len(reconstruction.clauses))
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	reduced = SatClauses{}
+	for _, clause := range lits {
+		if clause != nil {
+			reduced = append(reduced, slices.Clone(clause))
+		}
+	}
+
+	return true, reduced, reconstruction
+}
+
+const (
+	// Maximum length of a resolvent of bounded variable elimination
+	satPreprocessMaxResolvent = 20
+	// Maximum number of resolvents to try for bounded variable elimination
+	satPreprocessMaxProduct = 400
+)
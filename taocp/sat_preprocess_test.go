@@ -0,0 +1,129 @@
+package taocp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSatPreprocess(t *testing.T) {
+
+	cases := []struct {
+		n       int        // number of strictly distinct literals
+		clauses SatClauses // clauses to simplify
+		sat     bool       // not found to be unsatisfiable
+		reduced SatClauses // expected reduced clauses
+	}{
+		// unit propagation
+		{3, SatClauses{{1}, {-1, 2}, {-2, 3}}, true, SatClauses{}},
+		{2, SatClauses{{1}, {-1, 2}, {-2}}, false, nil},
+		// duplicates and tautologies
+		{2, SatClauses{{1, 1}, {1, -1}}, true, SatClauses{}},
+		// pure literals
+		{3, SatClauses{{1, 2}, {1, -2, 3}, {-2, -3}}, true, SatClauses{}},
+		// empty clause
+		{1, SatClauses{{1}, {}}, false, nil},
+		// unsatisfiable
+		{4, ClausesR, false, nil},
+	}
+
+	for i, c := range cases {
+		sat, reduced, _ := SatPreprocess(c.n, c.clauses, nil)
+		if sat != c.sat {
+			t.Errorf("For case #%d, expected sat=%t; got %t", i, c.sat, sat)
+			continue
+		}
+		if sat && !reflect.DeepEqual(reduced, c.reduced) {
+			t.Errorf("For case #%d, expected reduced clauses %v; got %v", i, c.reduced, reduced)
+		}
+	}
+}
+
+func TestSatPreprocessSubsumption(t *testing.T) {
+
+	// Pure literals and variable elimination are blocked by clauses
+	// {v, a} and {~v, ~a} for v = 1..4 and a = 5..10
+	var block SatClauses
+	for v := 1; v <= 4; v++ {
+		for a := 5; a <= 10; a++ {
+			block = append(block, SatClause{v, a}, SatClause{-v, -a})
+		}
+	}
+
+	cases := []struct {
+		clauses SatClauses // clauses to simplify
+		removed SatClause  // a clause which should be removed
+		kept    SatClause  // a clause which should be kept
+	}{
+		// {1, 2} subsumes {1, 2, 3}
+		{SatClauses{{1, 2}, {1, 2, 3}}, SatClause{1, 2, 3}, SatClause{1, 2}},
+		// {1, 2} and {-1, 2, 3} strengthen the latter to {2, 3}
+		{SatClauses{{1, 2}, {-1, 2, 3}}, SatClause{-1, 2, 3}, SatClause{2, 3}},
+	}
+
+	for i, c := range cases {
+		clauses := append(append(SatClauses{}, c.clauses...), block...)
+		sat, reduced, _ := SatPreprocess(10, clauses, nil)
+		if !sat {
+			t.Errorf("For case #%d, expected sat=true; got false", i)
+			continue
+		}
+
+		removed, kept := false, false
+		for _, clause := range reduced {
+			removed = removed || reflect.DeepEqual(clause, c.removed)
+			kept = kept || reflect.DeepEqual(clause, c.kept)
+		}
+		if removed || !kept {
+			t.Errorf("For case #%d, expected %v removed and %v kept; got %v", i, c.removed, c.kept, reduced)
+		}
+	}
+}
+
+// TestSatPreprocessExtend checks that solutions of the reduced clauses extend
+// to solutions of the original clauses, and that satisfiability is preserved
+func TestSatPreprocessExtend(t *testing.T) {
+
+	type satCase struct {
+		n       int        // number of strictly distinct literals
+		clauses SatClauses // clauses to simplify
+	}
+
+	cases := []satCase{
+		{4, ClausesRPrime},
+		{4, ClausesR},
+		{8, SatWaerdan(3, 3, 8)},
+		{9, SatWaerdan(3, 3, 9)},
+	}
+	for seed := int64(0); seed < 100; seed++ {
+		n := 10 + int(seed%20)
+		cases = append(cases, satCase{n, SatRand(3, 3*n+int(seed%3)*n, n, seed)})
+		cases = append(cases, satCase{n, SatRand(2, n+int(seed%2)*n/2, n, seed)})
+	}
+	for _, n := range []int{5, 7} {
+		clauses, options := SatLangford(n)
+		cases = append(cases, satCase{len(options), clauses})
+	}
+
+	for i, c := range cases {
+		expected, _ := SatAlgorithmD(c.n, c.clauses, nil, nil)
+
+		sat, reduced, reconstruction := SatPreprocess(c.n, c.clauses, nil)
+		var solution []int
+		if sat && len(reduced) > 0 {
+			sat, solution = SatAlgorithmD(c.n, reduced, nil, nil)
+		}
+
+		if sat != expected {
+			t.Errorf("For case #%d, expected sat=%t; got %t", i, expected, sat)
+			continue
+		}
+		if !sat {
+			continue
+		}
+
+		solution = reconstruction.Extend(solution)
+		if !SatTest(c.n, c.clauses, solution) {
+			t.Errorf("For case #%d, expected %v to satisfy the clauses", i, solution)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package taocp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// satProof writes the lines of a DRAT proof to SatOptions.Proof, in the
+// DIMACS numbering of the variables. Literals are given in the internal
+// encoding of the algorithms, 2k for x_k and 2k+1 for ~x_k.
+type satProof struct {
+	w *bufio.Writer
+}
+
+// newSatProof returns a satProof writing to options.Proof, or nil if no
+// proof was requested
+func newSatProof(options *SatOptions) *satProof {
+	if options == nil || options.Proof == nil {
+		return nil
+	}
+	return &satProof{w: bufio.NewWriter(options.Proof)}
+}
+
+// write writes the clause with the literals lits, preceded by prefix
+func (p *satProof) write(prefix string, lits []int) {
+	p.w.WriteString(prefix)
+	for _, l := range lits {
+		if l&1 == 1 {
+			p.w.WriteString("-")
+		}
+		p.w.WriteString(strconv.Itoa(l >> 1))
+		p.w.WriteString(" ")
+	}
+	p.w.WriteString("0\n")
+}
+
+// add writes the addition of a clause, if p is not nil
+func (p *satProof) add(lits []int) {
+	if p != nil {
+		p.write("", lits)
+	}
+}
+
+// delete writes the deletion of a clause, if p is not nil
+func (p *satProof) delete(lits []int) {
+	if p != nil {
+		p.write("d ", lits)
+	}
+}
+
+// flush writes any buffered lines, if p is not nil
+func (p *satProof) flush() {
+	if p != nil {
+		p.w.Flush()
+	}
+}
+
+// SatCheckProof checks a proof that the clauses on n variables are
+// unsatisfiable, read from proof in the DRAT text format: one clause per
+// line in DIMACS numbering terminated by 0, with deletions prefixed by "d"
+// and comment lines prefixed by "c". Every clause added must be RUP (reverse
+// unit propagation): assigning false to all of its literals must lead to a
+// conflict by unit propagation from the clauses present at that point. The
+// proof must derive the empty clause, or a conflict among the unit clauses.
+//
+// Deletions of clauses which are not present, and of unit clauses, are
+// ignored. Returns nil if the proof is valid.
+func SatCheckProof(n int, clauses SatClauses, proof io.Reader) error {
+
+	var (
+		mem          [][]int          // literals of each clause, nil if deleted
+		index        map[string][]int // clauses with each set of literals
+		watch        [][]int          // clauses watching each literal
+		x            []int            // value of each variable, or -1
+		trail        []int            // literals which are true, in order of assignment
+		g            int              // number of trail literals already propagated
+		inconsistent bool             // unit propagation of the clauses gives a conflict
+	)
+
+	watch = make([][]int, 2*n+2)
+	x = make([]int, n+1)
+	for k := range x {
+		x[k] = -1
+	}
+	index = make(map[string][]int)
+
+	isFalse := func(l int) bool {
+		return x[l>>1] == l&1
+	}
+
+	isTrue := func(l int) bool {
+		return x[l>>1] == l&1^1
+	}
+
+	assign := func(l int) {
+		x[l>>1] = l&1 ^ 1
+		trail = append(trail, l)
+	}
+
+	// literals converts a clause to internal literals, sorted and without
+	// duplicates; returns nil if the clause is a tautology
+	literals := func(clause []int) ([]int, error) {
+		lits := []int{}
+		for _, k := range clause {
+			var l int
+			if k >= 0 {
+				l = 2 * k
+			} else {
+				l = -2*k + 1
+			}
+			if l>>1 > n {
+				return nil, fmt.Errorf("variable %d is larger than n=%d", l>>1, n)
+			}
+			lits = append(lits, l)
+		}
+		slices.Sort(lits)
+		lits = slices.Compact(lits)
+		for i := 1; i < len(lits); i++ {
+			if lits[i] == lits[i-1]^1 {
+				return nil, nil
+			}
+		}
+		return lits, nil
+	}
+
+	key := func(lits []int) string {
+		return fmt.Sprint(lits)
+	}
+
+	// propagate makes the unpropagated trail literals true, returning true
+	// if there is a conflict
+	propagate := func() bool {
+		for g < len(trail) {
+			l := trail[g] ^ 1
+			g++
+
+			ws := watch[l]
+			i, j := 0, 0
+		Watchers:
+			for i < len(ws) {
+				c := ws[i]
+				i++
+				lits := mem[c]
+				if lits == nil {
+					// deleted
+					continue
+				}
+
+				if lits[0] == l {
+					lits[0], lits[1] = lits[1], lits[0]
+				}
+				if isTrue(lits[0]) {
+					ws[j] = c
+					j++
+					continue
+				}
+				for k := 2; k < len(lits); k++ {
+					if !isFalse(lits[k]) {
+						lits[1], lits[k] = lits[k], lits[1]
+						watch[lits[1]] = append(watch[lits[1]], c)
+						continue Watchers
+					}
+				}
+
+				ws[j] = c
+				j++
+				if isFalse(lits[0]) {
+					for i < len(ws) {
+						ws[j] = ws[i]
+						i++
+						j++
+					}
+					watch[l] = ws[:j]
+					return true
+				}
+				assign(lits[0])
+			}
+			watch[l] = ws[:j]
+		}
+		return false
+	}
+
+	// undo unassigns the trail literals after the first t
+	undo := func(t int) {
+		for _, l := range trail[t:] {
+			x[l>>1] = -1
+		}
+		trail = trail[:t]
+		g = t
+	}
+
+	// insert adds the clause with literals lits to the clauses, propagating
+	// any units at the top level
+	insert := func(lits []int) {
+		if inconsistent {
+			return
+		}
+
+		// Move the literals which are not false to the front
+		free := 0
+		for i, l := range lits {
+			if !isFalse(l) {
+				lits[free], lits[i] = lits[i], lits[free]
+				free++
+			}
+		}
+
+		if len(lits) >= 2 {
+			c := len(mem)
+			mem = append(mem, lits)
+			k := key(slices.Sorted(slices.Values(lits)))
+			index[k] = append(index[k], c)
+			watch[lits[0]] = append(watch[lits[0]], c)
+			watch[lits[1]] = append(watch[lits[1]], c)
+		}
+
+		switch {
+		case free == 0:
+			inconsistent = true
+		case free == 1 && !isTrue(lits[0]):
+			assign(lits[0])
+			if propagate() {
+				inconsistent = true
+			}
+		}
+	}
+
+	// remove deletes the clause with literals lits from the clauses
+	remove := func(lits []int) {
+		if len(lits) < 2 {
+			return
+		}
+		k := key(lits)
+		if cs := index[k]; len(cs) > 0 {
+			mem[cs[len(cs)-1]] = nil
+			index[k] = cs[:len(cs)-1]
+		}
+	}
+
+	// rup determines if the clause with literals lits is RUP
+	rup := func(lits []int) bool {
+		if inconsistent {
+			return true
+		}
+		t := len(trail)
+		defer undo(t)
+		for _, l := range lits {
+			if isTrue(l) {
+				return true
+			}
+			if !isFalse(l) {
+				assign(l ^ 1)
+			}
+		}
+		return propagate()
+	}
+
+	for i, clause := range clauses {
+		lits, err := literals(clause)
+		if err != nil {
+			return fmt.Errorf("clause %d: %v", i+1, err)
+		}
+		if lits != nil {
+			insert(lits)
+		}
+	}
+
+	scanner := bufio.NewScanner(proof)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+
+		deletion := fields[0] == "d"
+		if deletion {
+			fields = fields[1:]
+		}
+
+		if len(fields) == 0 || fields[len(fields)-1] != "0" {
+			return fmt.Errorf("line %d: clause is not terminated by 0", line)
+		}
+		clause := make([]int, len(fields)-1)
+		for i, field := range fields[:len(fields)-1] {
+			v, err := strconv.Atoi(field)
+			if err != nil || v == 0 {
+				return fmt.Errorf("line %d: invalid literal '%s'", line, field)
+			}
+			clause[i] = v
+		}
+
+		lits, err := literals(clause)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		if lits == nil {
+			continue
+		}
+
+		if deletion {
+			remove(lits)
+			continue
+		}
+
+		if !rup(lits) {
+			return fmt.Errorf("line %d: clause %v is not RUP", line, clause)
+		}
+		if len(lits) == 0 {
+			return nil
+		}
+		insert(lits)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if !inconsistent {
+		return fmt.Errorf("proof does not derive the empty clause")
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package taocp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSatCheckProof(t *testing.T) {
+
+	cases := []struct {
+		n       int        // number of strictly distinct literals
+		clauses SatClauses // clauses which are unsatisfiable
+		proof   string     // DRAT proof
+		err     string     // expected error, or "" if valid
+	}{
+		// Proofs of unsatisfiability of R
+		{4, ClausesR, "1 2 0\n1 0\n2 0\n0\n", ""},
+		{4, ClausesR, "c comment\n\n1 2 0\nd 1 2 -3 0\n1 0\n2 0\n0\n", ""},
+		{4, ClausesR, "1 0\n2 0\n", "line 1: clause [1] is not RUP"},
+		{4, ClausesR, "1 2 0\n1 0\n0\n", "line 3: clause [] is not RUP"},
+		{4, ClausesR, "3 0\n", "line 1: clause [3] is not RUP"},
+		{4, ClausesR, "1 2 0\n", "proof does not derive the empty clause"},
+		{4, ClausesR, "1 2\n", "line 1: clause is not terminated by 0"},
+		{4, ClausesR, "1 x 0\n", "line 1: invalid literal 'x'"},
+		{4, ClausesR, "5 0\n", "line 1: variable 5 is larger than n=4"},
+		{1, SatClauses{{1}, {-1}}, "", ""},
+		{1, SatClauses{{}}, "0\n", ""},
+		{2, SatClauses{{1, 2}}, "0\n", "line 1: clause [] is not RUP"},
+		// A deleted clause can't be used
+		{2, SatClauses{{1, 2}, {1, -2}, {-1, 2}, {-1, -2}}, "d 1 2 0\n1 0\n", "line 2: clause [1] is not RUP"},
+	}
+
+	for i, c := range cases {
+		err := SatCheckProof(c.n, c.clauses, strings.NewReader(c.proof))
+
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != c.err {
+			t.Errorf("For case #%d, expected error '%s'; got '%s'", i, c.err, got)
+		}
+	}
+}
+
+func TestSatProof(t *testing.T) {
+
+	type solver func(int, SatClauses, *SatStats, *SatOptions) (bool, []int)
+
+	solvers := []struct {
+		name string
+		f    solver
+	}{
+		{"C", SatAlgorithmC},
+		{"D", SatAlgorithmD},
+		{"L", func(n int, clauses SatClauses, stats *SatStats, options *SatOptions) (bool, []int) {
+			return SatAlgorithmL(n, clauses, stats, options, NewSatAlgorithmLOptions())
+		}},
+		{"L/lookahead", func(n int, clauses SatClauses, stats *SatStats, options *SatOptions) (bool, []int) {
+			optionsL := NewSatAlgorithmLOptions()
+			optionsL.Lookahead = true
+			optionsL.DoubleLookahead = true
+			optionsL.CompensationResolvants = true
+			return SatAlgorithmL(n, clauses, stats, options, optionsL)
+		}},
+	}
+
+	langford, langfordOptions := SatLangford(6)
+	l1, l1Variables, _ := SatRead("testdata/SATExamples/L1.sat")
+	x2, x2Variables, _ := SatRead("testdata/SATExamples/X2.sat")
+
+	cases := []struct {
+		name       string     // name of the case
+		n          int        // number of strictly distinct literals
+		clauses    SatClauses // clauses to satisfy
+		algorithms string     // algorithms to check
+	}{
+		{"R", 4, ClausesR, "CDL"},
+		{"waerden(3,3;9)", 9, ClausesWaerden339, "CDL"},
+		{"waerden(3,5;22)", 22, SatWaerdan(3, 5, 22), "CDL"},
+		{"complete(5)", 5, SatComplete(5), "CDL"},
+		{"rand(2,400,100)", 100, SatRand(2, 400, 100, 0), "CDL"},
+		{"rand(3,500,100)", 100, SatRand(3, 500, 100, 0), "CDL"},
+		{"langford(6)", len(langfordOptions), langford, "CDL"},
+		{"unit conflict", 2, SatClauses{{1}, {-1, 2}, {-2}}, "CDL"},
+		{"X2", len(x2Variables), x2, "CDL"},
+		{"L1", len(l1Variables), l1, "CL"}, // D's proof is too long to check quickly
+	}
+
+	for _, s := range solvers {
+		for _, c := range cases {
+			if !strings.Contains(c.algorithms, s.name[:1]) {
+				continue
+			}
+			t.Run(s.name+"/"+c.name, func(t *testing.T) {
+				t.Parallel()
+
+				var proof bytes.Buffer
+				options := SatOptions{Proof: &proof}
+
+				sat, _ := s.f(c.n, c.clauses, &SatStats{}, &options)
+
+				if sat {
+					t.Fatalf("expected unsatisfiable; got satisfiable")
+				}
+				if err := SatCheckProof(c.n, c.clauses, &proof); err != nil {
+					t.Errorf("expected a valid proof; got error %v", err)
+				}
+			})
+		}
+	}
+}
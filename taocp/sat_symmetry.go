@@ -0,0 +1,130 @@
+package taocp
+
+import (
+	"slices"
+)
+
+// SatSymmetries returns generators of the group of symmetries of the
+// clauses: the permutations of the literals, which may complement
+// variables, that map the set of clauses to itself. They are found as
+// the automorphisms of the incidence graph, with a vertex for each literal
+// and each clause, an edge between the literals x and ~x, and an edge
+// between each clause and its literals. Each generator is a list of n
+// literals, the image of each variable 1..n. complete is false if the search
+// for them was cut short, and they may not generate the whole group.
+func SatSymmetries(n int, clauses SatClauses) (generators [][]int, complete bool) {
+
+	// Literal x is vertex 2(x-1) and ~x is vertex 2(x-1)+1; clause j is
+	// vertex 2n+j
+	vertex := func(l int) int {
+		if l > 0 {
+			return 2 * (l - 1)
+		}
+		return 2*(-l-1) + 1
+	}
+
+	color := make([]int, 2*n+len(clauses))
+	for j := range clauses {
+		color[2*n+j] = 1
+	}
+	g := newSymGraph(color)
+	for k := 1; k <= n; k++ {
+		g.addEdge(vertex(k), vertex(-k))
+	}
+	for j, clause := range clauses {
+		for _, l := range clause {
+			g.addEdge(2*n+j, vertex(l))
+		}
+	}
+	g.finish()
+
+	automorphisms, complete := g.automorphisms()
+	for _, gamma := range automorphisms {
+		sigma := make([]int, n)
+		identity := true
+		for k := 1; k <= n; k++ {
+			image := gamma[vertex(k)]
+			if image%2 == 0 {
+				sigma[k-1] = image/2 + 1
+			} else {
+				sigma[k-1] = -(image/2 + 1)
+			}
+			identity = identity && sigma[k-1] == k
+		}
+		// Skip automorphisms which only exchange identical clauses
+		if !identity {
+			generators = append(generators, sigma)
+		}
+	}
+
+	return generators, complete
+}
+
+// SatLexLeader generates lex-leader clauses which break the symmetries of
+// the generators, as returned by SatSymmetries: for each generator sigma,
+// they are satisfied only by the assignments x_1 ... x_n which are
+// lexicographically less than or equal to their image under sigma. The
+// least assignment of every class of symmetric solutions satisfies them, so
+// the clauses remain satisfiable if they were. The variables which are
+// mapped to themselves are skipped, and the auxiliary variable a_i means
+// that the assignment and its image agree on the first i variables moved.
+// Like SatMaxR, returns the new clauses and the number of auxiliary
+// variables created, (startV,...,startV+numV-1).
+func SatLexLeader(generators [][]int, startV int) (newclauses SatClauses, numV int) {
+
+	for _, sigma := range generators {
+		var moved []int
+		for k, image := range sigma {
+			if image != k+1 {
+				moved = append(moved, k+1)
+			}
+		}
+
+		a := 0 // agree on the variables before x, or 0 for none
+		for i, x := range moved {
+			y := sigma[x-1]
+
+			// If x and y agree so far, then x <= y
+			clause := SatClause{-x, y}
+			if y == -x {
+				clause = SatClause{-x}
+			}
+			if a != 0 {
+				clause = append(SatClause{-a}, clause...)
+			}
+			newclauses = append(newclauses, clause)
+
+			// x and ~x never agree
+			if y == -x || i == len(moved)-1 {
+				break
+			}
+
+			// If they agree so far, and x = y, they agree through x
+			next := startV + numV
+			numV++
+			agree := SatClauses{{-x, -y, next}, {x, y, next}}
+			for _, clause := range agree {
+				if a != 0 {
+					clause = append(SatClause{-a}, clause...)
+				}
+				newclauses = append(newclauses, clause)
+			}
+			a = next
+		}
+	}
+
+	return newclauses, numV
+}
+
+// SatBreakSymmetry returns the clauses with lex-leader clauses added for the
+// symmetries found by SatSymmetries, and the new number of variables. The
+// result is satisfiable if and only if the clauses are, and its solutions,
+// restricted to the variables 1..n, are solutions of the clauses; but some
+// solutions are lost, so it isn't suitable for finding or counting all of
+// them. complete is as for SatSymmetries: if false, some symmetries may
+// remain.
+func SatBreakSymmetry(n int, clauses SatClauses) (int, SatClauses, bool) {
+	generators, complete := SatSymmetries(n, clauses)
+	newclauses, numV := SatLexLeader(generators, n+1)
+	return n + numV, append(slices.Clone(clauses), newclauses...), complete
+}
@@ -0,0 +1,179 @@
+package taocp
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/wallberg/sandbox-go/graph"
+)
+
+// satSymmetryCases returns small clauses with symmetries
+func satSymmetryCases() []struct {
+	name    string
+	n       int
+	clauses SatClauses
+} {
+	type c = struct {
+		name    string
+		n       int
+		clauses SatClauses
+	}
+	var cases []c
+
+	hole, variables := SatPigeonhole(3)
+	cases = append(cases, c{"hole(3)", len(variables), hole})
+	path, variables := SatColoring(graph.Path(3), 3)
+	cases = append(cases, c{"color(path(3),3)", len(variables), path})
+	cycle, variables := SatColoring(graph.Cycle(4), 2)
+	cases = append(cases, c{"color(cycle(4),2)", len(variables), cycle})
+	cases = append(cases, c{"waerden(3,3;8)", 8, SatWaerdan(3, 3, 8)})
+	cases = append(cases, c{"waerden(3,3;9)", 9, ClausesWaerden339})
+	cases = append(cases, c{"R'", 4, ClausesRPrime})
+	cases = append(cases, c{"complete(3)", 3, SatComplete(3)})
+
+	return cases
+}
+
+// satClauseSet returns the clauses as a sorted list of sorted clauses
+func satClauseSet(clauses SatClauses) []string {
+	var set []string
+	for _, clause := range clauses {
+		clause = slices.Clone(clause)
+		slices.Sort(clause)
+		set = append(set, fmt.Sprint(clause))
+	}
+	slices.Sort(set)
+	return set
+}
+
+func TestSatSymmetries(t *testing.T) {
+
+	for _, c := range satSymmetryCases() {
+		generators, complete := SatSymmetries(c.n, c.clauses)
+		if len(generators) == 0 && c.name != "R'" {
+			t.Errorf("For %s, expected symmetries", c.name)
+		}
+		if !complete {
+			t.Errorf("For %s, expected a complete search for symmetries", c.name)
+		}
+
+		// Each generator maps the clauses to themselves
+		for _, sigma := range generators {
+			var image SatClauses
+			for _, clause := range c.clauses {
+				var mapped SatClause
+				for _, l := range clause {
+					if l > 0 {
+						mapped = append(mapped, sigma[l-1])
+					} else {
+						mapped = append(mapped, -sigma[-l-1])
+					}
+				}
+				image = append(image, mapped)
+			}
+			if !slices.Equal(satClauseSet(image), satClauseSet(c.clauses)) {
+				t.Errorf("For %s, expected a symmetry; got %v", c.name, sigma)
+			}
+		}
+	}
+}
+
+func TestSatBreakSymmetry(t *testing.T) {
+
+	for _, c := range satSymmetryCases() {
+		generators, _ := SatSymmetries(c.n, c.clauses)
+		n, broken, _ := SatBreakSymmetry(c.n, c.clauses)
+
+		// apply returns the image of solution x under sigma
+		apply := func(sigma []int, x []int) []int {
+			y := make([]int, len(x))
+			for k, l := range sigma {
+				if l > 0 {
+					y[k] = x[l-1]
+				} else {
+					y[k] = 1 - x[-l-1]
+				}
+			}
+			return y
+		}
+
+		// kept returns true if the solution x of the clauses satisfies the
+		// lex-leader clauses, for some values of the auxiliary variables
+		kept := func(x []int) bool {
+			fixed := slices.Clone(broken)
+			for k, value := range x {
+				if value == 1 {
+					fixed = append(fixed, SatClause{k + 1})
+				} else {
+					fixed = append(fixed, SatClause{-(k + 1)})
+				}
+			}
+			sat, _ := SatAlgorithmD(n, fixed, nil, nil)
+			return sat
+		}
+
+		// Every class of symmetric solutions keeps its least solution
+		seen := make(map[string]bool)
+		classes := 0
+		for bits := 0; bits < 1<<c.n; bits++ {
+			x := make([]int, c.n)
+			for k := range x {
+				x[k] = bits >> (c.n - 1 - k) & 1
+			}
+			if !SatTest(c.n, c.clauses, x) {
+				if kept(x) {
+					t.Errorf("For %s, expected non-solution %v to be rejected", c.name, x)
+				}
+				continue
+			}
+			if seen[fmt.Sprint(x)] {
+				continue
+			}
+
+			// The class of x, whose first member is its least
+			classes++
+			class := [][]int{x}
+			seen[fmt.Sprint(x)] = true
+			for i := 0; i < len(class); i++ {
+				for _, sigma := range generators {
+					if y := apply(sigma, class[i]); !seen[fmt.Sprint(y)] {
+						seen[fmt.Sprint(y)] = true
+						class = append(class, y)
+					}
+				}
+			}
+			if !kept(x) {
+				t.Errorf("For %s, expected the least solution %v of its class to be kept", c.name, x)
+			}
+		}
+
+		sat, _ := SatAlgorithmD(n, broken, nil, nil)
+		if sat != (classes > 0) {
+			t.Errorf("For %s, expected sat=%t; got %t", c.name, classes > 0, sat)
+		}
+	}
+}
+
+// TestSatBreakSymmetryNodes checks that breaking the symmetries of the
+// pigeons and holes shrinks the search of Algorithm C
+func TestSatBreakSymmetryNodes(t *testing.T) {
+
+	for _, holes := range []int{4, 5, 6, 7, 8} {
+		clauses, variables := SatPigeonhole(holes)
+		n := len(variables)
+
+		stats := SatStats{}
+		SatAlgorithmC(n, clauses, &stats, nil)
+
+		nBroken, broken, _ := SatBreakSymmetry(n, clauses)
+		statsBroken := SatStats{}
+		if sat, _ := SatAlgorithmC(nBroken, broken, &statsBroken, nil); sat {
+			t.Errorf("For hole(%d), expected unsatisfiable", holes)
+		}
+		if statsBroken.Nodes >= stats.Nodes {
+			t.Errorf("For hole(%d), expected fewer than %d nodes; got %d", holes, stats.Nodes, statsBroken.Nodes)
+		}
+		t.Logf("hole(%d): %d nodes, %d with symmetry breaking", holes, stats.Nodes, statsBroken.Nodes)
+	}
+}
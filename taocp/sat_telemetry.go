@@ -0,0 +1,108 @@
+package taocp
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// SatTelemetry is a snapshot of the metrics of a SAT algorithm, reported to
+// SatStats.Telemetry while it runs and once more when it finishes. The
+// counts are those of SatStats, so they accumulate over every algorithm run
+// with the same stats. It serializes to JSON with the field names given by
+// its tags, one report per line with SatTelemetryWriter.
+type SatTelemetry struct {
+	Algorithm        string  `json:"algorithm"`         // A, B, C, D, L, P or W
+	Seq              int     `json:"seq"`               // number of the report, from 1
+	Elapsed          float64 `json:"elapsed"`           // seconds since the algorithm started
+	Done             bool    `json:"done"`              // the algorithm has finished
+	Nodes            int     `json:"nodes"`             // SatStats.Nodes
+	Decisions        int     `json:"decisions"`         // SatStats.Decisions
+	Propagations     int     `json:"propagations"`      // SatStats.Propagations
+	Conflicts        int     `json:"conflicts"`         // SatStats.Conflicts
+	Learned          int     `json:"learned"`           // SatStats.Learned
+	Restarts         int     `json:"restarts"`          // SatStats.Restarts
+	Flips            int     `json:"flips"`             // SatStats.Flips
+	Probes           int     `json:"probes"`            // SatStats.Probes
+	DoubleLookaheads int     `json:"double_lookaheads"` // SatStats.DoubleLookaheads
+	Solutions        int     `json:"solutions"`         // SatStats.Solutions
+	MaxLevel         int     `json:"max_level"`         // SatStats.MaxLevel
+}
+
+// satTelemetryState is the state of the telemetry of a SatStats
+type satTelemetryState struct {
+	algorithm string    // algorithm being run
+	start     time.Time // time the algorithm started
+	last      time.Time // time of the last report
+	seq       int       // number of reports
+}
+
+// SatTelemetryWriter returns a callback for SatStats.Telemetry which writes
+// each report to w as a line of JSON. Write errors are ignored, so they
+// don't interrupt the algorithm.
+func SatTelemetryWriter(w io.Writer) func(SatTelemetry) {
+	encoder := json.NewEncoder(w)
+	return func(t SatTelemetry) {
+		_ = encoder.Encode(t)
+	}
+}
+
+// SatTelemetryChannel returns a callback for SatStats.Telemetry which sends
+// each report to ch, blocking until it is received
+func SatTelemetryChannel(ch chan<- SatTelemetry) func(SatTelemetry) {
+	return func(t SatTelemetry) {
+		ch <- t
+	}
+}
+
+// telemetryStart begins the telemetry of an algorithm
+func (s *SatStats) telemetryStart(algorithm string) {
+	if s == nil || s.Telemetry == nil {
+		return
+	}
+	s.telemetry.algorithm = algorithm
+	s.telemetry.start = time.Now()
+	s.telemetry.last = s.telemetry.start
+}
+
+// telemetryTick reports the metrics, if TelemetryInterval has passed since
+// the last report
+func (s *SatStats) telemetryTick() {
+	if s == nil || s.Telemetry == nil {
+		return
+	}
+	if now := time.Now(); now.Sub(s.telemetry.last) >= s.TelemetryInterval {
+		s.telemetry.last = now
+		s.telemetryReport(now, false)
+	}
+}
+
+// telemetryDone makes the final report of an algorithm
+func (s *SatStats) telemetryDone() {
+	if s == nil || s.Telemetry == nil {
+		return
+	}
+	s.telemetryReport(time.Now(), true)
+}
+
+// telemetryReport passes the metrics to Telemetry
+func (s *SatStats) telemetryReport(now time.Time, done bool) {
+	s.telemetry.seq++
+	s.Telemetry(SatTelemetry{
+		Algorithm:        s.telemetry.algorithm,
+		Seq:              s.telemetry.seq,
+		Elapsed:          now.Sub(s.telemetry.start).Seconds(),
+		Done:             done,
+		Nodes:            s.Nodes,
+		Decisions:        s.Decisions,
+		Propagations:     s.Propagations,
+		Conflicts:        s.Conflicts,
+		Learned:          s.Learned,
+		Restarts:         s.Restarts,
+		Flips:            s.Flips,
+		Probes:           s.Probes,
+		DoubleLookaheads: s.DoubleLookaheads,
+		Solutions:        s.Solutions,
+		MaxLevel:         s.MaxLevel,
+	})
+}
@@ -0,0 +1,143 @@
+package taocp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSatTelemetry(t *testing.T) {
+
+	clauses, options := SatLangford(6)
+	n := len(options)
+
+	algorithms := map[string]func(*SatStats) bool{
+		"A": func(stats *SatStats) bool { sat, _ := SatAlgorithmA(n, clauses, stats, nil); return sat },
+		"B": func(stats *SatStats) bool { sat, _ := SatAlgorithmB(n, clauses, stats, nil); return sat },
+		"C": func(stats *SatStats) bool { sat, _ := SatAlgorithmC(n, clauses, stats, nil); return sat },
+		"D": func(stats *SatStats) bool { sat, _ := SatAlgorithmD(n, clauses, stats, nil); return sat },
+		"L": func(stats *SatStats) bool {
+			optionsL := &SatAlgorithmLOptions{Lookahead: true, DoubleLookahead: true}
+			sat, _ := SatAlgorithmL(n, clauses, stats, nil, optionsL)
+			return sat
+		},
+		"W": func(stats *SatStats) bool {
+			sat, _ := SatAlgorithmW(n, clauses, stats, nil, &SatAlgorithmWOptions{MaxFlips: 1000, MaxTries: 2})
+			return sat
+		},
+	}
+
+	for algorithm, solve := range algorithms {
+		var reports []SatTelemetry
+		stats := SatStats{
+			Telemetry: func(t SatTelemetry) { reports = append(reports, t) },
+		}
+
+		if solve(&stats) {
+			t.Errorf("For %s, expected langford(6) to be unsatisfiable", algorithm)
+		}
+
+		if len(reports) < 2 {
+			t.Errorf("For %s, expected reports while running; got %d", algorithm, len(reports))
+			continue
+		}
+		last := reports[len(reports)-1]
+		if !last.Done || last.Algorithm != algorithm || last.Seq != len(reports) {
+			t.Errorf("For %s, expected a final report; got %+v", algorithm, last)
+		}
+		if last.Nodes != stats.Nodes || last.Decisions != stats.Decisions ||
+			last.Propagations != stats.Propagations || last.Conflicts != stats.Conflicts ||
+			last.Learned != stats.Learned || last.Flips != stats.Flips ||
+			last.Probes != stats.Probes || last.DoubleLookaheads != stats.DoubleLookaheads {
+			t.Errorf("For %s, expected the final report to match %+v; got %+v", algorithm, stats, last)
+		}
+
+		// The counts never decrease
+		for i := 1; i < len(reports); i++ {
+			if reports[i].Nodes < reports[i-1].Nodes || reports[i].Flips < reports[i-1].Flips ||
+				reports[i].Elapsed < reports[i-1].Elapsed {
+				t.Errorf("For %s, expected increasing reports; got %+v then %+v", algorithm, reports[i-1], reports[i])
+				break
+			}
+		}
+
+		switch algorithm {
+		case "C":
+			if stats.Learned == 0 || stats.Propagations == 0 || stats.Decisions != stats.Nodes {
+				t.Errorf("For C, expected learned clauses, propagations and decisions; got %+v", last)
+			}
+		case "D":
+			if stats.Decisions+stats.Propagations != stats.Nodes || stats.Conflicts == 0 {
+				t.Errorf("For D, expected decisions + propagations = nodes, and conflicts; got %+v", last)
+			}
+		case "L":
+			if stats.Propagations == 0 || stats.Conflicts == 0 || stats.Probes == 0 || stats.DoubleLookaheads == 0 {
+				t.Errorf("For L, expected propagations, conflicts, probes and double lookaheads; got %+v", last)
+			}
+		case "W":
+			if stats.Flips == 0 {
+				t.Errorf("For W, expected flips; got %+v", last)
+			}
+		}
+	}
+}
+
+func TestSatTelemetryWriter(t *testing.T) {
+
+	var b bytes.Buffer
+	stats := SatStats{
+		Telemetry:         SatTelemetryWriter(&b),
+		TelemetryInterval: 1 << 62, // only the final report
+	}
+	SatAlgorithmD(9, ClausesWaerden339, &stats, nil)
+
+	var reports []SatTelemetry
+	scanner := bufio.NewScanner(&b)
+	for scanner.Scan() {
+		var report SatTelemetry
+		if err := json.Unmarshal(scanner.Bytes(), &report); err != nil {
+			t.Fatalf("Expected a line of JSON; got %q: %v", scanner.Text(), err)
+		}
+		reports = append(reports, report)
+	}
+
+	expected := SatTelemetry{
+		Algorithm:    "D",
+		Seq:          1,
+		Done:         true,
+		Nodes:        37,
+		Decisions:    stats.Decisions,
+		Propagations: stats.Propagations,
+		Conflicts:    stats.Conflicts,
+		MaxLevel:     stats.MaxLevel,
+	}
+	if len(reports) != 1 {
+		t.Fatalf("Expected 1 report; got %v", reports)
+	}
+	reports[0].Elapsed = 0
+	if reports[0] != expected {
+		t.Errorf("Expected %+v; got %+v", expected, reports[0])
+	}
+}
+
+func TestSatTelemetryChannel(t *testing.T) {
+
+	ch := make(chan SatTelemetry)
+	stats := SatStats{Telemetry: SatTelemetryChannel(ch)}
+
+	go func() {
+		SatAlgorithmC(9, ClausesWaerden339, &stats, nil)
+		close(ch)
+	}()
+
+	var last SatTelemetry
+	count := 0
+	for report := range ch {
+		last = report
+		count++
+	}
+	if !last.Done || last.Seq != count || last.Algorithm != "C" {
+		t.Errorf("Expected the final report of %d; got %+v", count, last)
+	}
+}
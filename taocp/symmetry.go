@@ -0,0 +1,357 @@
+package taocp
+
+import (
+	"slices"
+	"sort"
+)
+
+// symMaxNodes limits the nodes of the search for automorphisms. When it is
+// reached the generators found so far are returned, which are all
+// automorphisms but may not generate the whole group, and the search is
+// reported incomplete.
+var symMaxNodes = 1 << 16
+
+// symGraph is an undirected graph with colored vertices, whose automorphisms
+// are the symmetries of a SAT or XCC problem. Automorphisms must preserve
+// the colors.
+type symGraph struct {
+	adj   [][]int // neighbors of each vertex, sorted
+	color []int   // color of each vertex
+}
+
+// newSymGraph returns a graph with no edges, and vertices of the given colors
+func newSymGraph(color []int) *symGraph {
+	return &symGraph{
+		adj:   make([][]int, len(color)),
+		color: color,
+	}
+}
+
+// addEdge adds an edge between u and v
+func (g *symGraph) addEdge(u, v int) {
+	g.adj[u] = append(g.adj[u], v)
+	g.adj[v] = append(g.adj[v], u)
+}
+
+// finish sorts the neighbors of each vertex and removes repeated edges
+func (g *symGraph) finish() {
+	for u := range g.adj {
+		slices.Sort(g.adj[u])
+		g.adj[u] = slices.Compact(g.adj[u])
+	}
+}
+
+// adjacent returns true if u and v are adjacent
+func (g *symGraph) adjacent(u, v int) bool {
+	_, found := slices.BinarySearch(g.adj[u], v)
+	return found
+}
+
+// isAutomorphism returns true if the permutation gamma of the vertices
+// preserves the colors and the edges
+func (g *symGraph) isAutomorphism(gamma []int) bool {
+	for u, adj := range g.adj {
+		if g.color[gamma[u]] != g.color[u] || len(g.adj[gamma[u]]) != len(adj) {
+			return false
+		}
+		for _, v := range adj {
+			if !g.adjacent(gamma[u], gamma[v]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// symPartition is an ordered partition of the vertices into cells
+type symPartition struct {
+	order []int // the vertices, cell by cell
+	cell  []int // position in order of the first vertex of the cell of each vertex
+	end   []int // position after the last vertex of the cell starting at each position
+}
+
+// clone returns a copy of p
+func (p *symPartition) clone() *symPartition {
+	return &symPartition{
+		order: slices.Clone(p.order),
+		cell:  slices.Clone(p.cell),
+		end:   slices.Clone(p.end),
+	}
+}
+
+// target returns the position of the first cell with more than one vertex,
+// or -1 if every cell has one vertex
+func (p *symPartition) target() int {
+	for i := 0; i < len(p.order); i = p.end[i] {
+		if p.end[i]-i > 1 {
+			return i
+		}
+	}
+	return -1
+}
+
+// shape returns a hash of the sizes of the cells, in order. Partitions of
+// different shapes may rarely have the same hash, which only costs a longer
+// search, since the leaves are checked by isAutomorphism.
+func (p *symPartition) shape() uint64 {
+	h := uint64(14695981039346656037) // FNV-1a
+	for i := 0; i < len(p.order); i = p.end[i] {
+		h = (h ^ uint64(p.end[i]-i)) * 1099511628211
+	}
+	return h
+}
+
+// partition returns the partition of the vertices by color, in increasing
+// order of color, refined until it is equitable
+func (g *symGraph) partition() *symPartition {
+	n := len(g.color)
+	p := &symPartition{
+		order: make([]int, n),
+		cell:  make([]int, n),
+		end:   make([]int, n),
+	}
+	for v := range p.order {
+		p.order[v] = v
+	}
+	sort.SliceStable(p.order, func(i, j int) bool {
+		return g.color[p.order[i]] < g.color[p.order[j]]
+	})
+
+	var queue []int
+	for i := 0; i < n; {
+		j := i
+		for j < n && g.color[p.order[j]] == g.color[p.order[i]] {
+			p.cell[p.order[j]] = i
+			j++
+		}
+		p.end[i] = j
+		queue = append(queue, i)
+		i = j
+	}
+
+	g.refine(p, queue)
+	return p
+}
+
+// refine splits the cells of p until it is equitable: any two vertices of
+// a cell have the same number of neighbors in each cell. The cells in queue
+// are used to split the others, and every new cell is added to it. The
+// order of the new cells depends only on the number of neighbors, so
+// isomorphic partitions are refined alike.
+func (g *symGraph) refine(p *symPartition, queue []int) {
+	n := len(p.order)
+	count := make([]int, n)
+	queued := make([]bool, n)
+	split := make([]bool, n)
+	for _, s := range queue {
+		queued[s] = true
+	}
+
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		queued[s] = false
+
+		// Count the neighbors of each vertex in cell s
+		var touched []int
+		for _, u := range p.order[s:p.end[s]] {
+			for _, v := range g.adj[u] {
+				if count[v] == 0 {
+					touched = append(touched, v)
+				}
+				count[v]++
+			}
+		}
+
+		// Split the cells with different counts
+		var cells []int
+		for _, v := range touched {
+			if c := p.cell[v]; !split[c] {
+				split[c] = true
+				cells = append(cells, c)
+			}
+		}
+		slices.Sort(cells)
+
+		for _, c := range cells {
+			split[c] = false
+			e := p.end[c]
+			vertices := p.order[c:e]
+			sort.SliceStable(vertices, func(i, j int) bool {
+				return count[vertices[i]] < count[vertices[j]]
+			})
+			if count[vertices[0]] == count[vertices[len(vertices)-1]] {
+				continue
+			}
+			for i := c; i < e; {
+				j := i
+				for j < e && count[p.order[j]] == count[p.order[i]] {
+					p.cell[p.order[j]] = i
+					j++
+				}
+				p.end[i] = j
+				if !queued[i] {
+					queued[i] = true
+					queue = append(queue, i)
+				}
+				i = j
+			}
+		}
+
+		for _, v := range touched {
+			count[v] = 0
+		}
+	}
+}
+
+// individualize returns a copy of p with v in a cell of its own, in front of
+// the rest of its cell, refined until it is equitable
+func (g *symGraph) individualize(p *symPartition, v int) *symPartition {
+	q := p.clone()
+	c := q.cell[v]
+	e := q.end[c]
+
+	i := slices.Index(q.order[c:e], v) + c
+	q.order[c], q.order[i] = q.order[i], q.order[c]
+	q.end[c] = c + 1
+	q.end[c+1] = e
+	for _, u := range q.order[c+1 : e] {
+		q.cell[u] = c + 1
+	}
+
+	g.refine(q, []int{c})
+	return q
+}
+
+// automorphisms returns generators of the group of automorphisms of g, as
+// permutations of the vertices, by the individualization and refinement of
+// McKay's nauty: the first path of the search tree individualizes the first
+// vertex of the first cell which isn't a single vertex, until each vertex
+// has a cell of its own. Working up the path, each other vertex w of the
+// cell individualized at a level is tried in its place, unless a generator
+// already found maps one to the other, and the first leaf below it which
+// matches the first leaf of the path gives a generator. complete is false if
+// the search reached symMaxNodes, and the generators may not generate the
+// whole group.
+func (g *symGraph) automorphisms() (generators [][]int, complete bool) {
+	n := len(g.color)
+
+	// The first path. A partition takes O(n) space, so only those at the
+	// levels which are multiples of stride are saved, doubling stride to
+	// keep about the square root of the depth of them. The others are
+	// recomputed from chosen, a segment of stride levels at a time, as the
+	// path is worked up.
+	var (
+		saved   []*symPartition // partition at each level which is a multiple of stride
+		stride  = 1             // levels between the saved partitions
+		shapes  []uint64        // shape of the partition at each level
+		targets []int           // target cell at each level
+		chosen  []int           // vertex individualized at each level
+		leaf    []int           // order of the partition at the last level
+	)
+	for p, level := g.partition(), 0; ; level++ {
+		if level%stride == 0 {
+			saved = append(saved, p)
+			if len(saved) > stride {
+				half := (len(saved) + 1) / 2
+				for k := range half {
+					saved[k] = saved[2*k]
+				}
+				clear(saved[half:])
+				saved = saved[:half]
+				stride *= 2
+			}
+		}
+		shapes = append(shapes, p.shape())
+		t := p.target()
+		targets = append(targets, t)
+		if t < 0 {
+			leaf = p.order
+			break
+		}
+		chosen = append(chosen, p.order[t])
+		p = g.individualize(p, p.order[t])
+	}
+
+	// partition returns the partition at a level of the first path, for
+	// levels in decreasing order
+	var segment []*symPartition // partitions at the levels from start
+	start := len(chosen) + 1
+	partition := func(level int) *symPartition {
+		if level < start {
+			start = level - level%stride
+			segment = append(segment[:0], saved[level/stride])
+			for l := start; l < level; l++ {
+				segment = append(segment, g.individualize(segment[l-start], chosen[l]))
+			}
+		}
+		return segment[level-start]
+	}
+
+	// The orbits of the generators found so far, as a forest
+	parent := make([]int, n)
+	for v := range parent {
+		parent[v] = v
+	}
+	find := func(v int) int {
+		for parent[v] != v {
+			parent[v] = parent[parent[v]]
+			v = parent[v]
+		}
+		return v
+	}
+
+	nodes := 0
+
+	// search returns an automorphism which maps the first leaf to a leaf
+	// below p, at the given level, if there is one
+	var search func(p *symPartition, level int) []int
+	search = func(p *symPartition, level int) []int {
+		nodes++
+		t := p.target()
+		if t != targets[level] || p.shape() != shapes[level] {
+			return nil
+		}
+		if t < 0 {
+			gamma := make([]int, n)
+			for i, u := range leaf {
+				gamma[u] = p.order[i]
+			}
+			if g.isAutomorphism(gamma) {
+				return gamma
+			}
+			return nil
+		}
+		for _, u := range slices.Clone(p.order[t:p.end[t]]) {
+			if nodes >= symMaxNodes {
+				return nil
+			}
+			if gamma := search(g.individualize(p, u), level+1); gamma != nil {
+				return gamma
+			}
+		}
+		return nil
+	}
+
+	for level := len(chosen) - 1; level >= 0 && nodes < symMaxNodes; level-- {
+		p, t, v := partition(level), targets[level], chosen[level]
+
+		var failed []int // vertices which no automorphism maps v to
+		for _, w := range p.order[t:p.end[t]] {
+			if find(w) == find(v) || slices.ContainsFunc(failed, func(u int) bool { return find(u) == find(w) }) {
+				continue
+			}
+			gamma := search(g.individualize(p, w), level+1)
+			if gamma == nil {
+				failed = append(failed, w)
+				continue
+			}
+			generators = append(generators, gamma)
+			for u, image := range gamma {
+				parent[find(u)] = find(image)
+			}
+		}
+	}
+
+	return generators, nodes < symMaxNodes
+}
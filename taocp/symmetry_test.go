@@ -0,0 +1,111 @@
+package taocp
+
+import (
+	"fmt"
+	"testing"
+)
+
+// symGroupOrder returns the order of the group generated by the
+// permutations, by generating all of its elements
+func symGroupOrder(n int, generators [][]int) int {
+	identity := make([]int, n)
+	for i := range identity {
+		identity[i] = i
+	}
+
+	seen := map[string]bool{fmt.Sprint(identity): true}
+	queue := [][]int{identity}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, gamma := range generators {
+			q := make([]int, n)
+			for i := range q {
+				q[i] = gamma[p[i]]
+			}
+			if key := fmt.Sprint(q); !seen[key] {
+				seen[key] = true
+				queue = append(queue, q)
+			}
+		}
+	}
+	return len(seen)
+}
+
+func TestSymGraphAutomorphisms(t *testing.T) {
+
+	// graph returns the graph with n vertices of color 0 and the given edges,
+	// and the colors given in colors
+	graph := func(n int, edges [][2]int, colors ...int) *symGraph {
+		color := make([]int, n)
+		copy(color, colors)
+		g := newSymGraph(color)
+		for _, e := range edges {
+			g.addEdge(e[0], e[1])
+		}
+		g.finish()
+		return g
+	}
+	cycle := func(n int) [][2]int {
+		var edges [][2]int
+		for i := 0; i < n; i++ {
+			edges = append(edges, [2]int{i, (i + 1) % n})
+		}
+		return edges
+	}
+	complete := func(n int) [][2]int {
+		var edges [][2]int
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				edges = append(edges, [2]int{i, j})
+			}
+		}
+		return edges
+	}
+	petersen := append(cycle(5), [2]int{0, 5}, [2]int{1, 6}, [2]int{2, 7}, [2]int{3, 8}, [2]int{4, 9},
+		[2]int{5, 7}, [2]int{7, 9}, [2]int{9, 6}, [2]int{6, 8}, [2]int{8, 5})
+	cube := [][2]int{{0, 1}, {1, 3}, {3, 2}, {2, 0}, {4, 5}, {5, 7}, {7, 6}, {6, 4},
+		{0, 4}, {1, 5}, {2, 6}, {3, 7}}
+
+	cases := []struct {
+		name  string    // name of the graph
+		g     *symGraph // graph
+		order int       // order of its automorphism group
+	}{
+		{"empty(3)", graph(3, nil), 6},
+		{"path(4)", graph(4, [][2]int{{0, 1}, {1, 2}, {2, 3}}), 2},
+		{"cycle(5)", graph(5, cycle(5)), 10},
+		{"cycle(8)", graph(8, cycle(8)), 16},
+		{"complete(5)", graph(5, complete(5)), 120},
+		{"petersen", graph(10, petersen), 120},
+		{"cube", graph(8, cube), 48},
+		{"colored cycle(6)", graph(6, cycle(6), 1), 2},
+		{"two triangles", graph(6, [][2]int{{0, 1}, {1, 2}, {2, 0}, {3, 4}, {4, 5}, {5, 3}}), 72},
+		{"asymmetric tree", graph(7, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 4}, {4, 5}, {2, 6}}), 1},
+		{"empty(7)", graph(7, nil), 5040},
+	}
+
+	for _, c := range cases {
+		generators, all := c.g.automorphisms()
+		for _, gamma := range generators {
+			if !c.g.isAutomorphism(gamma) {
+				t.Errorf("For %s, expected automorphisms; got %v", c.name, gamma)
+			}
+		}
+		if order := symGroupOrder(len(c.g.color), generators); order != c.order || !all {
+			t.Errorf("For %s, expected a complete group of order %d; got %d (complete=%t) from %v",
+				c.name, c.order, order, all, generators)
+		}
+	}
+
+	// Reaching the node limit leaves a partial set of generators
+	defer func(nodes int) { symMaxNodes = nodes }(symMaxNodes)
+	symMaxNodes = 2
+	generators, all := graph(7, nil).automorphisms()
+	if all {
+		t.Errorf("For empty(7) and %d nodes, expected an incomplete search", symMaxNodes)
+	}
+	if order := symGroupOrder(7, generators); order >= 5040 {
+		t.Errorf("For empty(7) and %d nodes, expected a subgroup; got order %d", symMaxNodes, order)
+	}
+}
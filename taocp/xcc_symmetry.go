@@ -0,0 +1,199 @@
+package taocp
+
+import (
+	"strings"
+)
+
+// xccSymGraph returns the incidence graph of an XCC problem, whose
+// automorphisms are its symmetries, and the vertex of each primary item.
+// Option k is vertex k. There is a vertex for each primary item, each
+// secondary item, and each color of a secondary item, joined to the item.
+// Each option is joined to its primary items, to the secondary items which
+// it has without a color, and to the colors of those it has with a color.
+func xccSymGraph(items []string, options [][]string, secondary []string) (*symGraph, []int) {
+
+	const (
+		colorOption = iota
+		colorPrimary
+		colorSecondary
+		colorColor
+	)
+
+	var color []int
+	vertex := make(map[string]int)
+	add := func(name string, c int) int {
+		color = append(color, c)
+		vertex[name] = len(color) - 1
+		return len(color) - 1
+	}
+
+	for range options {
+		color = append(color, colorOption)
+	}
+	primary := make([]int, len(items))
+	for i, item := range items {
+		primary[i] = add(item, colorPrimary)
+	}
+	for _, item := range secondary {
+		add(item, colorSecondary)
+	}
+
+	// The colors of the secondary items, and the edges of the options
+	type edge struct{ u, v int }
+	var edges []edge
+	for k, option := range options {
+		for _, item := range option {
+			if i := strings.Index(item, ":"); i > -1 {
+				if _, ok := vertex[item]; !ok {
+					edges = append(edges, edge{add(item, colorColor), vertex[item[:i]]})
+				}
+			}
+			edges = append(edges, edge{k, vertex[item]})
+		}
+	}
+
+	g := newSymGraph(color)
+	for _, e := range edges {
+		g.addEdge(e.u, e.v)
+	}
+	g.finish()
+
+	return g, primary
+}
+
+// XCCSymmetries returns generators of the group of symmetries of an XCC
+// problem: the permutations of the options which come from a permutation of
+// the primary items, the secondary items and the colors of each secondary
+// item, mapping the set of options to itself. They are found as the
+// automorphisms of the incidence graph of the options and items. Each
+// generator is a list of the image of each option 0..len(options)-1.
+// complete is false if the search for them was cut short, and they may not
+// generate the whole group.
+func XCCSymmetries(items []string, options [][]string, secondary []string) (generators [][]int, complete bool, err error) {
+
+	if err := xccValidate(items, options, secondary); err != nil {
+		return nil, false, err
+	}
+
+	g, _ := xccSymGraph(items, options, secondary)
+
+	automorphisms, complete := g.automorphisms()
+	for _, gamma := range automorphisms {
+		// Skip automorphisms which only permute the items
+		identity := true
+		for k := range options {
+			identity = identity && gamma[k] == k
+		}
+		if !identity {
+			generators = append(generators, gamma[:len(options)])
+		}
+	}
+
+	return generators, complete, nil
+}
+
+// XCCBreakSymmetry breaks the symmetries of an XCC problem by removing
+// options, generalizing the removal of the reversals of Langford pairs in
+// Exercise 7.2.2.1-15. It returns the numbers (0..len(options)-1) of the
+// options which remain, in order.
+//
+// A solution has exactly one option of each primary item p, and a symmetry
+// which maps p to itself maps the solution to one with the image of that
+// option. So every solution is symmetric to one whose option of p is the
+// first of its orbit under the generators of XCCSymmetries which map p to
+// itself, and the other options of p can be removed. This is done for the
+// item p which removes the most options, then repeated for the options
+// which remain, until no more are removed. The remaining problem has a
+// solution if and only if the original has, and its solutions are solutions
+// of the original; but some solutions are lost, so it isn't suitable for
+// finding or counting all of them. Counts, limits and estimates of the
+// search with the remaining options describe the reduced problem, not the
+// original.
+//
+// Symmetries could instead be broken by adding secondary items to the
+// options, whose colors allow only a lex-leader solution, as
+// SatBreakSymmetry does with clauses. But a lex-leader condition compares a
+// whole solution with its image, which colors can express only for special
+// generators, and then at the cost of new items and longer options.
+// Removing options needs no new items, only makes the search smaller, and
+// is sound for any generators by the argument above. complete is false if
+// the search for the symmetries was cut short, and some may remain.
+func XCCBreakSymmetry(items []string, options [][]string, secondary []string) (remain []int, complete bool, err error) {
+
+	if err := xccValidate(items, options, secondary); err != nil {
+		return nil, false, err
+	}
+
+	complete = true
+	remain = make([]int, len(options))
+	for k := range remain {
+		remain[k] = k
+	}
+
+	for {
+		current := make([][]string, len(remain))
+		for k, r := range remain {
+			current[k] = options[r]
+		}
+
+		g, primary := xccSymGraph(items, current, secondary)
+		generators, found := g.automorphisms()
+		complete = complete && found
+
+		// Find the primary item whose options have the most orbits to trim
+		var removed []int
+		for _, p := range primary {
+			of := g.adj[p] // options of p, in order
+
+			// The orbits of the options of p, as a forest whose roots are
+			// their first options
+			parent := make(map[int]int)
+			for _, k := range of {
+				parent[k] = k
+			}
+			find := func(k int) int {
+				for parent[k] != k {
+					k = parent[k]
+				}
+				return k
+			}
+			for _, gamma := range generators {
+				if gamma[p] != p {
+					continue
+				}
+				for _, k := range of {
+					a, b := find(k), find(gamma[k])
+					if a != b {
+						parent[max(a, b)] = min(a, b)
+					}
+				}
+			}
+
+			var trim []int
+			for _, k := range of {
+				if find(k) != k {
+					trim = append(trim, k)
+				}
+			}
+			if len(trim) > len(removed) {
+				removed = trim
+			}
+		}
+
+		if len(removed) == 0 {
+			return remain, complete, nil
+		}
+
+		remove := make(map[int]bool)
+		for _, k := range removed {
+			remove[k] = true
+		}
+		var next []int
+		for k, r := range remain {
+			if !remove[k] {
+				next = append(next, r)
+			}
+		}
+		remain = next
+	}
+}
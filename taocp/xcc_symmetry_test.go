@@ -0,0 +1,119 @@
+package taocp
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// xccLangford returns the items and options of Langford pairs for n, with
+// the reversals which Exercise 7.2.2.1-15 omits
+func xccLangford(n int) ([]string, [][]string) {
+	var items []string
+	for i := 1; i <= n; i++ {
+		items = append(items, strconv.Itoa(i))
+	}
+	for s := 1; s <= 2*n; s++ {
+		items = append(items, "s"+strconv.Itoa(s))
+	}
+
+	var options [][]string
+	for i := 1; i <= n; i++ {
+		for j := 1; j+i+1 <= 2*n; j++ {
+			options = append(options, []string{strconv.Itoa(i), "s" + strconv.Itoa(j), "s" + strconv.Itoa(j+i+1)})
+		}
+	}
+	return items, options
+}
+
+// xccSolutionSet returns the solutions as a sorted list of strings
+func xccSolutionSet(t *testing.T, items []string, options [][]string, secondary []string) []string {
+	var set []string
+	for solution, err := range XCC(items, options, secondary, nil, nil) {
+		if err != nil {
+			t.Fatalf("Expected no error; got %v", err)
+		}
+		var keys []string
+		for _, option := range solution {
+			keys = append(keys, fmt.Sprint(option))
+		}
+		slices.Sort(keys)
+		set = append(set, fmt.Sprint(keys))
+	}
+	slices.Sort(set)
+	return set
+}
+
+func TestXCCBreakSymmetry(t *testing.T) {
+
+	type problem struct {
+		name      string     // name of the problem
+		items     []string   // primary items
+		options   [][]string // options
+		secondary []string   // secondary items
+		count     int        // number of solutions
+		broken    int        // number of solutions after breaking symmetry
+	}
+
+	var cases []problem
+	for _, c := range []struct{ n, count, broken int }{{3, 2, 1}, {4, 2, 1}, {7, 52, 26}, {8, 300, 150}} {
+		items, options := xccLangford(c.n)
+		cases = append(cases, problem{fmt.Sprintf("langford(%d)", c.n), items, options, nil, c.count, c.broken})
+	}
+	for _, c := range []struct{ n, count, broken int }{{4, 2, 1}, {5, 10, 6}, {6, 4, 2}, {8, 92, 46}} {
+		items, options, secondary := nQueensXC(c.n)
+		cases = append(cases, problem{fmt.Sprintf("queens(%d)", c.n), items, options, secondary, c.count, c.broken})
+	}
+	cases = append(cases, problem{
+		"colors",
+		[]string{"a", "b"},
+		[][]string{{"a", "x:0"}, {"a", "x:1"}, {"b", "x:0"}, {"b", "x:1"}},
+		[]string{"x"},
+		2, 1,
+	})
+
+	for _, c := range cases {
+		generators, complete, err := XCCSymmetries(c.items, c.options, c.secondary)
+		if err != nil || len(generators) == 0 || !complete {
+			t.Errorf("For %s, expected all symmetries; got %v, %t, %v", c.name, generators, complete, err)
+		}
+
+		remain, complete, err := XCCBreakSymmetry(c.items, c.options, c.secondary)
+		if err != nil || !complete {
+			t.Errorf("For %s, expected no error and a complete search; got %t, %v", c.name, complete, err)
+			continue
+		}
+		var options [][]string
+		for _, k := range remain {
+			options = append(options, c.options[k])
+		}
+
+		all := xccSolutionSet(t, c.items, c.options, c.secondary)
+		broken := xccSolutionSet(t, c.items, options, c.secondary)
+		if len(all) != c.count || len(broken) != c.broken {
+			t.Errorf("For %s, expected %d and %d solutions; got %d and %d",
+				c.name, c.count, c.broken, len(all), len(broken))
+		}
+		for _, solution := range broken {
+			if _, found := slices.BinarySearch(all, solution); !found {
+				t.Errorf("For %s, expected a solution of the original problem; got %s", c.name, solution)
+			}
+		}
+	}
+
+	// Without symmetries, every option remains
+	items, options := []string{"a", "b", "c"}, [][]string{{"a"}, {"a", "b"}, {"b", "c"}, {"a", "b", "c"}}
+	generators, _, err := XCCSymmetries(items, options, nil)
+	if err != nil || len(generators) != 0 {
+		t.Errorf("Expected no symmetries; got %v, %v", generators, err)
+	}
+	remain, _, err := XCCBreakSymmetry(items, options, nil)
+	if err != nil || !slices.Equal(remain, []int{0, 1, 2, 3}) {
+		t.Errorf("Expected every option to remain; got %v, %v", remain, err)
+	}
+
+	if _, _, err := XCCBreakSymmetry(nil, options, nil); err == nil {
+		t.Errorf("Expected an error for no items")
+	}
+}